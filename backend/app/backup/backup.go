@@ -0,0 +1,206 @@
+// Package backup implementa o envelope das cópias de segurança do cofre: o
+// conteúdo é compactado, cifrado com AES-256-CTR sob uma chave derivada da
+// senha por Argon2id e autenticado por um HMAC-SHA256 que cobre também os
+// parâmetros de derivação.
+package backup
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"io"
+
+	"golang.org/x/crypto/argon2"
+)
+
+const (
+	Format  = "password-mobile-app/backup"
+	Version = 1
+
+	saltSize = 16
+	keySize  = 32
+)
+
+// Limites para arquivos recebidos, evitando derivações ou descompactações
+// sem controle.
+var (
+	MaxMemory      uint32 = 256 * 1024
+	MaxIterations  uint32 = 20
+	MaxParallelism uint8  = 16
+	MaxContentSize int64  = 64 << 20
+)
+
+var errInvalid = errors.New("backup: senha incorreta ou arquivo corrompido")
+
+type KDFParams struct {
+	Algoritmo   string `json:"algoritmo"`
+	Salt        []byte `json:"salt"`
+	Memoria     uint32 `json:"memoria"`
+	Iteracoes   uint32 `json:"iteracoes"`
+	Paralelismo uint8  `json:"paralelismo"`
+}
+
+type Envelope struct {
+	Formato  string    `json:"formato"`
+	Versao   int       `json:"versao"`
+	KDF      KDFParams `json:"kdf"`
+	IV       []byte    `json:"iv"`
+	Conteudo []byte    `json:"conteudo"`
+	MAC      []byte    `json:"mac,omitempty"`
+}
+
+func DefaultKDFParams() (KDFParams, error) {
+	salt := make([]byte, saltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return KDFParams{}, err
+	}
+
+	return KDFParams{
+		Algoritmo:   "argon2id",
+		Salt:        salt,
+		Memoria:     64 * 1024,
+		Iteracoes:   3,
+		Paralelismo: 4,
+	}, nil
+}
+
+// Seal compacta, cifra e autentica o conteúdo, devolvendo o envelope em JSON.
+func Seal(content []byte, password string) ([]byte, error) {
+	if password == "" {
+		return nil, errors.New("backup: senha é obrigatória")
+	}
+
+	params, err := DefaultKDFParams()
+	if err != nil {
+		return nil, err
+	}
+
+	iv := make([]byte, aes.BlockSize)
+	if _, err := rand.Read(iv); err != nil {
+		return nil, err
+	}
+
+	var compressed bytes.Buffer
+	gz := gzip.NewWriter(&compressed)
+	gz.Write(content)
+	if err := gz.Close(); err != nil {
+		return nil, err
+	}
+
+	encKey, macKey := deriveKeys(password, params)
+	ciphertext, err := xorCTR(encKey, iv, compressed.Bytes())
+	if err != nil {
+		return nil, err
+	}
+
+	env := &Envelope{
+		Formato:  Format,
+		Versao:   Version,
+		KDF:      params,
+		IV:       iv,
+		Conteudo: ciphertext,
+	}
+	if env.MAC, err = env.mac(macKey); err != nil {
+		return nil, err
+	}
+
+	return json.MarshalIndent(env, "", "  ")
+}
+
+// Open confere o MAC antes de decifrar e devolve o conteúdo original.
+func Open(data []byte, password string) ([]byte, error) {
+	var env Envelope
+	if err := json.Unmarshal(data, &env); err != nil || env.Formato != Format {
+		return nil, errors.New("backup: o arquivo não é uma cópia de segurança do cofre")
+	}
+	if env.Versao != Version {
+		return nil, errors.New("backup: versão do arquivo não suportada")
+	}
+	if err := validateParams(env.KDF); err != nil {
+		return nil, err
+	}
+	if len(env.IV) != aes.BlockSize {
+		return nil, errInvalid
+	}
+
+	encKey, macKey := deriveKeys(password, env.KDF)
+	expected, err := env.mac(macKey)
+	if err != nil {
+		return nil, err
+	}
+	if !hmac.Equal(expected, env.MAC) {
+		return nil, errInvalid
+	}
+
+	compressed, err := xorCTR(encKey, env.IV, env.Conteudo)
+	if err != nil {
+		return nil, err
+	}
+
+	reader, err := gzip.NewReader(bytes.NewReader(compressed))
+	if err != nil {
+		return nil, errInvalid
+	}
+	defer reader.Close()
+
+	content, err := io.ReadAll(io.LimitReader(reader, MaxContentSize+1))
+	if err != nil {
+		return nil, errInvalid
+	}
+	if int64(len(content)) > MaxContentSize {
+		return nil, errors.New("backup: conteúdo muito grande")
+	}
+	return content, nil
+}
+
+func validateParams(params KDFParams) error {
+	if params.Algoritmo != "argon2id" {
+		return errors.New("backup: algoritmo de derivação não suportado")
+	}
+	if len(params.Salt) < saltSize || params.Memoria < 8*1024 || params.Iteracoes == 0 || params.Paralelismo == 0 {
+		return errors.New("backup: parâmetros de derivação inválidos")
+	}
+	if params.Memoria > MaxMemory || params.Iteracoes > MaxIterations || params.Paralelismo > MaxParallelism {
+		return errors.New("backup: parâmetros de derivação acima do permitido")
+	}
+	return nil
+}
+
+func deriveKeys(password string, params KDFParams) ([]byte, []byte) {
+	key := argon2.IDKey([]byte(password), params.Salt, params.Iteracoes, params.Memoria, params.Paralelismo, 2*keySize)
+	return key[:keySize], key[keySize:]
+}
+
+// mac cobre o envelope inteiro serializado sem o próprio MAC; como a
+// serialização de uma struct é determinística, o resultado não depende da
+// formatação do arquivo recebido.
+func (e *Envelope) mac(key []byte) ([]byte, error) {
+	unsigned := *e
+	unsigned.MAC = nil
+
+	data, err := json.Marshal(unsigned)
+	if err != nil {
+		return nil, err
+	}
+
+	h := hmac.New(sha256.New, key)
+	h.Write(data)
+	return h.Sum(nil), nil
+}
+
+func xorCTR(key, iv, data []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]byte, len(data))
+	cipher.NewCTR(block, iv).XORKeyStream(out, data)
+	return out, nil
+}
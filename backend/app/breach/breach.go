@@ -0,0 +1,97 @@
+// Package breach verifica se uma senha aparece no Pwned Passwords (HIBP) sem
+// consultar a internet: a busca é feita num conjunto baixado localmente, seja
+// no formato de intervalos por prefixo da API, seja num filtro de Bloom
+// compacto gerado a partir dele pelo comando breach-build.
+package breach
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"unicode/utf16"
+
+	"golang.org/x/crypto/md4"
+)
+
+type HashKind string
+
+const (
+	SHA1 HashKind = "sha1"
+	NTLM HashKind = "ntlm"
+)
+
+// Checker responde se uma senha está entre as senhas vazadas conhecidas.
+type Checker interface {
+	Contains(password string) (bool, error)
+}
+
+func ParseHashKind(value string) (HashKind, error) {
+	switch kind := HashKind(strings.ToLower(strings.TrimSpace(value))); kind {
+	case "", SHA1:
+		return SHA1, nil
+	case NTLM:
+		return NTLM, nil
+	default:
+		return "", fmt.Errorf("breach: hash desconhecido: %s", value)
+	}
+}
+
+// Hash calcula o hash da senha no formato usado pelo conjunto: SHA-1 do texto
+// em UTF-8 ou NTLM (MD4 do texto em UTF-16LE).
+func Hash(kind HashKind, password string) []byte {
+	if kind == NTLM {
+		units := utf16.Encode([]rune(password))
+		encoded := make([]byte, 0, len(units)*2)
+		for _, unit := range units {
+			encoded = append(encoded, byte(unit), byte(unit>>8))
+		}
+		h := md4.New()
+		h.Write(encoded)
+		return h.Sum(nil)
+	}
+
+	sum := sha1.Sum([]byte(password))
+	return sum[:]
+}
+
+func hashSize(kind HashKind) int {
+	if kind == NTLM {
+		return md4.Size
+	}
+	return sha1.Size
+}
+
+func decodeHash(kind HashKind, value string) ([]byte, error) {
+	hash, err := hex.DecodeString(value)
+	if err != nil || len(hash) != hashSize(kind) {
+		return nil, fmt.Errorf("breach: hash inválido: %q", value)
+	}
+	return hash, nil
+}
+
+// Open carrega o conjunto em path: um diretório com os arquivos de intervalo
+// ou um arquivo de filtro gerado por BuildFilter. Para filtros o tipo de hash
+// vem do próprio arquivo.
+func Open(path string, kind HashKind) (Checker, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if info.IsDir() {
+		return &RangeDir{Dir: path, Kind: kind}, nil
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return ReadFilter(file)
+}
+
+var errInvalidFilter = errors.New("breach: arquivo de filtro inválido")
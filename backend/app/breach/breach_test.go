@@ -0,0 +1,202 @@
+package breach
+
+import (
+	"bytes"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Os diretórios de testdata seguem o formato do PwnedPasswordsDownloader:
+// um arquivo por prefixo, linhas SUFIXO:OCORRÊNCIAS terminadas em CRLF e
+// sufixos de preenchimento com zero ocorrências. O prefixo 3DECD não tem
+// extensão e seus sufixos estão em minúsculas; o 44E86 é o prefixo de
+// "nao-vazada", mas só tem um sufixo que difere do dela no último dígito.
+var (
+	breached = []string{"password", "123456", "senha123", "sênha"}
+	clean    = []string{"nao-vazada", "correct horse battery staple", ""}
+
+	// O filtro usa só os primeiros 16 bytes do hash e não distingue
+	// "nao-vazada" do sufixo vizinho.
+	filterClean = clean[1:]
+)
+
+func TestHash(t *testing.T) {
+	tests := []struct {
+		kind     HashKind
+		password string
+		hash     string
+	}{
+		{SHA1, "password", "5baa61e4c9b93f3f0682250b6cf8331b7ee68fd8"},
+		{SHA1, "", "da39a3ee5e6b4b0d3255bfef95601890afd80709"},
+		{NTLM, "password", "8846f7eaee8fb117ad06bdd830b7586c"},
+		{NTLM, "123456", "32ed87bdb5fdc5e9cba88547376818d4"},
+		{NTLM, "", "31d6cfe0d16ae931b73c59d7e0c089c0"},
+	}
+
+	for _, tt := range tests {
+		if got := hex.EncodeToString(Hash(tt.kind, tt.password)); got != tt.hash {
+			t.Errorf("%s(%q) = %s, esperado %s", tt.kind, tt.password, got, tt.hash)
+		}
+	}
+}
+
+func TestParseHashKind(t *testing.T) {
+	for value, want := range map[string]HashKind{"": SHA1, "sha1": SHA1, " SHA1 ": SHA1, "NTLM": NTLM} {
+		kind, err := ParseHashKind(value)
+		if err != nil || kind != want {
+			t.Errorf("ParseHashKind(%q) = %s, %v", value, kind, err)
+		}
+	}
+	if _, err := ParseHashKind("md5"); err == nil {
+		t.Error("hash desconhecido aceito")
+	}
+}
+
+func checkContains(t *testing.T, name string, checker Checker, breached, clean []string) {
+	t.Helper()
+
+	for _, password := range breached {
+		found, err := checker.Contains(password)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if !found {
+			t.Errorf("%s: %q não encontrada", name, password)
+		}
+	}
+	for _, password := range clean {
+		found, err := checker.Contains(password)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if found {
+			t.Errorf("%s: %q apontada como vazada", name, password)
+		}
+	}
+}
+
+func TestRangeDir(t *testing.T) {
+	checkContains(t, "sha1", &RangeDir{Dir: filepath.Join("testdata", "sha1"), Kind: SHA1}, breached, clean)
+	checkContains(t, "ntlm", &RangeDir{Dir: filepath.Join("testdata", "ntlm"), Kind: NTLM}, []string{"password", "123456"}, []string{"senha123", ""})
+
+	// O mesmo conjunto lido com o hash errado não encontra nada.
+	checkContains(t, "sha1 como ntlm", &RangeDir{Dir: filepath.Join("testdata", "sha1"), Kind: NTLM}, nil, breached)
+}
+
+func TestBuildFilter(t *testing.T) {
+	for _, path := range []string{filepath.Join("testdata", "sha1"), filepath.Join("testdata", "sha1.txt")} {
+		filter, err := BuildFilter(path, SHA1, 0.001)
+		if err != nil {
+			t.Fatalf("%s: %v", path, err)
+		}
+		checkContains(t, path, filter, breached, filterClean)
+
+		var buf bytes.Buffer
+		if _, err := filter.WriteTo(&buf); err != nil {
+			t.Fatal(err)
+		}
+		read, err := ReadFilter(bytes.NewReader(buf.Bytes()))
+		if err != nil {
+			t.Fatalf("%s: ler filtro: %v", path, err)
+		}
+		if read.Kind != SHA1 || read.hashes != filter.hashes || read.size != filter.size {
+			t.Errorf("%s: cabeçalho lido difere do gravado", path)
+		}
+		checkContains(t, path+" relido", read, breached, filterClean)
+	}
+
+	filter, err := BuildFilter(filepath.Join("testdata", "ntlm"), NTLM, 0.001)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkContains(t, "ntlm", filter, []string{"password", "123456"}, []string{"senha123"})
+}
+
+func TestBuildFilterRejectsBadHash(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "hashes.txt")
+	if err := os.WriteFile(path, []byte("5BAA61E4C9B93F3F0682250B6CF8331B7EE68FD8:1\nXYZ:2\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := BuildFilter(path, SHA1, 0.001); err == nil {
+		t.Error("hash inválido aceito")
+	}
+
+	// Hashes SHA-1 não servem para um conjunto NTLM.
+	if _, err := BuildFilter(filepath.Join("testdata", "sha1.txt"), NTLM, 0.001); err == nil {
+		t.Error("hash do tamanho errado aceito")
+	}
+}
+
+func TestOpen(t *testing.T) {
+	checker, err := Open(filepath.Join("testdata", "sha1"), SHA1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkContains(t, "diretório", checker, breached, clean)
+
+	filter, err := BuildFilter(filepath.Join("testdata", "ntlm"), NTLM, 0.001)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "vazadas.bloom")
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := filter.WriteTo(file); err != nil {
+		t.Fatal(err)
+	}
+	file.Close()
+
+	// O tipo de hash vem do arquivo, não do argumento.
+	checker, err = Open(path, SHA1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkContains(t, "filtro", checker, []string{"password", "123456"}, []string{"senha123"})
+
+	if _, err := Open(filepath.Join("testdata", "inexistente"), SHA1); err == nil {
+		t.Error("conjunto inexistente aceito")
+	}
+}
+
+func TestReadFilterRejectsCorrupted(t *testing.T) {
+	filter, err := BuildFilter(filepath.Join("testdata", "sha1.txt"), SHA1, 0.01)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if _, err := filter.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	valid := buf.Bytes()
+
+	corrupt := func(offset int, value byte) []byte {
+		data := append([]byte(nil), valid...)
+		data[offset] = value
+		return data
+	}
+
+	tests := map[string][]byte{
+		"vazio":             nil,
+		"assinatura":        corrupt(0, 'X'),
+		"versão":            corrupt(8, filterVersion+1),
+		"tipo de hash":      corrupt(9, 3),
+		"sem funções":       corrupt(10, 0),
+		"funções demais":    corrupt(10, maxHashes+1),
+		"tamanho não par":   corrupt(11, valid[11]+1),
+		"bits truncados":    valid[:len(valid)-1],
+		"só o cabeçalho":    valid[:len(filterMagic)+11],
+		"texto de hashes":   []byte(strings.Repeat("5BAA61E4C9B93F3F0682250B6CF8331B7EE68FD8:1\n", 4)),
+		"tamanho exagerado": append(append([]byte(nil), valid[:11]...), 0, 0, 0, 0, 0, 0, 0, 0x10),
+	}
+
+	for name, data := range tests {
+		if _, err := ReadFilter(bytes.NewReader(data)); err != errInvalidFilter {
+			t.Errorf("%s: %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,177 @@
+package breach
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+)
+
+const (
+	filterMagic   = "PMABLOOM"
+	filterVersion = 1
+	maxHashes     = 64
+	// maxFilterSize (16 GiB) protege a leitura de um cabeçalho corrompido.
+	maxFilterSize = 1 << 37
+)
+
+// Filter é um filtro de Bloom sobre os hashes do conjunto. Como os hashes já
+// são uniformes, as posições saem direto dos seus bytes (hash duplo), sem
+// calcular nada além do hash da senha. Falsos positivos ocorrem na taxa
+// escolhida em NewFilter; falsos negativos nunca.
+type Filter struct {
+	Kind   HashKind
+	hashes uint64
+	size   uint64
+	bits   []uint64
+}
+
+// NewFilter dimensiona o filtro para count hashes com a taxa de falsos
+// positivos indicada.
+func NewFilter(kind HashKind, count uint64, falsePositiveRate float64) (*Filter, error) {
+	if falsePositiveRate <= 0 || falsePositiveRate >= 1 {
+		return nil, errors.New("breach: a taxa de falsos positivos deve estar entre 0 e 1")
+	}
+	if count == 0 {
+		count = 1
+	}
+
+	size := uint64(math.Ceil(-float64(count) * math.Log(falsePositiveRate) / (math.Ln2 * math.Ln2)))
+	size = (size + 63) / 64 * 64
+	hashes := uint64(math.Round(float64(size) / float64(count) * math.Ln2))
+	hashes = max(1, min(hashes, maxHashes))
+
+	return &Filter{
+		Kind:   kind,
+		hashes: hashes,
+		size:   size,
+		bits:   make([]uint64, size/64),
+	}, nil
+}
+
+func (f *Filter) positions(hash []byte, fn func(bit uint64) bool) bool {
+	h1 := binary.LittleEndian.Uint64(hash[0:8])
+	h2 := binary.LittleEndian.Uint64(hash[8:16]) | 1
+	for i := uint64(0); i < f.hashes; i++ {
+		if !fn((h1 + i*h2) % f.size) {
+			return false
+		}
+	}
+	return true
+}
+
+func (f *Filter) Add(hash []byte) {
+	f.positions(hash, func(bit uint64) bool {
+		f.bits[bit/64] |= 1 << (bit % 64)
+		return true
+	})
+}
+
+func (f *Filter) ContainsHash(hash []byte) bool {
+	return f.positions(hash, func(bit uint64) bool {
+		return f.bits[bit/64]&(1<<(bit%64)) != 0
+	})
+}
+
+func (f *Filter) Contains(password string) (bool, error) {
+	return f.ContainsHash(Hash(f.Kind, password)), nil
+}
+
+// BuildFilter gera um filtro a partir de um conjunto baixado (diretório de
+// intervalos ou arquivo HASH:OCORRÊNCIAS), lendo-o duas vezes: uma para
+// contar os hashes e outra para preenchê-lo.
+func BuildFilter(path string, kind HashKind, falsePositiveRate float64) (*Filter, error) {
+	var count uint64
+	if err := scanHashes(path, kind, func([]byte) error {
+		count++
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	filter, err := NewFilter(kind, count, falsePositiveRate)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := scanHashes(path, kind, func(hash []byte) error {
+		filter.Add(hash)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return filter, nil
+}
+
+// Formato do arquivo: "PMABLOOM", versão, tipo de hash (1 SHA-1, 2 NTLM),
+// número de funções de hash, tamanho em bits (uint64) e os bits, tudo em
+// little-endian.
+func (f *Filter) WriteTo(w io.Writer) (int64, error) {
+	buffered := bufio.NewWriter(w)
+
+	kind := byte(1)
+	if f.Kind == NTLM {
+		kind = 2
+	}
+
+	header := make([]byte, 0, len(filterMagic)+11)
+	header = append(header, filterMagic...)
+	header = append(header, filterVersion, kind, byte(f.hashes))
+	header = binary.LittleEndian.AppendUint64(header, f.size)
+
+	written, err := buffered.Write(header)
+	total := int64(written)
+	if err != nil {
+		return total, err
+	}
+
+	word := make([]byte, 8)
+	for _, bits := range f.bits {
+		binary.LittleEndian.PutUint64(word, bits)
+		written, err := buffered.Write(word)
+		total += int64(written)
+		if err != nil {
+			return total, err
+		}
+	}
+	return total, buffered.Flush()
+}
+
+func ReadFilter(r io.Reader) (*Filter, error) {
+	buffered := bufio.NewReader(r)
+
+	header := make([]byte, len(filterMagic)+11)
+	if _, err := io.ReadFull(buffered, header); err != nil {
+		return nil, errInvalidFilter
+	}
+	if string(header[:len(filterMagic)]) != filterMagic || header[8] != filterVersion {
+		return nil, errInvalidFilter
+	}
+
+	filter := &Filter{
+		hashes: uint64(header[10]),
+		size:   binary.LittleEndian.Uint64(header[11:]),
+	}
+	switch header[9] {
+	case 1:
+		filter.Kind = SHA1
+	case 2:
+		filter.Kind = NTLM
+	default:
+		return nil, errInvalidFilter
+	}
+	if filter.hashes == 0 || filter.hashes > maxHashes || filter.size == 0 || filter.size%64 != 0 || filter.size > maxFilterSize {
+		return nil, errInvalidFilter
+	}
+
+	filter.bits = make([]uint64, filter.size/64)
+	word := make([]byte, 8)
+	for i := range filter.bits {
+		if _, err := io.ReadFull(buffered, word); err != nil {
+			return nil, errInvalidFilter
+		}
+		filter.bits[i] = binary.LittleEndian.Uint64(word)
+	}
+	return filter, nil
+}
@@ -0,0 +1,108 @@
+package breach
+
+import (
+	"bufio"
+	"encoding/hex"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const prefixLength = 5
+
+// RangeDir lê um diretório no formato da API de intervalos do HIBP, como o
+// gerado pelo PwnedPasswordsDownloader: um arquivo por prefixo de 5 dígitos
+// hexadecimais (ABCDE.txt) com linhas SUFIXO:OCORRÊNCIAS.
+type RangeDir struct {
+	Dir  string
+	Kind HashKind
+}
+
+func (d *RangeDir) Contains(password string) (bool, error) {
+	hash := strings.ToUpper(hex.EncodeToString(Hash(d.Kind, password)))
+	prefix, suffix := hash[:prefixLength], hash[prefixLength:]
+
+	file, err := d.openRange(prefix)
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(strings.TrimSpace(scanner.Text()), ":")
+		if strings.EqualFold(line, suffix) {
+			return true, nil
+		}
+	}
+	return false, scanner.Err()
+}
+
+func (d *RangeDir) openRange(prefix string) (*os.File, error) {
+	file, err := os.Open(filepath.Join(d.Dir, prefix+".txt"))
+	if errors.Is(err, fs.ErrNotExist) {
+		return os.Open(filepath.Join(d.Dir, prefix))
+	}
+	return file, err
+}
+
+// scanHashes percorre todos os hashes de um conjunto: um diretório de
+// intervalos ou um arquivo único com linhas HASH:OCORRÊNCIAS.
+func scanHashes(path string, kind HashKind, fn func(hash []byte) error) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+
+	if !info.IsDir() {
+		return scanFile(path, kind, "", fn)
+	}
+
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		prefix := strings.TrimSuffix(entry.Name(), ".txt")
+		if entry.IsDir() || len(prefix) != prefixLength {
+			continue
+		}
+		if _, err := hex.DecodeString("0" + prefix); err != nil {
+			continue
+		}
+		if err := scanFile(filepath.Join(path, entry.Name()), kind, prefix, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func scanFile(path string, kind HashKind, prefix string, fn func(hash []byte) error) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		value, _, _ := strings.Cut(strings.TrimSpace(scanner.Text()), ":")
+		if value == "" {
+			continue
+		}
+
+		hash, err := decodeHash(kind, prefix+value)
+		if err != nil {
+			return err
+		}
+		if err := fn(hash); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
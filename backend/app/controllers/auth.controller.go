@@ -1,22 +1,27 @@
 package controllers
 
 import (
+	"errors"
 	"net/mail"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/Vicente/Password-Mobile-App/backend/app/ratelimit"
 	"github.com/Vicente/Password-Mobile-App/backend/app/services"
 	"github.com/Vicente/Password-Mobile-App/backend/app/types"
 	"github.com/gofiber/fiber/v2"
 )
 
 type AuthController struct {
-	AuthService *services.AuthService
+	AuthService    *services.AuthService
+	SessionService *services.SessionService
 }
 
-func NewAuthController(authService *services.AuthService) *AuthController {
+func NewAuthController(authService *services.AuthService, sessionService *services.SessionService) *AuthController {
 	return &AuthController{
-		AuthService: authService,
+		AuthService:    authService,
+		SessionService: sessionService,
 	}
 }
 
@@ -98,7 +103,54 @@ func (c *AuthController) Login(ctx *fiber.Ctx) error {
 		})
 	}
 
+	req.Sessao = types.SessionInfo{
+		Dispositivo: req.Dispositivo,
+		UserAgent:   ctx.Get(fiber.HeaderUserAgent),
+		IP:          ctx.IP(),
+	}
+
 	response, err := c.AuthService.Login(&req)
+	if err != nil {
+		var limitErr *ratelimit.Error
+		if errors.As(err, &limitErr) {
+			return tooManyRequests(ctx, limitErr)
+		}
+		return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	if response.MFAObrigatorio {
+		return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+			"mfaObrigatorio": true,
+			"mfaToken":       response.MFAToken,
+		})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"token":        response.Token,
+		"refreshToken": response.RefreshToken,
+		"user":         response.User.Profile(),
+	})
+}
+
+func tooManyRequests(ctx *fiber.Ctx, err *ratelimit.Error) error {
+	ctx.Set(fiber.HeaderRetryAfter, strconv.Itoa(err.RetryAfterSeconds()))
+	return ctx.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{
+		"error": err.Error(),
+	})
+}
+
+func (c *AuthController) Refresh(ctx *fiber.Ctx) error {
+	var req types.RefreshRequest
+
+	if err := ctx.BodyParser(&req); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Dados inválidos: " + err.Error(),
+		})
+	}
+
+	response, err := c.SessionService.Refresh(req.RefreshToken)
 	if err != nil {
 		return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
 			"error": err.Error(),
@@ -106,6 +158,101 @@ func (c *AuthController) Login(ctx *fiber.Ctx) error {
 	}
 
 	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
-		"token": response.Token,
+		"token":        response.Token,
+		"refreshToken": response.RefreshToken,
+	})
+}
+
+func (c *AuthController) Logout(ctx *fiber.Ctx) error {
+	var req types.RefreshRequest
+
+	if err := ctx.BodyParser(&req); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Dados inválidos: " + err.Error(),
+		})
+	}
+
+	if err := c.SessionService.Logout(req.RefreshToken); err != nil {
+		if err.Error() == "refresh token inválido" {
+			return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return ctx.SendStatus(fiber.StatusNoContent)
+}
+
+func (c *AuthController) ListSessions(ctx *fiber.Ctx) error {
+	userID, ok := ctx.Locals("userID").(uint)
+	if !ok {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Usuário não autenticado",
+		})
+	}
+
+	sessionID, _ := ctx.Locals("sessionID").(uint)
+
+	sessions, err := c.SessionService.ListSessions(userID, sessionID)
+	if err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(sessions)
+}
+
+func (c *AuthController) RevokeSession(ctx *fiber.Ctx) error {
+	sessionID, err := strconv.ParseUint(ctx.Params("id"), 10, 32)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "ID da sessão inválido",
+		})
+	}
+
+	userID, ok := ctx.Locals("userID").(uint)
+	if !ok {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Usuário não autenticado",
+		})
+	}
+
+	if err := c.SessionService.RevokeSession(userID, uint(sessionID)); err != nil {
+		if err.Error() == "sessão não encontrada" {
+			return ctx.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return ctx.SendStatus(fiber.StatusNoContent)
+}
+
+func (c *AuthController) RevokeOtherSessions(ctx *fiber.Ctx) error {
+	userID, ok := ctx.Locals("userID").(uint)
+	if !ok {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Usuário não autenticado",
+		})
+	}
+
+	sessionID, _ := ctx.Locals("sessionID").(uint)
+
+	revoked, err := c.SessionService.RevokeOtherSessions(userID, sessionID)
+	if err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"sessoesEncerradas": revoked,
 	})
 }
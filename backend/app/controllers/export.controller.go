@@ -0,0 +1,142 @@
+package controllers
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/Vicente/Password-Mobile-App/backend/app/ratelimit"
+	"github.com/Vicente/Password-Mobile-App/backend/app/services"
+	"github.com/Vicente/Password-Mobile-App/backend/app/types"
+	"github.com/gofiber/fiber/v2"
+)
+
+type ExportController struct {
+	ExportService *services.ExportService
+}
+
+func NewExportController(exportService *services.ExportService) *ExportController {
+	return &ExportController{
+		ExportService: exportService,
+	}
+}
+
+func (c *ExportController) RequestExport(ctx *fiber.Ctx) error {
+	var req types.ExportRequest
+
+	if len(ctx.Body()) > 0 {
+		if err := ctx.BodyParser(&req); err != nil {
+			return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Dados inválidos: " + err.Error(),
+			})
+		}
+	}
+
+	userID, ok := ctx.Locals("userID").(uint)
+	if !ok {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Usuário não autenticado",
+		})
+	}
+
+	req.UserID = userID
+	req.Sessao = types.SessionInfo{
+		UserAgent: ctx.Get(fiber.HeaderUserAgent),
+		IP:        ctx.IP(),
+	}
+
+	job, err := c.ExportService.RequestExport(&req)
+	if err != nil {
+		var limitErr *ratelimit.Error
+		if errors.As(err, &limitErr) {
+			return tooManyRequests(ctx, limitErr)
+		}
+
+		switch err.Error() {
+		case "senha incorreta":
+			return ctx.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		case "já existe uma exportação em andamento":
+			return ctx.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return ctx.Status(fiber.StatusAccepted).JSON(job)
+}
+
+func (c *ExportController) GetJobs(ctx *fiber.Ctx) error {
+	userID, ok := ctx.Locals("userID").(uint)
+	if !ok {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Usuário não autenticado",
+		})
+	}
+
+	jobs, err := c.ExportService.GetJobs(userID)
+	if err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(jobs)
+}
+
+func (c *ExportController) GetJob(ctx *fiber.Ctx) error {
+	jobID, err := strconv.ParseUint(ctx.Params("id"), 10, 32)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "ID da exportação inválido",
+		})
+	}
+
+	userID, ok := ctx.Locals("userID").(uint)
+	if !ok {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Usuário não autenticado",
+		})
+	}
+
+	job, err := c.ExportService.GetJob(uint(jobID), userID)
+	if err != nil {
+		if err.Error() == "exportação não encontrada" {
+			return ctx.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(job)
+}
+
+func (c *ExportController) Download(ctx *fiber.Ctx) error {
+	job, content, err := c.ExportService.Download(ctx.Query("token"))
+	if err != nil {
+		if err.Error() == "link de download inválido ou expirado" {
+			return ctx.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	contentType := fiber.MIMEApplicationJSONCharsetUTF8
+	if job.Formato == types.ExportFormatZIP {
+		contentType = "application/zip"
+	}
+
+	ctx.Set(fiber.HeaderContentType, contentType)
+	ctx.Set(fiber.HeaderCacheControl, "no-store")
+	ctx.Attachment(services.ExportFilename(job))
+	return ctx.Status(fiber.StatusOK).Send(content)
+}
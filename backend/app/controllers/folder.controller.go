@@ -0,0 +1,144 @@
+package controllers
+
+import (
+	"strconv"
+
+	"github.com/Vicente/Password-Mobile-App/backend/app/services"
+	"github.com/Vicente/Password-Mobile-App/backend/app/types"
+	"github.com/gofiber/fiber/v2"
+)
+
+type FolderController struct {
+	FolderService *services.FolderService
+}
+
+func NewFolderController(folderService *services.FolderService) *FolderController {
+	return &FolderController{
+		FolderService: folderService,
+	}
+}
+
+func (c *FolderController) ListFolders(ctx *fiber.Ctx) error {
+	userID, ok := ctx.Locals("userID").(uint)
+	if !ok {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Usuário não autenticado",
+		})
+	}
+
+	folders, err := c.FolderService.ListFolders(userID)
+	if err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(folders)
+}
+
+func (c *FolderController) CreateFolder(ctx *fiber.Ctx) error {
+	var req types.FolderRequest
+
+	if err := ctx.BodyParser(&req); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Dados inválidos: " + err.Error(),
+		})
+	}
+
+	userID, ok := ctx.Locals("userID").(uint)
+	if !ok {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Usuário não autenticado",
+		})
+	}
+
+	req.UserID = userID
+
+	folder, err := c.FolderService.CreateFolder(&req)
+	if err != nil {
+		return ctx.Status(folderErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(folder)
+}
+
+func (c *FolderController) UpdateFolder(ctx *fiber.Ctx) error {
+	folderID, err := strconv.ParseUint(ctx.Params("id"), 10, 32)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "ID da pasta inválido",
+		})
+	}
+
+	var req types.FolderRequest
+
+	if err := ctx.BodyParser(&req); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Dados inválidos: " + err.Error(),
+		})
+	}
+
+	userID, ok := ctx.Locals("userID").(uint)
+	if !ok {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Usuário não autenticado",
+		})
+	}
+
+	req.ID = uint(folderID)
+	req.UserID = userID
+
+	folder, err := c.FolderService.UpdateFolder(&req)
+	if err != nil {
+		return ctx.Status(folderErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(folder)
+}
+
+func (c *FolderController) DeleteFolder(ctx *fiber.Ctx) error {
+	folderID, err := strconv.ParseUint(ctx.Params("id"), 10, 32)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "ID da pasta inválido",
+		})
+	}
+
+	userID, ok := ctx.Locals("userID").(uint)
+	if !ok {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Usuário não autenticado",
+		})
+	}
+
+	result, err := c.FolderService.DeleteFolder(uint(folderID), userID, ctx.Query("modo"))
+	if err != nil {
+		return ctx.Status(folderErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(result)
+}
+
+func folderErrorStatus(err error) int {
+	switch err.Error() {
+	case "pasta não encontrada":
+		return fiber.StatusNotFound
+	case "já existe uma pasta com este nome",
+		"a pasta de destino já tem uma subpasta com o mesmo nome":
+		return fiber.StatusConflict
+	case "nome da pasta é obrigatório",
+		"o nome da pasta não pode conter / ou \\",
+		"o nome da pasta deve ter no máximo 100 caracteres",
+		"pasta pai não encontrada",
+		"a pasta não pode ser movida para dentro de si mesma",
+		"modo de exclusão inválido, use mover ou cascata":
+		return fiber.StatusBadRequest
+	}
+	return fiber.StatusInternalServerError
+}
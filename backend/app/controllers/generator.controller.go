@@ -0,0 +1,35 @@
+package controllers
+
+import (
+	"github.com/Vicente/Password-Mobile-App/backend/app/services"
+	"github.com/Vicente/Password-Mobile-App/backend/app/types"
+	"github.com/gofiber/fiber/v2"
+)
+
+type GeneratorController struct{}
+
+func NewGeneratorController() *GeneratorController {
+	return &GeneratorController{}
+}
+
+func (c *GeneratorController) Generate(ctx *fiber.Ctx) error {
+	var req types.GenerateRequest
+
+	if len(ctx.Body()) > 0 {
+		if err := ctx.BodyParser(&req); err != nil {
+			return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Dados inválidos: " + err.Error(),
+			})
+		}
+	}
+
+	response, err := services.GeneratePassword(&req)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	ctx.Set(fiber.HeaderCacheControl, "no-store")
+	return ctx.Status(fiber.StatusOK).JSON(response)
+}
@@ -1,7 +1,12 @@
 package controllers
 
 import (
+	"bytes"
+	"errors"
+	"io"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/Vicente/Password-Mobile-App/backend/app/services"
 	"github.com/Vicente/Password-Mobile-App/backend/app/types"
@@ -43,6 +48,7 @@ func (c *ItemController) CreateItem(ctx *fiber.Ctx) error {
 		})
 	}
 
+	ctx.Set(fiber.HeaderETag, itemETag(response.Revisao))
 	return ctx.Status(fiber.StatusCreated).JSON(response)
 }
 
@@ -54,18 +60,393 @@ func (c *ItemController) GetItemsByUser(ctx *fiber.Ctx) error {
 		})
 	}
 
-	items, err := c.ItemService.GetItemsByUser(userID)
+	filter, err := parseItemFilter(ctx)
 	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	page, err := c.ItemService.GetItemsByUser(userID, filter)
+	if err != nil {
+		switch err.Error() {
+		case "ordem inválida, use nome, criado ou atualizado",
+			"tipo de item inválido, use login, cartao, identidade, nota ou wifi",
+			"limite deve estar entre 1 e 200",
+			"a busca deve ter no máximo 100 caracteres",
+			"cursor exige limite",
+			"cursor inválido":
+			return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
 		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": err.Error(),
 		})
 	}
 
-	if len(items) == 0 {
-		return ctx.SendStatus(fiber.StatusNoContent)
+	ctx.Set("X-Total-Count", strconv.FormatInt(page.Total, 10))
+	if page.Cursor != "" {
+		ctx.Set("X-Next-Cursor", page.Cursor)
 	}
+	return ctx.Status(fiber.StatusOK).JSON(page.Itens)
+}
 
-	return ctx.Status(fiber.StatusOK).JSON(items)
+// parseItemFilter lê os filtros, a ordenação e a paginação da query string.
+// A tag pode se repetir ou vir separada por vírgulas.
+func parseItemFilter(ctx *fiber.Ctx) (*types.ItemFilter, error) {
+	filter := &types.ItemFilter{
+		Tipo:   ctx.Query("tipo"),
+		Busca:  ctx.Query("busca"),
+		Ordem:  ctx.Query("ordem"),
+		Cursor: ctx.Query("cursor"),
+	}
+
+	if prefixo := ctx.Query("prefixo"); prefixo != "" {
+		value, err := strconv.ParseBool(prefixo)
+		if err != nil {
+			return nil, errors.New("prefixo deve ser true ou false")
+		}
+		filter.Prefixo = value
+	}
+
+	switch strings.ToLower(ctx.Query("direcao")) {
+	case "", "asc":
+	case "desc":
+		filter.Decrescente = true
+	default:
+		return nil, errors.New("direção inválida, use asc ou desc")
+	}
+
+	if limite := ctx.Query("limite"); limite != "" {
+		value, err := strconv.Atoi(limite)
+		if err != nil || value <= 0 {
+			return nil, errors.New("limite deve estar entre 1 e 200")
+		}
+		filter.Limite = value
+	}
+
+	if pasta := ctx.Query("pasta"); pasta != "" {
+		pastaID, err := strconv.ParseUint(pasta, 10, 32)
+		if err != nil {
+			return nil, errors.New("pasta inválida")
+		}
+		id := uint(pastaID)
+		filter.PastaID = &id
+	}
+
+	if subpastas := ctx.Query("subpastas"); subpastas != "" {
+		value, err := strconv.ParseBool(subpastas)
+		if err != nil {
+			return nil, errors.New("subpastas deve ser true ou false")
+		}
+		filter.Subpastas = value
+	}
+
+	if campos := ctx.Query("campos"); campos != "" {
+		for _, campo := range strings.Split(campos, ",") {
+			filter.Campos = append(filter.Campos, strings.ToLower(strings.TrimSpace(campo)))
+		}
+	}
+
+	seen := make(map[string]bool)
+	for _, value := range ctx.Context().QueryArgs().PeekMulti("tag") {
+		for _, tag := range strings.Split(string(value), ",") {
+			tag = strings.TrimSpace(tag)
+			if tag != "" && !seen[tag] {
+				seen[tag] = true
+				filter.Tags = append(filter.Tags, tag)
+			}
+		}
+	}
+
+	return filter, nil
+}
+
+func (c *ItemController) ReplaceItem(ctx *fiber.Ctx) error {
+	return c.updateItem(ctx, false)
+}
+
+func (c *ItemController) PatchItem(ctx *fiber.Ctx) error {
+	return c.updateItem(ctx, true)
+}
+
+func (c *ItemController) updateItem(ctx *fiber.Ctx, parcial bool) error {
+	itemID, err := strconv.ParseUint(ctx.Params("id"), 10, 32)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "ID do item inválido",
+		})
+	}
+
+	var req types.UpdateItemRequest
+
+	if err := ctx.BodyParser(&req); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Dados inválidos: " + err.Error(),
+		})
+	}
+
+	userID, ok := ctx.Locals("userID").(uint)
+	if !ok {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Usuário não autenticado",
+		})
+	}
+
+	if ifMatch := ctx.Get(fiber.HeaderIfMatch); ifMatch != "" {
+		revisao, ok := parseItemETag(ifMatch)
+		if !ok {
+			return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Cabeçalho If-Match inválido",
+			})
+		}
+		req.Revisao = revisao
+	}
+
+	req.ID = uint(itemID)
+	req.UserID = userID
+	req.Parcial = parcial
+
+	response, err := c.ItemService.UpdateItem(&req)
+	if err != nil {
+		switch err.Error() {
+		case "item não encontrado ou você não tem acesso a ele":
+			return ctx.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"error": "Você não tem acesso a este item",
+			})
+		case "o item foi modificado por outra requisição":
+			return ctx.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		case "revisão do item é obrigatória":
+			return ctx.Status(fiber.StatusPreconditionRequired).JSON(fiber.Map{
+				"error": "Informe a revisão do item no cabeçalho If-Match ou no campo revisao",
+			})
+		}
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	ctx.Set(fiber.HeaderETag, itemETag(response.Revisao))
+	return ctx.Status(fiber.StatusOK).JSON(response)
+}
+
+func (c *ItemController) GetItem(ctx *fiber.Ctx) error {
+	itemID, err := strconv.ParseUint(ctx.Params("id"), 10, 32)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "ID do item inválido",
+		})
+	}
+
+	userID, ok := ctx.Locals("userID").(uint)
+	if !ok {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Usuário não autenticado",
+		})
+	}
+
+	response, err := c.ItemService.GetItem(uint(itemID), userID)
+	if err != nil {
+		if err.Error() == "item não encontrado ou você não tem acesso a ele" {
+			return ctx.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"error": "Você não tem acesso a este item",
+			})
+		}
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	ctx.Set(fiber.HeaderETag, itemETag(response.Revisao))
+	return ctx.Status(fiber.StatusOK).JSON(response)
+}
+
+func (c *ItemController) GetItemTOTP(ctx *fiber.Ctx) error {
+	itemID, err := strconv.ParseUint(ctx.Params("id"), 10, 32)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "ID do item inválido",
+		})
+	}
+
+	userID, ok := ctx.Locals("userID").(uint)
+	if !ok {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Usuário não autenticado",
+		})
+	}
+
+	response, err := c.ItemService.GetItemTOTP(uint(itemID), userID)
+	if err != nil {
+		return itemOTPError(ctx, err)
+	}
+
+	// O código muda a cada período e não deve ficar em cache.
+	ctx.Set(fiber.HeaderCacheControl, "no-store")
+	return ctx.Status(fiber.StatusOK).JSON(response)
+}
+
+func (c *ItemController) NextItemHOTP(ctx *fiber.Ctx) error {
+	itemID, err := strconv.ParseUint(ctx.Params("id"), 10, 32)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "ID do item inválido",
+		})
+	}
+
+	userID, ok := ctx.Locals("userID").(uint)
+	if !ok {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Usuário não autenticado",
+		})
+	}
+
+	response, err := c.ItemService.NextItemHOTP(uint(itemID), userID)
+	if err != nil {
+		return itemOTPError(ctx, err)
+	}
+
+	ctx.Set(fiber.HeaderCacheControl, "no-store")
+	ctx.Set(fiber.HeaderETag, itemETag(response.Revisao))
+	return ctx.Status(fiber.StatusOK).JSON(response)
+}
+
+func itemOTPError(ctx *fiber.Ctx, err error) error {
+	switch err.Error() {
+	case "item não encontrado ou você não tem acesso a ele":
+		return ctx.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "Você não tem acesso a este item",
+		})
+	case "o item não tem TOTP configurado":
+		return ctx.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	case "o código de itens zero-knowledge é gerado no cliente",
+		"códigos HOTP são gerados com POST /api/item/:id/hotp",
+		"o autenticador do item não é HOTP":
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	case "o item foi modificado por outra requisição":
+		return ctx.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"error": err.Error(),
+	})
+}
+
+func (c *ItemController) GetItemHistory(ctx *fiber.Ctx) error {
+	itemID, err := strconv.ParseUint(ctx.Params("id"), 10, 32)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "ID do item inválido",
+		})
+	}
+
+	userID, ok := ctx.Locals("userID").(uint)
+	if !ok {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Usuário não autenticado",
+		})
+	}
+
+	history, err := c.ItemService.GetItemHistory(uint(itemID), userID)
+	if err != nil {
+		if err.Error() == "item não encontrado ou você não tem acesso a ele" {
+			return ctx.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"error": "Você não tem acesso a este item",
+			})
+		}
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(history)
+}
+
+func (c *ItemController) RestoreItemVersion(ctx *fiber.Ctx) error {
+	itemID, err := strconv.ParseUint(ctx.Params("id"), 10, 32)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "ID do item inválido",
+		})
+	}
+
+	versao, err := strconv.ParseUint(ctx.Params("version"), 10, 32)
+	if err != nil || versao == 0 {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Versão inválida",
+		})
+	}
+
+	userID, ok := ctx.Locals("userID").(uint)
+	if !ok {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Usuário não autenticado",
+		})
+	}
+
+	var revisao uint
+	if ifMatch := ctx.Get(fiber.HeaderIfMatch); ifMatch != "" {
+		if revisao, ok = parseItemETag(ifMatch); !ok {
+			return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Cabeçalho If-Match inválido",
+			})
+		}
+	}
+
+	response, err := c.ItemService.RestoreItemVersion(uint(itemID), userID, uint(versao), revisao)
+	if err != nil {
+		switch err.Error() {
+		case "item não encontrado ou você não tem acesso a ele":
+			return ctx.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"error": "Você não tem acesso a este item",
+			})
+		case "versão não encontrada no histórico do item":
+			return ctx.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		case "o item foi modificado por outra requisição":
+			return ctx.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		case "itens deste tipo não têm senha para restaurar":
+			return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		case "revisão do item é obrigatória":
+			return ctx.Status(fiber.StatusPreconditionRequired).JSON(fiber.Map{
+				"error": "Informe a revisão do item no cabeçalho If-Match",
+			})
+		}
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	ctx.Set(fiber.HeaderETag, itemETag(response.Revisao))
+	return ctx.Status(fiber.StatusOK).JSON(response)
+}
+
+func itemETag(revisao uint) string {
+	return `"` + strconv.FormatUint(uint64(revisao), 10) + `"`
+}
+
+func parseItemETag(value string) (uint, bool) {
+	value = strings.TrimPrefix(strings.TrimSpace(value), "W/")
+	value = strings.Trim(value, `"`)
+
+	revisao, err := strconv.ParseUint(value, 10, 32)
+	if err != nil || revisao == 0 {
+		return 0, false
+	}
+	return uint(revisao), true
 }
 
 func (c *ItemController) DeleteItem(ctx *fiber.Ctx) error {
@@ -104,3 +485,279 @@ func (c *ItemController) DeleteItem(ctx *fiber.Ctx) error {
 
 	return ctx.SendStatus(fiber.StatusNoContent)
 }
+
+func (c *ItemController) GetTrash(ctx *fiber.Ctx) error {
+	userID, ok := ctx.Locals("userID").(uint)
+	if !ok {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Usuário não autenticado",
+		})
+	}
+
+	items, err := c.ItemService.GetTrash(userID)
+	if err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	if len(items) == 0 {
+		return ctx.SendStatus(fiber.StatusNoContent)
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(items)
+}
+
+func (c *ItemController) RestoreFromTrash(ctx *fiber.Ctx) error {
+	itemID, err := strconv.ParseUint(ctx.Params("id"), 10, 32)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "ID do item inválido",
+		})
+	}
+
+	userID, ok := ctx.Locals("userID").(uint)
+	if !ok {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Usuário não autenticado",
+		})
+	}
+
+	response, err := c.ItemService.RestoreFromTrash(uint(itemID), userID)
+	if err != nil {
+		switch err.Error() {
+		case "item não encontrado na lixeira":
+			return ctx.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		case "já existe um item com este nome":
+			return ctx.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	ctx.Set(fiber.HeaderETag, itemETag(response.Revisao))
+	return ctx.Status(fiber.StatusOK).JSON(response)
+}
+
+func (c *ItemController) DeleteFromTrash(ctx *fiber.Ctx) error {
+	itemID, err := strconv.ParseUint(ctx.Params("id"), 10, 32)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "ID do item inválido",
+		})
+	}
+
+	userID, ok := ctx.Locals("userID").(uint)
+	if !ok {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Usuário não autenticado",
+		})
+	}
+
+	if err := c.ItemService.DeleteFromTrash(uint(itemID), userID); err != nil {
+		if err.Error() == "item não encontrado na lixeira" {
+			return ctx.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return ctx.SendStatus(fiber.StatusNoContent)
+}
+
+func (c *ItemController) ImportItems(ctx *fiber.Ctx) error {
+	userID, ok := ctx.Locals("userID").(uint)
+	if !ok {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Usuário não autenticado",
+		})
+	}
+
+	dryRun := ctx.QueryBool("dryRun") || ctx.FormValue("dryRun") == "true"
+
+	var reader io.Reader
+	if file, err := ctx.FormFile("arquivo"); err == nil {
+		opened, err := file.Open()
+		if err != nil {
+			return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Não foi possível ler o arquivo enviado",
+			})
+		}
+		defer opened.Close()
+		reader = opened
+	} else if len(ctx.Body()) > 0 && !strings.HasPrefix(ctx.Get(fiber.HeaderContentType), fiber.MIMEMultipartForm) {
+		reader = bytes.NewReader(ctx.Body())
+	} else {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Envie o CSV no campo arquivo ou no corpo da requisição",
+		})
+	}
+
+	result, err := c.ItemService.ImportCSV(userID, reader, dryRun)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	if dryRun {
+		return ctx.Status(fiber.StatusOK).JSON(result)
+	}
+	return ctx.Status(fiber.StatusCreated).JSON(result)
+}
+
+func (c *ItemController) ImportKDBX(ctx *fiber.Ctx) error {
+	userID, ok := ctx.Locals("userID").(uint)
+	if !ok {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Usuário não autenticado",
+		})
+	}
+
+	dryRun := ctx.QueryBool("dryRun") || ctx.FormValue("dryRun") == "true"
+
+	file, err := ctx.FormFile("arquivo")
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Envie o arquivo .kdbx no campo arquivo",
+		})
+	}
+
+	opened, err := file.Open()
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Não foi possível ler o arquivo enviado",
+		})
+	}
+	defer opened.Close()
+
+	result, err := c.ItemService.ImportKDBX(userID, opened, ctx.FormValue("senha"), dryRun)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	if dryRun {
+		return ctx.Status(fiber.StatusOK).JSON(result)
+	}
+	return ctx.Status(fiber.StatusCreated).JSON(result)
+}
+
+func (c *ItemController) ExportKDBX(ctx *fiber.Ctx) error {
+	var req types.KDBXExportRequest
+
+	if err := ctx.BodyParser(&req); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Dados inválidos: " + err.Error(),
+		})
+	}
+
+	userID, ok := ctx.Locals("userID").(uint)
+	if !ok {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Usuário não autenticado",
+		})
+	}
+
+	req.UserID = userID
+
+	content, err := c.ItemService.ExportKDBX(&req)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	ctx.Set(fiber.HeaderContentType, "application/octet-stream")
+	ctx.Set(fiber.HeaderCacheControl, "no-store")
+	ctx.Attachment("cofre.kdbx")
+	return ctx.Status(fiber.StatusOK).Send(content)
+}
+
+func (c *ItemController) ExportBackup(ctx *fiber.Ctx) error {
+	var req types.BackupExportRequest
+
+	if err := ctx.BodyParser(&req); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Dados inválidos: " + err.Error(),
+		})
+	}
+
+	userID, ok := ctx.Locals("userID").(uint)
+	if !ok {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Usuário não autenticado",
+		})
+	}
+
+	content, err := c.ItemService.ExportBackup(userID, req.Senha)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	ctx.Set(fiber.HeaderCacheControl, "no-store")
+	ctx.Attachment("cofre-" + time.Now().Format("2006-01-02") + ".json")
+	return ctx.Status(fiber.StatusOK).Send(content)
+}
+
+func (c *ItemController) RestoreBackup(ctx *fiber.Ctx) error {
+	userID, ok := ctx.Locals("userID").(uint)
+	if !ok {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Usuário não autenticado",
+		})
+	}
+
+	file, err := ctx.FormFile("arquivo")
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Envie a cópia de segurança no campo arquivo",
+		})
+	}
+
+	opened, err := file.Open()
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Não foi possível ler o arquivo enviado",
+		})
+	}
+	defer opened.Close()
+
+	data, err := io.ReadAll(opened)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Não foi possível ler o arquivo enviado",
+		})
+	}
+
+	modo := ctx.FormValue("modo")
+	if modo == "" {
+		modo = ctx.Query("modo")
+	}
+
+	result, err := c.ItemService.RestoreBackup(userID, data, ctx.FormValue("senha"), modo)
+	if err != nil {
+		if err.Error() == "a cópia tem itens que não podem ser restaurados, nada foi alterado" {
+			return ctx.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{
+				"error":     err.Error(),
+				"ignorados": result.Ignorados,
+			})
+		}
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(result)
+}
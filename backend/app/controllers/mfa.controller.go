@@ -0,0 +1,189 @@
+package controllers
+
+import (
+	"errors"
+
+	"github.com/Vicente/Password-Mobile-App/backend/app/ratelimit"
+	"github.com/Vicente/Password-Mobile-App/backend/app/services"
+	"github.com/Vicente/Password-Mobile-App/backend/app/types"
+	"github.com/gofiber/fiber/v2"
+)
+
+type MFAController struct {
+	MFAService *services.MFAService
+}
+
+func NewMFAController(mfaService *services.MFAService) *MFAController {
+	return &MFAController{
+		MFAService: mfaService,
+	}
+}
+
+func (c *MFAController) Status(ctx *fiber.Ctx) error {
+	userID, ok := ctx.Locals("userID").(uint)
+	if !ok {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Usuário não autenticado",
+		})
+	}
+
+	response, err := c.MFAService.Status(userID)
+	if err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(response)
+}
+
+func (c *MFAController) Setup(ctx *fiber.Ctx) error {
+	userID, ok := ctx.Locals("userID").(uint)
+	if !ok {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Usuário não autenticado",
+		})
+	}
+
+	response, err := c.MFAService.Setup(userID)
+	if err != nil {
+		if err.Error() == "a autenticação em dois fatores já está ativa" {
+			return ctx.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(response)
+}
+
+func (c *MFAController) Confirm(ctx *fiber.Ctx) error {
+	var req types.MFACodeRequest
+
+	if err := ctx.BodyParser(&req); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Dados inválidos: " + err.Error(),
+		})
+	}
+
+	userID, ok := ctx.Locals("userID").(uint)
+	if !ok {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Usuário não autenticado",
+		})
+	}
+
+	req.UserID = userID
+
+	response, err := c.MFAService.Confirm(&req)
+	if err != nil {
+		if err.Error() == "a autenticação em dois fatores já está ativa" {
+			return ctx.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(response)
+}
+
+func (c *MFAController) Verify(ctx *fiber.Ctx) error {
+	var req types.MFAVerifyRequest
+
+	if err := ctx.BodyParser(&req); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Dados inválidos: " + err.Error(),
+		})
+	}
+
+	req.Sessao = types.SessionInfo{
+		UserAgent: ctx.Get(fiber.HeaderUserAgent),
+		IP:        ctx.IP(),
+	}
+
+	response, err := c.MFAService.Verify(&req)
+	if err != nil {
+		var limitErr *ratelimit.Error
+		if errors.As(err, &limitErr) {
+			return tooManyRequests(ctx, limitErr)
+		}
+		return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"token":        response.Token,
+		"refreshToken": response.RefreshToken,
+		"user":         response.User.Profile(),
+	})
+}
+
+func (c *MFAController) Disable(ctx *fiber.Ctx) error {
+	var req types.MFADisableRequest
+
+	if err := ctx.BodyParser(&req); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Dados inválidos: " + err.Error(),
+		})
+	}
+
+	userID, ok := ctx.Locals("userID").(uint)
+	if !ok {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Usuário não autenticado",
+		})
+	}
+
+	req.UserID = userID
+
+	if err := c.MFAService.Disable(&req); err != nil {
+		var limitErr *ratelimit.Error
+		if errors.As(err, &limitErr) {
+			return tooManyRequests(ctx, limitErr)
+		}
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return ctx.SendStatus(fiber.StatusNoContent)
+}
+
+func (c *MFAController) RegenerateRecoveryCodes(ctx *fiber.Ctx) error {
+	var req types.MFACodeRequest
+
+	if err := ctx.BodyParser(&req); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Dados inválidos: " + err.Error(),
+		})
+	}
+
+	userID, ok := ctx.Locals("userID").(uint)
+	if !ok {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Usuário não autenticado",
+		})
+	}
+
+	req.UserID = userID
+
+	response, err := c.MFAService.RegenerateRecoveryCodes(&req)
+	if err != nil {
+		var limitErr *ratelimit.Error
+		if errors.As(err, &limitErr) {
+			return tooManyRequests(ctx, limitErr)
+		}
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(response)
+}
@@ -0,0 +1,113 @@
+package controllers
+
+import (
+	"errors"
+
+	"github.com/Vicente/Password-Mobile-App/backend/app/ratelimit"
+	"github.com/Vicente/Password-Mobile-App/backend/app/services"
+	"github.com/Vicente/Password-Mobile-App/backend/app/types"
+	"github.com/gofiber/fiber/v2"
+)
+
+type PasswordController struct {
+	PasswordService *services.PasswordService
+}
+
+func NewPasswordController(passwordService *services.PasswordService) *PasswordController {
+	return &PasswordController{
+		PasswordService: passwordService,
+	}
+}
+
+func (c *PasswordController) ChangePassword(ctx *fiber.Ctx) error {
+	var req types.ChangePasswordRequest
+
+	if err := ctx.BodyParser(&req); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Dados inválidos: " + err.Error(),
+		})
+	}
+
+	userID, ok := ctx.Locals("userID").(uint)
+	if !ok {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Usuário não autenticado",
+		})
+	}
+
+	req.UserID = userID
+	req.SessionID, _ = ctx.Locals("sessionID").(uint)
+	req.Sessao = types.SessionInfo{
+		UserAgent: ctx.Get(fiber.HeaderUserAgent),
+		IP:        ctx.IP(),
+	}
+
+	if err := c.PasswordService.ChangePassword(&req); err != nil {
+		var limitErr *ratelimit.Error
+		if errors.As(err, &limitErr) {
+			return tooManyRequests(ctx, limitErr)
+		}
+		if err.Error() == "senha atual incorreta" {
+			return ctx.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Senha alterada com sucesso",
+	})
+}
+
+func (c *PasswordController) ForgotPassword(ctx *fiber.Ctx) error {
+	var req types.ForgotPasswordRequest
+
+	if err := ctx.BodyParser(&req); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Dados inválidos: " + err.Error(),
+		})
+	}
+
+	if err := c.PasswordService.ForgotPassword(&req); err != nil {
+		if err.Error() == "email é obrigatório" {
+			return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return ctx.Status(fiber.StatusAccepted).JSON(fiber.Map{
+		"message": "Se o email estiver cadastrado, você receberá um link para redefinir a senha",
+	})
+}
+
+func (c *PasswordController) ResetPassword(ctx *fiber.Ctx) error {
+	var req types.ResetPasswordRequest
+
+	if err := ctx.BodyParser(&req); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Dados inválidos: " + err.Error(),
+		})
+	}
+
+	req.Sessao = types.SessionInfo{
+		UserAgent: ctx.Get(fiber.HeaderUserAgent),
+		IP:        ctx.IP(),
+	}
+
+	if err := c.PasswordService.ResetPassword(&req); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Senha redefinida com sucesso, faça login novamente",
+	})
+}
@@ -0,0 +1,46 @@
+package controllers
+
+import (
+	"github.com/Vicente/Password-Mobile-App/backend/app/services"
+	"github.com/gofiber/fiber/v2"
+)
+
+type ReportController struct {
+	ItemService *services.ItemService
+}
+
+func NewReportController(itemService *services.ItemService) *ReportController {
+	return &ReportController{
+		ItemService: itemService,
+	}
+}
+
+func (c *ReportController) Health(ctx *fiber.Ctx) error {
+	userID, ok := ctx.Locals("userID").(uint)
+	if !ok {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Usuário não autenticado",
+		})
+	}
+
+	dias := ctx.QueryInt("dias")
+	if dias < 0 {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "dias deve ser um número positivo",
+		})
+	}
+
+	report, err := c.ItemService.HealthReport(userID, dias)
+	if err != nil {
+		if err.Error() == "o relatório de saúde não está disponível no modo zero-knowledge" {
+			return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(report)
+}
@@ -0,0 +1,139 @@
+package controllers
+
+import (
+	"strconv"
+
+	"github.com/Vicente/Password-Mobile-App/backend/app/services"
+	"github.com/Vicente/Password-Mobile-App/backend/app/types"
+	"github.com/gofiber/fiber/v2"
+)
+
+type TagController struct {
+	TagService *services.TagService
+}
+
+func NewTagController(tagService *services.TagService) *TagController {
+	return &TagController{
+		TagService: tagService,
+	}
+}
+
+func (c *TagController) ListTags(ctx *fiber.Ctx) error {
+	userID, ok := ctx.Locals("userID").(uint)
+	if !ok {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Usuário não autenticado",
+		})
+	}
+
+	tags, err := c.TagService.ListTags(userID)
+	if err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(tags)
+}
+
+func (c *TagController) CreateTag(ctx *fiber.Ctx) error {
+	var req types.TagRequest
+
+	if err := ctx.BodyParser(&req); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Dados inválidos: " + err.Error(),
+		})
+	}
+
+	userID, ok := ctx.Locals("userID").(uint)
+	if !ok {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Usuário não autenticado",
+		})
+	}
+
+	req.UserID = userID
+
+	tag, err := c.TagService.CreateTag(&req)
+	if err != nil {
+		return ctx.Status(tagErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(tag)
+}
+
+func (c *TagController) RenameTag(ctx *fiber.Ctx) error {
+	tagID, err := strconv.ParseUint(ctx.Params("id"), 10, 32)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "ID da tag inválido",
+		})
+	}
+
+	var req types.TagRequest
+
+	if err := ctx.BodyParser(&req); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Dados inválidos: " + err.Error(),
+		})
+	}
+
+	userID, ok := ctx.Locals("userID").(uint)
+	if !ok {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Usuário não autenticado",
+		})
+	}
+
+	req.ID = uint(tagID)
+	req.UserID = userID
+
+	tag, err := c.TagService.RenameTag(&req)
+	if err != nil {
+		return ctx.Status(tagErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(tag)
+}
+
+func (c *TagController) DeleteTag(ctx *fiber.Ctx) error {
+	tagID, err := strconv.ParseUint(ctx.Params("id"), 10, 32)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "ID da tag inválido",
+		})
+	}
+
+	userID, ok := ctx.Locals("userID").(uint)
+	if !ok {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Usuário não autenticado",
+		})
+	}
+
+	if err := c.TagService.DeleteTag(uint(tagID), userID); err != nil {
+		return ctx.Status(tagErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return ctx.SendStatus(fiber.StatusNoContent)
+}
+
+func tagErrorStatus(err error) int {
+	switch err.Error() {
+	case "tag não encontrada":
+		return fiber.StatusNotFound
+	case "já existe uma tag com este nome":
+		return fiber.StatusConflict
+	case "nome da tag é obrigatório",
+		"o nome da tag não pode conter vírgulas",
+		"o nome da tag deve ter no máximo 50 caracteres":
+		return fiber.StatusBadRequest
+	}
+	return fiber.StatusInternalServerError
+}
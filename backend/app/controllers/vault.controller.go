@@ -0,0 +1,69 @@
+package controllers
+
+import (
+	"github.com/Vicente/Password-Mobile-App/backend/app/services"
+	"github.com/Vicente/Password-Mobile-App/backend/app/types"
+	"github.com/gofiber/fiber/v2"
+)
+
+type VaultController struct {
+	VaultService *services.VaultService
+}
+
+func NewVaultController(vaultService *services.VaultService) *VaultController {
+	return &VaultController{
+		VaultService: vaultService,
+	}
+}
+
+func (c *VaultController) GetConfig(ctx *fiber.Ctx) error {
+	userID, ok := ctx.Locals("userID").(uint)
+	if !ok {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Usuário não autenticado",
+		})
+	}
+
+	response, err := c.VaultService.GetConfig(userID)
+	if err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(response)
+}
+
+func (c *VaultController) EnableZeroKnowledge(ctx *fiber.Ctx) error {
+	var req types.EnableZeroKnowledgeRequest
+
+	if err := ctx.BodyParser(&req); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Dados inválidos: " + err.Error(),
+		})
+	}
+
+	userID, ok := ctx.Locals("userID").(uint)
+	if !ok {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Usuário não autenticado",
+		})
+	}
+
+	req.UserID = userID
+
+	response, err := c.VaultService.EnableZeroKnowledge(&req)
+	if err != nil {
+		if err.Error() == "o cofre deve estar vazio para ativar o modo zero-knowledge" ||
+			err.Error() == "o modo zero-knowledge já está ativo" {
+			return ctx.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(response)
+}
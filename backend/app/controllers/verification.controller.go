@@ -0,0 +1,59 @@
+package controllers
+
+import (
+	"errors"
+
+	"github.com/Vicente/Password-Mobile-App/backend/app/ratelimit"
+	"github.com/Vicente/Password-Mobile-App/backend/app/services"
+	"github.com/gofiber/fiber/v2"
+)
+
+type VerificationController struct {
+	VerificationService *services.VerificationService
+}
+
+func NewVerificationController(verificationService *services.VerificationService) *VerificationController {
+	return &VerificationController{
+		VerificationService: verificationService,
+	}
+}
+
+func (c *VerificationController) Verify(ctx *fiber.Ctx) error {
+	if err := c.VerificationService.Verify(ctx.Query("token")); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Email verificado com sucesso",
+	})
+}
+
+func (c *VerificationController) Resend(ctx *fiber.Ctx) error {
+	userID, ok := ctx.Locals("userID").(uint)
+	if !ok {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Usuário não autenticado",
+		})
+	}
+
+	if err := c.VerificationService.Resend(userID); err != nil {
+		var limitErr *ratelimit.Error
+		if errors.As(err, &limitErr) {
+			return tooManyRequests(ctx, limitErr)
+		}
+		if err.Error() == "email já verificado" {
+			return ctx.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return ctx.Status(fiber.StatusAccepted).JSON(fiber.Map{
+		"message": "Email de verificação reenviado",
+	})
+}
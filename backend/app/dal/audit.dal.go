@@ -0,0 +1,29 @@
+package dal
+
+import (
+	"github.com/Vicente/Password-Mobile-App/backend/app/types"
+	"gorm.io/gorm"
+)
+
+type AuditDAL struct {
+	DB *gorm.DB
+}
+
+func NewAuditDAL(db *gorm.DB) *AuditDAL {
+	return &AuditDAL{
+		DB: db,
+	}
+}
+
+func (d *AuditDAL) CreateEvent(event *types.AuditEvent) error {
+	return d.DB.Create(event).Error
+}
+
+func (d *AuditDAL) GetEventsByUserID(userID uint) ([]types.AuditEvent, error) {
+	var events []types.AuditEvent
+	result := d.DB.Where("user_id = ?", userID).Order("created_at").Find(&events)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return events, nil
+}
@@ -36,4 +36,123 @@ func (d *AuthDAL) GetUserByEmail(email string) (*types.User, error) {
 		return nil, result.Error
 	}
 	return &user, nil
-} 
\ No newline at end of file
+}
+
+func (d *AuthDAL) GetUserByID(id uint) (*types.User, error) {
+	var user types.User
+	result := d.DB.Where("id = ?", id).First(&user)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &user, nil
+}
+
+// ConfirmEmail marca o email atual como verificado ou, se o endereço for o
+// email pendente do usuário, passa a usá-lo como email da conta.
+func (d *AuthDAL) ConfirmEmail(id uint, email string) error {
+	if email == "" {
+		return errors.New("link de verificação inválido ou expirado")
+	}
+
+	return d.DB.Transaction(func(tx *gorm.DB) error {
+		var user types.User
+		if err := tx.Where("id = ?", id).First(&user).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New("link de verificação inválido ou expirado")
+			}
+			return err
+		}
+
+		switch email {
+		case user.Email:
+			return tx.Model(&user).Update("email_verificado", true).Error
+		case user.EmailPendente:
+			var count int64
+			if err := tx.Model(&types.User{}).Where("email = ? AND id <> ?", email, id).Count(&count).Error; err != nil {
+				return err
+			}
+			if count > 0 {
+				return errors.New("email já cadastrado")
+			}
+			return tx.Model(&user).Updates(map[string]interface{}{
+				"email":            email,
+				"email_pendente":   "",
+				"email_verificado": true,
+			}).Error
+		default:
+			return errors.New("link de verificação inválido ou expirado")
+		}
+	})
+}
+
+// AddEmailVerifiedColumn cria email_verificado marcando como verificados os
+// usuários que já existiam, para que a nova exigência não bloqueie contas
+// antigas. Só age enquanto a coluna não existe, antes do AutoMigrate.
+func (d *AuthDAL) AddEmailVerifiedColumn() error {
+	migrator := d.DB.Migrator()
+	if !migrator.HasTable(&types.User{}) || migrator.HasColumn(&types.User{}, "EmailVerificado") {
+		return nil
+	}
+
+	return d.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("ALTER TABLE users ADD COLUMN email_verificado boolean NOT NULL DEFAULT true").Error; err != nil {
+			return err
+		}
+		return tx.Exec("ALTER TABLE users ALTER COLUMN email_verificado SET DEFAULT false").Error
+	})
+}
+
+func (d *AuthDAL) EmailInUse(email string, exceptID uint) (bool, error) {
+	var count int64
+	result := d.DB.Model(&types.User{}).Where("email = ? AND id <> ?", email, exceptID).Count(&count)
+	return count > 0, result.Error
+}
+
+func (d *AuthDAL) UpdateProfile(user *types.User) error {
+	return d.DB.Model(user).Select("nome", "data_nascimento", "email_pendente").Updates(user).Error
+}
+
+// DeleteUser remove definitivamente o usuário e todos os dados ligados a ele.
+// Novas tabelas com user_id precisam ser incluídas aqui.
+func (d *AuthDAL) DeleteUser(id uint) error {
+	return d.DB.Transaction(func(tx *gorm.DB) error {
+		sessions := tx.Unscoped().Model(&types.Session{}).Select("id").Where("user_id = ?", id)
+		if err := tx.Where("session_id IN (?)", sessions).Delete(&types.RefreshToken{}).Error; err != nil {
+			return err
+		}
+
+		items := tx.Unscoped().Model(&types.Item{}).Select("id").Where("user_id = ?", id)
+		if err := tx.Exec("DELETE FROM item_tags WHERE item_id IN (?)", items).Error; err != nil {
+			return err
+		}
+
+		models := []interface{}{
+			&types.ItemHistory{},
+			&types.Item{},
+			&types.Tag{},
+			&types.Folder{},
+			&types.Session{},
+			&types.RecoveryCode{},
+			&types.MFAConfig{},
+			&types.PasswordReset{},
+			&types.VaultConfig{},
+			&types.UserKey{},
+			&types.AuditEvent{},
+			&types.ExportJob{},
+		}
+		for _, model := range models {
+			if err := tx.Unscoped().Where("user_id = ?", id).Delete(model).Error; err != nil {
+				return err
+			}
+		}
+
+		result := tx.Unscoped().Where("id = ?", id).Delete(&types.User{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errors.New("usuário não encontrado")
+		}
+		return nil
+	})
+}
@@ -0,0 +1,82 @@
+package dal
+
+import (
+	"time"
+
+	"github.com/Vicente/Password-Mobile-App/backend/app/types"
+	"gorm.io/gorm"
+)
+
+type ExportDAL struct {
+	DB *gorm.DB
+}
+
+func NewExportDAL(db *gorm.DB) *ExportDAL {
+	return &ExportDAL{
+		DB: db,
+	}
+}
+
+func (d *ExportDAL) CreateJob(job *types.ExportJob) error {
+	return d.DB.Create(job).Error
+}
+
+func (d *ExportDAL) SaveJob(job *types.ExportJob) error {
+	return d.DB.Save(job).Error
+}
+
+func (d *ExportDAL) GetJobByID(id uint) (*types.ExportJob, error) {
+	var job types.ExportJob
+	result := d.DB.Where("id = ?", id).First(&job)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &job, nil
+}
+
+func (d *ExportDAL) GetUserJob(id uint, userID uint) (*types.ExportJob, error) {
+	var job types.ExportJob
+	result := d.DB.Omit("conteudo").Where("id = ? AND user_id = ?", id, userID).First(&job)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &job, nil
+}
+
+func (d *ExportDAL) GetJobsByUserID(userID uint) ([]types.ExportJob, error) {
+	var jobs []types.ExportJob
+	result := d.DB.Omit("conteudo").Where("user_id = ?", userID).Order("created_at DESC").Find(&jobs)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return jobs, nil
+}
+
+func (d *ExportDAL) HasActiveJob(userID uint) (bool, error) {
+	var count int64
+	result := d.DB.Model(&types.ExportJob{}).
+		Where("user_id = ? AND status IN ?", userID, []string{types.ExportPending, types.ExportRunning}).
+		Count(&count)
+	return count > 0, result.Error
+}
+
+// FailInterruptedJobs marca como erro as exportações que estavam em andamento
+// quando o servidor parou.
+func (d *ExportDAL) FailInterruptedJobs() (int64, error) {
+	result := d.DB.Model(&types.ExportJob{}).
+		Where("status IN ?", []string{types.ExportPending, types.ExportRunning}).
+		Updates(map[string]interface{}{"status": types.ExportFailed, "erro": "exportação interrompida, solicite novamente"})
+	return result.RowsAffected, result.Error
+}
+
+// DeleteJob remove a exportação e informa se ela ainda existia, para que dois
+// downloads simultâneos do mesmo link não sejam ambos atendidos.
+func (d *ExportDAL) DeleteJob(id uint) (bool, error) {
+	result := d.DB.Where("id = ?", id).Delete(&types.ExportJob{})
+	return result.RowsAffected > 0, result.Error
+}
+
+func (d *ExportDAL) DeleteExpiredJobs(now time.Time) (int64, error) {
+	result := d.DB.Where("expira_em IS NOT NULL AND expira_em < ?", now).Delete(&types.ExportJob{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,170 @@
+package dal
+
+import (
+	"errors"
+
+	"github.com/Vicente/Password-Mobile-App/backend/app/types"
+	"gorm.io/gorm"
+)
+
+type FolderDAL struct {
+	DB *gorm.DB
+}
+
+func NewFolderDAL(db *gorm.DB) *FolderDAL {
+	return &FolderDAL{
+		DB: db,
+	}
+}
+
+func (d *FolderDAL) GetFoldersByUserID(userID uint) ([]types.Folder, error) {
+	var folders []types.Folder
+	result := d.DB.Where("user_id = ?", userID).Order("nome, id").Find(&folders)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return folders, nil
+}
+
+func (d *FolderDAL) GetFolderByID(id uint, userID uint) (*types.Folder, error) {
+	var folder types.Folder
+	result := d.DB.Where("id = ? AND user_id = ?", id, userID).First(&folder)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &folder, nil
+}
+
+func inFolder(db *gorm.DB, column string, parentID *uint) *gorm.DB {
+	if parentID == nil {
+		return db.Where(column + " IS NULL")
+	}
+	return db.Where(column+" = ?", *parentID)
+}
+
+func (d *FolderDAL) checkSiblingName(folder *types.Folder) error {
+	var existing types.Folder
+	query := d.DB.Where("user_id = ? AND nome = ? AND id <> ?", folder.UserID, folder.Nome, folder.ID)
+	result := inFolder(query, "parent_id", folder.ParentID).First(&existing)
+	if result.Error == nil {
+		return errors.New("já existe uma pasta com este nome")
+	} else if !errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return result.Error
+	}
+	return nil
+}
+
+func (d *FolderDAL) CreateFolder(folder *types.Folder) error {
+	if err := d.checkSiblingName(folder); err != nil {
+		return err
+	}
+	return d.DB.Create(folder).Error
+}
+
+func (d *FolderDAL) UpdateFolder(folder *types.Folder) error {
+	if err := d.checkSiblingName(folder); err != nil {
+		return err
+	}
+	return d.DB.Model(folder).Select("nome", "parent_id").Updates(folder).Error
+}
+
+// EnsurePath devolve a pasta do caminho informado (nomes a partir da raiz),
+// criando as que faltarem. Um caminho vazio é a raiz.
+func (d *FolderDAL) EnsurePath(userID uint, names []string) (*uint, error) {
+	var parentID *uint
+	for _, name := range names {
+		var folder types.Folder
+		query := d.DB.Where("user_id = ? AND nome = ?", userID, name)
+		result := inFolder(query, "parent_id", parentID).Order("id").First(&folder)
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			folder = types.Folder{Nome: name, ParentID: parentID, UserID: userID}
+			if err := d.DB.Create(&folder).Error; err != nil {
+				return nil, err
+			}
+		} else if result.Error != nil {
+			return nil, result.Error
+		}
+
+		id := folder.ID
+		parentID = &id
+	}
+	return parentID, nil
+}
+
+func (d *FolderDAL) CountItemsByFolder(userID uint) (map[uint]int64, error) {
+	var rows []struct {
+		PastaID uint
+		Total   int64
+	}
+	result := d.DB.Model(&types.Item{}).
+		Select("pasta_id, COUNT(*) AS total").
+		Where("user_id = ? AND pasta_id IS NOT NULL", userID).
+		Group("pasta_id").
+		Scan(&rows)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	counts := make(map[uint]int64, len(rows))
+	for _, row := range rows {
+		counts[row.PastaID] = row.Total
+	}
+	return counts, nil
+}
+
+// DeleteFolderMovingContents remove a pasta levando suas subpastas e itens
+// (inclusive os da lixeira) para a pasta pai.
+func (d *FolderDAL) DeleteFolderMovingContents(folder *types.Folder) (int64, error) {
+	var moved int64
+	err := d.DB.Transaction(func(tx *gorm.DB) error {
+		children := tx.Model(&types.Folder{}).Select("nome").Where("parent_id = ?", folder.ID)
+		var conflicts int64
+		query := tx.Model(&types.Folder{}).Where("user_id = ? AND id <> ? AND nome IN (?)", folder.UserID, folder.ID, children)
+		if err := inFolder(query, "parent_id", folder.ParentID).Count(&conflicts).Error; err != nil {
+			return err
+		}
+		if conflicts > 0 {
+			return errors.New("a pasta de destino já tem uma subpasta com o mesmo nome")
+		}
+
+		if err := tx.Model(&types.Folder{}).Where("parent_id = ?", folder.ID).Update("parent_id", folder.ParentID).Error; err != nil {
+			return err
+		}
+
+		result := tx.Unscoped().Model(&types.Item{}).Where("pasta_id = ?", folder.ID).UpdateColumn("pasta_id", folder.ParentID)
+		if result.Error != nil {
+			return result.Error
+		}
+		moved = result.RowsAffected
+
+		return tx.Delete(folder).Error
+	})
+	return moved, err
+}
+
+// DeleteFolders remove as pastas indicadas e manda para a lixeira os itens
+// que estavam nelas. Os itens saem das pastas, já que elas deixam de existir.
+func (d *FolderDAL) DeleteFolders(userID uint, ids []uint) (int64, error) {
+	var trashed int64
+	err := d.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("user_id = ? AND pasta_id IN ?", userID, ids).Delete(&types.Item{})
+		if result.Error != nil {
+			return result.Error
+		}
+		trashed = result.RowsAffected
+
+		if err := tx.Unscoped().Model(&types.Item{}).Where("user_id = ? AND pasta_id IN ?", userID, ids).UpdateColumn("pasta_id", nil).Error; err != nil {
+			return err
+		}
+
+		return tx.Where("user_id = ? AND id IN ?", userID, ids).Delete(&types.Folder{}).Error
+	})
+	return trashed, err
+}
+
+func (d *FolderDAL) DeleteAllFoldersByUserID(userID uint) error {
+	if err := d.DB.Unscoped().Model(&types.Item{}).Where("user_id = ?", userID).UpdateColumn("pasta_id", nil).Error; err != nil {
+		return err
+	}
+	return d.DB.Where("user_id = ?", userID).Delete(&types.Folder{}).Error
+}
@@ -2,6 +2,9 @@ package dal
 
 import (
 	"errors"
+	"fmt"
+	"strings"
+	"time"
 
 	"github.com/Vicente/Password-Mobile-App/backend/app/types"
 	"gorm.io/gorm"
@@ -17,6 +20,12 @@ func NewItemDAL(db *gorm.DB) *ItemDAL {
 	}
 }
 
+func (d *ItemDAL) Transaction(fn func(tx *ItemDAL) error) error {
+	return d.DB.Transaction(func(tx *gorm.DB) error {
+		return fn(&ItemDAL{DB: tx})
+	})
+}
+
 func (d *ItemDAL) CreateItem(item *types.Item) error {
 	var existingItem types.Item
 	result := d.DB.Where("nome = ? AND user_id = ?", item.Nome, item.UserID).First(&existingItem)
@@ -31,22 +40,224 @@ func (d *ItemDAL) CreateItem(item *types.Item) error {
 
 func (d *ItemDAL) GetItemsByUserID(userID uint) ([]types.Item, error) {
 	var items []types.Item
-	result := d.DB.Where("user_id = ?", userID).Find(&items)
+	result := d.DB.Preload("Tags").Where("user_id = ?", userID).Find(&items)
 	if result.Error != nil {
 		return nil, result.Error
 	}
 	return items, nil
 }
 
+// itemSortColumns mapeia a ordem da listagem para a coluna do banco. Os
+// nomes vêm desta tabela, nunca da requisição.
+var itemSortColumns = map[string]string{
+	types.ItemSortName:    "nome",
+	types.ItemSortCreated: "created_at",
+	types.ItemSortUpdated: "updated_at",
+}
+
+// ListItems aplica o filtro, a ordenação e a paginação direto na consulta.
+// O total conta todos os itens do filtro, sem o cursor e o limite.
+func (d *ItemDAL) ListItems(userID uint, filter *types.ItemFilter) ([]types.Item, int64, error) {
+	var total int64
+	if err := d.filterItems(userID, filter).Model(&types.Item{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	column, ok := itemSortColumns[filter.Ordem]
+	if !ok {
+		column = itemSortColumns[types.ItemSortName]
+	}
+	direction, op := "ASC", ">"
+	if filter.Decrescente {
+		direction, op = "DESC", "<"
+	}
+
+	query := d.filterItems(userID, filter).Preload("Tags")
+	if cursor := filter.Apos; cursor != nil {
+		var value interface{} = cursor.Data
+		if column == "nome" {
+			value = cursor.Nome
+		}
+		query = query.Where(fmt.Sprintf("(%s, id) %s (?, ?)", column, op), value, cursor.ID)
+	}
+	query = query.Order(fmt.Sprintf("%s %s, id %s", column, direction, direction))
+	if filter.Limite > 0 {
+		query = query.Limit(filter.Limite)
+	}
+
+	var items []types.Item
+	if err := query.Find(&items).Error; err != nil {
+		return nil, 0, err
+	}
+	return items, total, nil
+}
+
+// itemSearchColumns liga os campos aceitos na busca às colunas em texto
+// claro. As notas ficam de fora por serem guardadas criptografadas.
+var itemSearchColumns = map[string]string{
+	types.ItemSearchName: "nome",
+	types.ItemSearchURL:  "url",
+}
+
+func (d *ItemDAL) filterItems(userID uint, filter *types.ItemFilter) *gorm.DB {
+	query := d.DB.Where("user_id = ?", userID)
+
+	if filter.PastaID != nil {
+		switch {
+		case *filter.PastaID == 0:
+			query = query.Where("pasta_id IS NULL")
+		case filter.Subpastas:
+			query = query.Where(`pasta_id IN (
+				WITH RECURSIVE subpastas AS (
+					SELECT id FROM folders WHERE id = ? AND user_id = ?
+					UNION ALL
+					SELECT folders.id FROM folders JOIN subpastas ON folders.parent_id = subpastas.id
+				)
+				SELECT id FROM subpastas
+			)`, *filter.PastaID, userID)
+		default:
+			query = query.Where("pasta_id = ?", *filter.PastaID)
+		}
+	}
+
+	if len(filter.Tags) > 0 {
+		tagged := d.DB.Table("item_tags").
+			Select("item_tags.item_id").
+			Joins("JOIN tags ON tags.id = item_tags.tag_id").
+			Where("tags.user_id = ? AND tags.nome IN ?", userID, filter.Tags).
+			Group("item_tags.item_id").
+			Having("COUNT(DISTINCT tags.id) = ?", len(filter.Tags))
+		query = query.Where("id IN (?)", tagged)
+	}
+
+	if filter.Tipo != "" {
+		query = query.Where("tipo = ?", filter.Tipo)
+	}
+
+	if filter.Busca != "" {
+		pattern := escapeLike(filter.Busca) + "%"
+		if !filter.Prefixo {
+			pattern = "%" + pattern
+		}
+		campos := filter.Campos
+		if len(campos) == 0 {
+			campos = []string{types.ItemSearchName, types.ItemSearchURL}
+		}
+
+		conditions := make([]string, 0, len(campos))
+		args := make([]interface{}, 0, len(campos))
+		seen := make(map[string]bool)
+		for _, campo := range campos {
+			if column, ok := itemSearchColumns[campo]; ok && !seen[column] {
+				seen[column] = true
+				conditions = append(conditions, column+" ILIKE ?")
+				args = append(args, pattern)
+			}
+		}
+		query = query.Where("("+strings.Join(conditions, " OR ")+")", args...)
+	}
+
+	return query
+}
+
+// escapeLike protege os curingas do LIKE no texto buscado.
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(value)
+}
+
+// EnsureListIndexes cria os índices da listagem, um por ordenação, que o
+// AutoMigrate não consegue declarar nas colunas de gorm.Model.
+func (d *ItemDAL) EnsureListIndexes() error {
+	for _, stmt := range []string{
+		"CREATE INDEX IF NOT EXISTS idx_items_user_nome ON items (user_id, nome, id)",
+		"CREATE INDEX IF NOT EXISTS idx_items_user_criado ON items (user_id, created_at, id)",
+		"CREATE INDEX IF NOT EXISTS idx_items_user_atualizado ON items (user_id, updated_at, id)",
+	} {
+		if err := d.DB.Exec(stmt).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// EnsureSearchIndexes cria índices de trigramas para a busca por trechos do
+// nome e da URL. Eles dependem da extensão pg_trgm; sem ela a busca continua
+// funcionando, mas percorre todos os itens do usuário.
+func (d *ItemDAL) EnsureSearchIndexes() error {
+	for _, stmt := range []string{
+		"CREATE EXTENSION IF NOT EXISTS pg_trgm",
+		"CREATE INDEX IF NOT EXISTS idx_items_nome_trgm ON items USING gin (nome gin_trgm_ops)",
+		"CREATE INDEX IF NOT EXISTS idx_items_url_trgm ON items USING gin (url gin_trgm_ops)",
+	} {
+		if err := d.DB.Exec(stmt).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (d *ItemDAL) GetItemByID(id uint, userID uint) (*types.Item, error) {
 	var item types.Item
-	result := d.DB.Where("id = ? AND user_id = ?", id, userID).First(&item)
+	result := d.DB.Preload("Tags").Where("id = ? AND user_id = ?", id, userID).First(&item)
 	if result.Error != nil {
 		return nil, result.Error
 	}
 	return &item, nil
 }
 
+func (d *ItemDAL) UpdateItem(item *types.Item, revisao uint) error {
+	if item.Nome != "" {
+		var existingItem types.Item
+		result := d.DB.Where("nome = ? AND user_id = ? AND id <> ?", item.Nome, item.UserID, item.ID).First(&existingItem)
+		if result.Error == nil {
+			return errors.New("já existe um item com este nome")
+		} else if !errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return result.Error
+		}
+	}
+
+	result := d.DB.Model(&types.Item{}).
+		Where("id = ? AND user_id = ? AND revisao = ?", item.ID, item.UserID, revisao).
+		Updates(map[string]interface{}{
+			"nome":           item.Nome,
+			"usuario":        item.Usuario,
+			"url":            item.URL,
+			"senha_cifrada":  item.SenhaCifrada,
+			"senha_hmac":     item.SenhaHMAC,
+			"notas_cifradas": item.NotasCifradas,
+			"blob":           item.Blob,
+			"tipo":           item.Tipo,
+			"dados_cifrados": item.DadosCifrados,
+			"forca_senha":    item.ForcaSenha,
+			"senha_vazada":   item.SenhaVazada,
+			"pasta_id":       item.PastaID,
+			"revisao":        gorm.Expr("revisao + 1"),
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		if _, err := d.GetItemByID(item.ID, item.UserID); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New("item não encontrado ou você não tem acesso a ele")
+			}
+			return err
+		}
+		return errors.New("o item foi modificado por outra requisição")
+	}
+
+	return d.DB.Where("id = ? AND user_id = ?", item.ID, item.UserID).First(item).Error
+}
+
+func (d *ItemDAL) ReplaceItemTags(item *types.Item, tags []types.Tag) error {
+	association := d.DB.Model(item).Association("Tags")
+	if len(tags) == 0 {
+		return association.Clear()
+	}
+	return association.Replace(tags)
+}
+
 func (d *ItemDAL) DeleteItem(id uint, userID uint) error {
 	var item types.Item
 	result := d.DB.Where("id = ? AND user_id = ?", id, userID).First(&item)
@@ -59,3 +270,266 @@ func (d *ItemDAL) DeleteItem(id uint, userID uint) error {
 
 	return d.DB.Delete(&item).Error
 }
+
+func (d *ItemDAL) GetLegacyPlaintextItems() ([]types.LegacyItem, error) {
+	var items []types.LegacyItem
+	if !d.DB.Migrator().HasColumn(&types.Item{}, "senha") {
+		return items, nil
+	}
+
+	result := d.DB.Raw("SELECT id, user_id, senha FROM items WHERE senha IS NOT NULL AND senha <> ''").Scan(&items)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return items, nil
+}
+
+func (d *ItemDAL) ReplaceLegacyPassword(id uint, senhaCifrada []byte) error {
+	return d.DB.Exec("UPDATE items SET senha_cifrada = ?, senha = NULL WHERE id = ?", senhaCifrada, id).Error
+}
+
+// NextItemID reserva o ID de um novo item na sequência da tabela.
+func (d *ItemDAL) NextItemID() (uint, error) {
+	var id uint
+	result := d.DB.Raw("SELECT nextval(pg_get_serial_sequence('items', 'id'))").Scan(&id)
+	return id, result.Error
+}
+
+// unboundField compara o primeiro byte do valor cifrado, a versão do formato.
+func unboundField(column string) string {
+	return fmt.Sprintf("substring(%s from 1 for 1) = ?", column)
+}
+
+// GetItemsWithUnboundFields devolve os itens, inclusive os da lixeira, com
+// algum campo cifrado na versão informada.
+func (d *ItemDAL) GetItemsWithUnboundFields(version byte) ([]types.Item, error) {
+	value := []byte{version}
+
+	var items []types.Item
+	result := d.DB.Unscoped().
+		Where(unboundField("senha_cifrada")+" OR "+unboundField("notas_cifradas")+" OR "+unboundField("dados_cifrados"), value, value, value).
+		Find(&items)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return items, nil
+}
+
+func (d *ItemDAL) SetItemCiphertexts(item *types.Item) error {
+	return d.DB.Unscoped().Model(&types.Item{}).Where("id = ?", item.ID).UpdateColumns(map[string]interface{}{
+		"senha_cifrada":  item.SenhaCifrada,
+		"notas_cifradas": item.NotasCifradas,
+		"dados_cifrados": item.DadosCifrados,
+	}).Error
+}
+
+func (d *ItemDAL) GetHistoryWithUnboundPassword(version byte) ([]types.ItemHistory, error) {
+	var history []types.ItemHistory
+	result := d.DB.Where(unboundField("senha_cifrada"), []byte{version}).Find(&history)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return history, nil
+}
+
+func (d *ItemDAL) SetHistoryPassword(id uint, senhaCifrada []byte) error {
+	return d.DB.Model(&types.ItemHistory{}).Where("id = ?", id).UpdateColumn("senha_cifrada", senhaCifrada).Error
+}
+
+func (d *ItemDAL) GetItemsWithoutPasswordMAC() ([]types.Item, error) {
+	var items []types.Item
+	result := d.DB.Unscoped().Where("senha_hmac IS NULL AND senha_cifrada IS NOT NULL").Find(&items)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return items, nil
+}
+
+func (d *ItemDAL) SetPasswordMAC(id uint, senhaHMAC []byte) error {
+	return d.DB.Unscoped().Model(&types.Item{}).Where("id = ?", id).UpdateColumn("senha_hmac", senhaHMAC).Error
+}
+
+// GetItemsWithoutPasswordRating devolve os itens dos tipos informados que têm
+// senha mas ainda não têm força avaliada, ou, com vazadas, que ainda não
+// foram conferidos contra o conjunto de senhas vazadas.
+func (d *ItemDAL) GetItemsWithoutPasswordRating(tipos []string, vazadas bool) ([]types.Item, error) {
+	missing := "forca_senha IS NULL"
+	if vazadas {
+		missing = "(forca_senha IS NULL OR senha_vazada IS NULL)"
+	}
+
+	var items []types.Item
+	result := d.DB.Unscoped().Where("senha_cifrada IS NOT NULL AND blob IS NULL AND tipo IN ? AND "+missing, tipos).Find(&items)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return items, nil
+}
+
+func (d *ItemDAL) SetPasswordRating(id uint, forca *int, vazada *bool) error {
+	return d.DB.Unscoped().Model(&types.Item{}).Where("id = ?", id).UpdateColumns(map[string]interface{}{
+		"forca_senha":  forca,
+		"senha_vazada": vazada,
+	}).Error
+}
+
+func (d *ItemDAL) CreateBlobItem(item *types.Item) error {
+	return d.DB.Create(item).Error
+}
+
+func (d *ItemDAL) CountItemsByUserID(userID uint) (int64, error) {
+	var count int64
+	result := d.DB.Unscoped().Model(&types.Item{}).Where("user_id = ?", userID).Count(&count)
+	return count, result.Error
+}
+
+func (d *ItemDAL) CreateHistory(history *types.ItemHistory) error {
+	return d.DB.Create(history).Error
+}
+
+func (d *ItemDAL) GetHistoryByItemID(itemID uint, userID uint) ([]types.ItemHistory, error) {
+	var history []types.ItemHistory
+	result := d.DB.Where("item_id = ? AND user_id = ?", itemID, userID).Order("versao DESC").Find(&history)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return history, nil
+}
+
+func (d *ItemDAL) GetHistoryVersion(itemID uint, userID uint, versao uint) (*types.ItemHistory, error) {
+	var history types.ItemHistory
+	result := d.DB.Where("item_id = ? AND user_id = ? AND versao = ?", itemID, userID, versao).First(&history)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &history, nil
+}
+
+func (d *ItemDAL) PruneHistory(itemID uint, limit int) error {
+	keep := d.DB.Model(&types.ItemHistory{}).Select("id").Where("item_id = ?", itemID).Order("versao DESC").Limit(limit)
+	return d.DB.Where("item_id = ? AND id NOT IN (?)", itemID, keep).Delete(&types.ItemHistory{}).Error
+}
+
+func (d *ItemDAL) GetDeletedItemsByUserID(userID uint) ([]types.Item, error) {
+	var items []types.Item
+	result := d.DB.Unscoped().Preload("Tags").Where("user_id = ? AND deleted_at IS NOT NULL", userID).Order("deleted_at DESC").Find(&items)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return items, nil
+}
+
+func (d *ItemDAL) GetDeletedItemByID(id uint, userID uint) (*types.Item, error) {
+	var item types.Item
+	result := d.DB.Unscoped().Preload("Tags").Where("id = ? AND user_id = ? AND deleted_at IS NOT NULL", id, userID).First(&item)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &item, nil
+}
+
+func (d *ItemDAL) RestoreItem(item *types.Item) error {
+	if item.Nome != "" {
+		var existingItem types.Item
+		result := d.DB.Where("nome = ? AND user_id = ?", item.Nome, item.UserID).First(&existingItem)
+		if result.Error == nil {
+			return errors.New("já existe um item com este nome")
+		} else if !errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return result.Error
+		}
+	}
+
+	result := d.DB.Unscoped().Model(&types.Item{}).
+		Where("id = ? AND user_id = ? AND deleted_at IS NOT NULL", item.ID, item.UserID).
+		Update("deleted_at", nil)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("item não encontrado na lixeira")
+	}
+
+	item.DeletedAt = gorm.DeletedAt{}
+	return nil
+}
+
+func (d *ItemDAL) PurgeItems(ids []uint) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	return d.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("item_id IN ?", ids).Delete(&types.ItemHistory{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec("DELETE FROM item_tags WHERE item_id IN ?", ids).Error; err != nil {
+			return err
+		}
+		return tx.Unscoped().Where("id IN ?", ids).Delete(&types.Item{}).Error
+	})
+}
+
+func (d *ItemDAL) GetItemIDsDeletedBefore(cutoff time.Time) ([]uint, error) {
+	var ids []uint
+	result := d.DB.Unscoped().Model(&types.Item{}).Where("deleted_at IS NOT NULL AND deleted_at < ?", cutoff).Pluck("id", &ids)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return ids, nil
+}
+
+func (d *ItemDAL) GetAllItemsByUserID(userID uint) ([]types.Item, error) {
+	var items []types.Item
+	result := d.DB.Unscoped().Preload("Tags").Where("user_id = ?", userID).Order("id").Find(&items)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return items, nil
+}
+
+func (d *ItemDAL) GetHistoryByUserID(userID uint) ([]types.ItemHistory, error) {
+	var history []types.ItemHistory
+	result := d.DB.Where("user_id = ?", userID).Order("item_id, versao").Find(&history)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return history, nil
+}
+
+func (d *ItemDAL) GetItemNamesByUserID(userID uint) ([]string, error) {
+	var names []string
+	result := d.DB.Model(&types.Item{}).Where("user_id = ?", userID).Pluck("nome", &names)
+	return names, result.Error
+}
+
+// InsertItem grava o item com o histórico informado, sem a checagem de nome
+// de CreateItem: é usado na restauração, que já valida os nomes e também
+// recria itens da lixeira.
+func (d *ItemDAL) InsertItem(item *types.Item, history []types.ItemHistory) error {
+	if err := d.DB.Create(item).Error; err != nil {
+		return err
+	}
+
+	for i := range history {
+		history[i].ItemID = item.ID
+		history[i].UserID = item.UserID
+	}
+	if len(history) == 0 {
+		return nil
+	}
+	return d.DB.Create(&history).Error
+}
+
+func (d *ItemDAL) DeleteAllItemsByUserID(userID uint) (int64, error) {
+	if err := d.DB.Where("user_id = ?", userID).Delete(&types.ItemHistory{}).Error; err != nil {
+		return 0, err
+	}
+
+	items := d.DB.Unscoped().Model(&types.Item{}).Select("id").Where("user_id = ?", userID)
+	if err := d.DB.Exec("DELETE FROM item_tags WHERE item_id IN (?)", items).Error; err != nil {
+		return 0, err
+	}
+
+	result := d.DB.Unscoped().Where("user_id = ?", userID).Delete(&types.Item{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,29 @@
+package dal
+
+import (
+	"github.com/Vicente/Password-Mobile-App/backend/app/types"
+	"gorm.io/gorm"
+)
+
+type KeyDAL struct {
+	DB *gorm.DB
+}
+
+func NewKeyDAL(db *gorm.DB) *KeyDAL {
+	return &KeyDAL{
+		DB: db,
+	}
+}
+
+func (d *KeyDAL) GetKeyByUserID(userID uint) (*types.UserKey, error) {
+	var key types.UserKey
+	result := d.DB.Where("user_id = ?", userID).First(&key)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &key, nil
+}
+
+func (d *KeyDAL) CreateKey(key *types.UserKey) error {
+	return d.DB.Create(key).Error
+}
@@ -0,0 +1,85 @@
+package dal
+
+import (
+	"time"
+
+	"github.com/Vicente/Password-Mobile-App/backend/app/types"
+	"gorm.io/gorm"
+)
+
+type MFADAL struct {
+	DB *gorm.DB
+}
+
+func NewMFADAL(db *gorm.DB) *MFADAL {
+	return &MFADAL{
+		DB: db,
+	}
+}
+
+func (d *MFADAL) GetConfigByUserID(userID uint) (*types.MFAConfig, error) {
+	var config types.MFAConfig
+	result := d.DB.Where("user_id = ?", userID).First(&config)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &config, nil
+}
+
+func (d *MFADAL) SaveConfig(config *types.MFAConfig) error {
+	return d.DB.Save(config).Error
+}
+
+func (d *MFADAL) ActivateConfig(config *types.MFAConfig, codes []types.RecoveryCode) error {
+	return d.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(config).Error; err != nil {
+			return err
+		}
+		return replaceRecoveryCodes(tx, config.UserID, codes)
+	})
+}
+
+func (d *MFADAL) ReplaceRecoveryCodes(userID uint, codes []types.RecoveryCode) error {
+	return d.DB.Transaction(func(tx *gorm.DB) error {
+		return replaceRecoveryCodes(tx, userID, codes)
+	})
+}
+
+func replaceRecoveryCodes(tx *gorm.DB, userID uint, codes []types.RecoveryCode) error {
+	if err := tx.Where("user_id = ?", userID).Delete(&types.RecoveryCode{}).Error; err != nil {
+		return err
+	}
+	if len(codes) == 0 {
+		return nil
+	}
+	return tx.Create(&codes).Error
+}
+
+func (d *MFADAL) UseRecoveryCode(userID uint, codigoHash string) (bool, error) {
+	result := d.DB.Model(&types.RecoveryCode{}).
+		Where("user_id = ? AND codigo_hash = ? AND usado_em IS NULL", userID, codigoHash).
+		Update("usado_em", time.Now())
+	return result.RowsAffected == 1, result.Error
+}
+
+func (d *MFADAL) CountUnusedRecoveryCodes(userID uint) (int64, error) {
+	var count int64
+	result := d.DB.Model(&types.RecoveryCode{}).Where("user_id = ? AND usado_em IS NULL", userID).Count(&count)
+	return count, result.Error
+}
+
+func (d *MFADAL) UpdateLastStep(userID uint, step int64) (bool, error) {
+	result := d.DB.Model(&types.MFAConfig{}).
+		Where("user_id = ? AND ultimo_passo < ?", userID, step).
+		Update("ultimo_passo", step)
+	return result.RowsAffected == 1, result.Error
+}
+
+func (d *MFADAL) DeleteConfig(userID uint) error {
+	return d.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&types.RecoveryCode{}).Error; err != nil {
+			return err
+		}
+		return tx.Unscoped().Where("user_id = ?", userID).Delete(&types.MFAConfig{}).Error
+	})
+}
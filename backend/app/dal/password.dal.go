@@ -0,0 +1,84 @@
+package dal
+
+import (
+	"errors"
+	"time"
+
+	"github.com/Vicente/Password-Mobile-App/backend/app/types"
+	"gorm.io/gorm"
+)
+
+type PasswordDAL struct {
+	DB *gorm.DB
+}
+
+func NewPasswordDAL(db *gorm.DB) *PasswordDAL {
+	return &PasswordDAL{
+		DB: db,
+	}
+}
+
+// CreateReset invalida os pedidos de redefinição anteriores do usuário, de modo
+// que apenas o link mais recente continue válido.
+func (d *PasswordDAL) CreateReset(reset *types.PasswordReset) error {
+	return d.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ? AND usado_em IS NULL", reset.UserID).Delete(&types.PasswordReset{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(reset).Error
+	})
+}
+
+func (d *PasswordDAL) GetResetByHash(hash string) (*types.PasswordReset, error) {
+	var reset types.PasswordReset
+	result := d.DB.Where("token_hash = ?", hash).First(&reset)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &reset, nil
+}
+
+func (d *PasswordDAL) UpdatePassword(userID uint, senhaHash string, keepSessionID uint) error {
+	return d.DB.Transaction(func(tx *gorm.DB) error {
+		if err := updatePassword(tx, userID, senhaHash); err != nil {
+			return err
+		}
+		return revokeSessions(tx, userID, keepSessionID)
+	})
+}
+
+func (d *PasswordDAL) ResetPassword(reset *types.PasswordReset, senhaHash string) error {
+	return d.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&types.PasswordReset{}).
+			Where("id = ? AND usado_em IS NULL", reset.ID).
+			Update("usado_em", time.Now())
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errors.New("link de redefinição já utilizado")
+		}
+
+		if err := updatePassword(tx, reset.UserID, senhaHash); err != nil {
+			return err
+		}
+		return revokeSessions(tx, reset.UserID, 0)
+	})
+}
+
+func updatePassword(tx *gorm.DB, userID uint, senhaHash string) error {
+	result := tx.Model(&types.User{}).Where("id = ?", userID).Update("senha_hash", senhaHash)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("usuário não encontrado")
+	}
+	return nil
+}
+
+func revokeSessions(tx *gorm.DB, userID uint, keepSessionID uint) error {
+	return tx.Model(&types.Session{}).
+		Where("user_id = ? AND id <> ? AND revogada_em IS NULL", userID, keepSessionID).
+		Update("revogada_em", time.Now()).Error
+}
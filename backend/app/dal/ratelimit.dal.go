@@ -0,0 +1,135 @@
+package dal
+
+import (
+	"time"
+
+	"github.com/Vicente/Password-Mobile-App/backend/app/ratelimit"
+	"github.com/Vicente/Password-Mobile-App/backend/app/types"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type RateLimitDAL struct {
+	DB *gorm.DB
+}
+
+func NewRateLimitDAL(db *gorm.DB) *RateLimitDAL {
+	return &RateLimitDAL{
+		DB: db,
+	}
+}
+
+func (d *RateLimitDAL) Add(key string, at time.Time) error {
+	return d.DB.Create(&types.RateLimitEvent{Chave: key, Em: at}).Error
+}
+
+// TryAdd serializa as requisições da mesma chave com um advisory lock da
+// transação, para que contagem e inserção não se intercalem.
+func (d *RateLimitDAL) TryAdd(key string, at time.Time, since time.Time, limit int) (ratelimit.Stats, bool, error) {
+	var stats ratelimit.Stats
+	added := false
+
+	err := d.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext(?))", key).Error; err != nil {
+			return err
+		}
+
+		var err error
+		if stats, err = eventStats(tx, key, since); err != nil {
+			return err
+		}
+		if stats.Count >= limit {
+			return nil
+		}
+
+		added = true
+		return tx.Create(&types.RateLimitEvent{Chave: key, Em: at}).Error
+	})
+	if err != nil {
+		return ratelimit.Stats{}, false, err
+	}
+	return stats, added, nil
+}
+
+// AddIf usa o mesmo advisory lock de TryAdd.
+func (d *RateLimitDAL) AddIf(key string, at time.Time, since time.Time, check func(ratelimit.Stats) error) error {
+	var checkErr error
+
+	err := d.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext(?))", key).Error; err != nil {
+			return err
+		}
+
+		stats, err := eventStats(tx, key, since)
+		if err != nil {
+			return err
+		}
+		if checkErr = check(stats); checkErr != nil {
+			return nil
+		}
+
+		return tx.Create(&types.RateLimitEvent{Chave: key, Em: at}).Error
+	})
+	if err != nil {
+		return err
+	}
+	return checkErr
+}
+
+func (d *RateLimitDAL) Stats(key string, since time.Time) (ratelimit.Stats, error) {
+	return eventStats(d.DB, key, since)
+}
+
+func eventStats(db *gorm.DB, key string, since time.Time) (ratelimit.Stats, error) {
+	var row struct {
+		Count  int
+		Oldest *time.Time
+		Newest *time.Time
+	}
+
+	result := db.Model(&types.RateLimitEvent{}).
+		Select("COUNT(*) AS count, MIN(em) AS oldest, MAX(em) AS newest").
+		Where("chave = ? AND em >= ?", key, since).
+		Scan(&row)
+	if result.Error != nil {
+		return ratelimit.Stats{}, result.Error
+	}
+
+	stats := ratelimit.Stats{Count: row.Count}
+	if row.Oldest != nil {
+		stats.Oldest = *row.Oldest
+	}
+	if row.Newest != nil {
+		stats.Newest = *row.Newest
+	}
+	return stats, nil
+}
+
+func (d *RateLimitDAL) Reset(key string) error {
+	return d.DB.Where("chave = ?", key).Delete(&types.RateLimitEvent{}).Error
+}
+
+func (d *RateLimitDAL) Lock(key string, until time.Time) error {
+	return d.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "chave"}},
+		DoUpdates: clause.AssignmentColumns([]string{"ate"}),
+	}).Create(&types.RateLimitLock{Chave: key, Ate: until}).Error
+}
+
+func (d *RateLimitDAL) LockedUntil(key string) (time.Time, error) {
+	var locks []types.RateLimitLock
+	if err := d.DB.Where("chave = ?", key).Limit(1).Find(&locks).Error; err != nil {
+		return time.Time{}, err
+	}
+	if len(locks) == 0 {
+		return time.Time{}, nil
+	}
+	return locks[0].Ate, nil
+}
+
+func (d *RateLimitDAL) Prune(before time.Time) error {
+	if err := d.DB.Where("em < ?", before).Delete(&types.RateLimitEvent{}).Error; err != nil {
+		return err
+	}
+	return d.DB.Where("ate < ?", before).Delete(&types.RateLimitLock{}).Error
+}
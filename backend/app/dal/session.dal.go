@@ -0,0 +1,122 @@
+package dal
+
+import (
+	"errors"
+	"time"
+
+	"github.com/Vicente/Password-Mobile-App/backend/app/types"
+	"gorm.io/gorm"
+)
+
+type SessionDAL struct {
+	DB *gorm.DB
+}
+
+func NewSessionDAL(db *gorm.DB) *SessionDAL {
+	return &SessionDAL{
+		DB: db,
+	}
+}
+
+func (d *SessionDAL) CreateSession(session *types.Session, token *types.RefreshToken) error {
+	return d.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(session).Error; err != nil {
+			return err
+		}
+
+		token.SessionID = session.ID
+		return tx.Create(token).Error
+	})
+}
+
+func (d *SessionDAL) GetSessionByID(id uint) (*types.Session, error) {
+	var session types.Session
+	result := d.DB.Where("id = ?", id).First(&session)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &session, nil
+}
+
+func (d *SessionDAL) GetRefreshTokenByHash(hash string) (*types.RefreshToken, error) {
+	var token types.RefreshToken
+	result := d.DB.Preload("Session").Where("token_hash = ?", hash).First(&token)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &token, nil
+}
+
+func (d *SessionDAL) RotateRefreshToken(current *types.RefreshToken, next *types.RefreshToken) error {
+	return d.DB.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		result := tx.Model(&types.RefreshToken{}).
+			Where("id = ? AND usado_em IS NULL", current.ID).
+			Update("usado_em", now)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errors.New("refresh token já utilizado")
+		}
+
+		next.SessionID = current.SessionID
+		if err := tx.Create(next).Error; err != nil {
+			return err
+		}
+
+		return tx.Model(&types.Session{}).
+			Where("id = ?", current.SessionID).
+			Updates(map[string]interface{}{"expira_em": next.ExpiraEm, "ultimo_acesso": now}).Error
+	})
+}
+
+func (d *SessionDAL) RevokeSession(id uint) error {
+	return d.DB.Model(&types.Session{}).
+		Where("id = ? AND revogada_em IS NULL", id).
+		Update("revogada_em", time.Now()).Error
+}
+
+func (d *SessionDAL) GetActiveSessionsByUserID(userID uint) ([]types.Session, error) {
+	var sessions []types.Session
+	result := d.DB.Where("user_id = ? AND revogada_em IS NULL AND expira_em > ?", userID, time.Now()).
+		Order("ultimo_acesso DESC").
+		Find(&sessions)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return sessions, nil
+}
+
+func (d *SessionDAL) RevokeUserSession(id uint, userID uint) error {
+	result := d.DB.Model(&types.Session{}).
+		Where("id = ? AND user_id = ? AND revogada_em IS NULL", id, userID).
+		Update("revogada_em", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("sessão não encontrada")
+	}
+	return nil
+}
+
+func (d *SessionDAL) RevokeOtherSessions(userID uint, currentID uint) (int64, error) {
+	result := d.DB.Model(&types.Session{}).
+		Where("user_id = ? AND id <> ? AND revogada_em IS NULL", userID, currentID).
+		Update("revogada_em", time.Now())
+	return result.RowsAffected, result.Error
+}
+
+func (d *SessionDAL) TouchSession(id uint, at time.Time) error {
+	return d.DB.Model(&types.Session{}).Where("id = ?", id).Update("ultimo_acesso", at).Error
+}
+
+func (d *SessionDAL) GetSessionsByUserID(userID uint) ([]types.Session, error) {
+	var sessions []types.Session
+	result := d.DB.Where("user_id = ?", userID).Order("created_at").Find(&sessions)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return sessions, nil
+}
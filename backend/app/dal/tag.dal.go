@@ -0,0 +1,124 @@
+package dal
+
+import (
+	"errors"
+
+	"github.com/Vicente/Password-Mobile-App/backend/app/types"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type TagDAL struct {
+	DB *gorm.DB
+}
+
+func NewTagDAL(db *gorm.DB) *TagDAL {
+	return &TagDAL{
+		DB: db,
+	}
+}
+
+func (d *TagDAL) GetTagsByUserID(userID uint) ([]types.Tag, error) {
+	var tags []types.Tag
+	result := d.DB.Where("user_id = ?", userID).Order("nome").Find(&tags)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return tags, nil
+}
+
+func (d *TagDAL) GetTagByID(id uint, userID uint) (*types.Tag, error) {
+	var tag types.Tag
+	result := d.DB.Where("id = ? AND user_id = ?", id, userID).First(&tag)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &tag, nil
+}
+
+func (d *TagDAL) checkName(tag *types.Tag) error {
+	var existing types.Tag
+	result := d.DB.Where("user_id = ? AND nome = ? AND id <> ?", tag.UserID, tag.Nome, tag.ID).First(&existing)
+	if result.Error == nil {
+		return errors.New("já existe uma tag com este nome")
+	} else if !errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return result.Error
+	}
+	return nil
+}
+
+func (d *TagDAL) CreateTag(tag *types.Tag) error {
+	if err := d.checkName(tag); err != nil {
+		return err
+	}
+	return d.DB.Create(tag).Error
+}
+
+func (d *TagDAL) UpdateTag(tag *types.Tag) error {
+	if err := d.checkName(tag); err != nil {
+		return err
+	}
+	return d.DB.Model(tag).Update("nome", tag.Nome).Error
+}
+
+func (d *TagDAL) DeleteTag(tag *types.Tag) error {
+	return d.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("DELETE FROM item_tags WHERE tag_id = ?", tag.ID).Error; err != nil {
+			return err
+		}
+		return tx.Delete(tag).Error
+	})
+}
+
+// FindOrCreateTags devolve as tags com os nomes informados, criando as que
+// ainda não existem.
+func (d *TagDAL) FindOrCreateTags(userID uint, names []string) ([]types.Tag, error) {
+	if len(names) == 0 {
+		return []types.Tag{}, nil
+	}
+
+	missing := make([]types.Tag, 0, len(names))
+	for _, name := range names {
+		missing = append(missing, types.Tag{Nome: name, UserID: userID})
+	}
+	if err := d.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&missing).Error; err != nil {
+		return nil, err
+	}
+
+	var tags []types.Tag
+	result := d.DB.Where("user_id = ? AND nome IN ?", userID, names).Order("nome").Find(&tags)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return tags, nil
+}
+
+func (d *TagDAL) CountItemsByTag(userID uint) (map[uint]int64, error) {
+	var rows []struct {
+		TagID uint
+		Total int64
+	}
+	result := d.DB.Table("item_tags").
+		Select("item_tags.tag_id, COUNT(*) AS total").
+		Joins("JOIN items ON items.id = item_tags.item_id").
+		Where("items.user_id = ? AND items.deleted_at IS NULL", userID).
+		Group("item_tags.tag_id").
+		Scan(&rows)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	counts := make(map[uint]int64, len(rows))
+	for _, row := range rows {
+		counts[row.TagID] = row.Total
+	}
+	return counts, nil
+}
+
+func (d *TagDAL) DeleteAllTagsByUserID(userID uint) error {
+	tags := d.DB.Model(&types.Tag{}).Select("id").Where("user_id = ?", userID)
+	if err := d.DB.Exec("DELETE FROM item_tags WHERE tag_id IN (?)", tags).Error; err != nil {
+		return err
+	}
+	return d.DB.Where("user_id = ?", userID).Delete(&types.Tag{}).Error
+}
@@ -0,0 +1,29 @@
+package dal
+
+import (
+	"github.com/Vicente/Password-Mobile-App/backend/app/types"
+	"gorm.io/gorm"
+)
+
+type VaultDAL struct {
+	DB *gorm.DB
+}
+
+func NewVaultDAL(db *gorm.DB) *VaultDAL {
+	return &VaultDAL{
+		DB: db,
+	}
+}
+
+func (d *VaultDAL) GetConfigByUserID(userID uint) (*types.VaultConfig, error) {
+	var config types.VaultConfig
+	result := d.DB.Where("user_id = ?", userID).First(&config)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &config, nil
+}
+
+func (d *VaultDAL) SaveConfig(config *types.VaultConfig) error {
+	return d.DB.Save(config).Error
+}
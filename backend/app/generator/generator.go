@@ -0,0 +1,340 @@
+// Package generator gera senhas aleatórias, senhas pronunciáveis e frases-senha
+// diceware usando apenas crypto/rand, informando a entropia de cada resultado.
+package generator
+
+import (
+	"crypto/rand"
+	"errors"
+	"math"
+	"math/big"
+	"strings"
+)
+
+type Mode string
+
+const (
+	Random        Mode = "aleatoria"
+	Pronounceable Mode = "pronunciavel"
+	Diceware      Mode = "diceware"
+)
+
+const (
+	MinLength = 4
+	MaxLength = 128
+
+	MinWords = 3
+	MaxWords = 20
+
+	DefaultLength              = 20
+	DefaultPronounceableLength = 12
+	DefaultWords               = 6
+	DefaultSeparator           = "-"
+)
+
+const (
+	lowercase = "abcdefghijklmnopqrstuvwxyz"
+	uppercase = "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	digits    = "0123456789"
+	symbols   = "!\"#$%&'()*+,-./:;<=>?@[\\]^_`{|}~"
+
+	// Caracteres fáceis de confundir entre si em muitas fontes.
+	ambiguous = "0Oo1lI|`'\""
+
+	vowels     = "aeiou"
+	consonants = "bcdfghjklmnpqrstvwxz"
+)
+
+// Options descreve a senha desejada. As classes de caracteres valem para o
+// modo aleatório; no pronunciável só Minusculas/Maiusculas (primeira letra) e
+// MinNumeros (dígitos ao final) são considerados.
+type Options struct {
+	Modo            Mode
+	Tamanho         int
+	Minusculas      bool
+	Maiusculas      bool
+	Numeros         bool
+	Simbolos        bool
+	ExcluirAmbiguos bool
+	MinMinusculas   int
+	MinMaiusculas   int
+	MinNumeros      int
+	MinSimbolos     int
+	Palavras        int
+	Separador       string
+	Capitalizar     bool
+	IncluirNumero   bool
+}
+
+type Result struct {
+	Senha    string
+	Entropia float64
+}
+
+func Generate(opts Options) (*Result, error) {
+	switch opts.Modo {
+	case Random, "":
+		return generateRandom(opts)
+	case Pronounceable:
+		return generatePronounceable(opts)
+	case Diceware:
+		return generatePassphrase(opts)
+	}
+	return nil, errors.New("modo inválido, use aleatoria, pronunciavel ou diceware")
+}
+
+type charClass struct {
+	chars   []rune
+	minimum int
+}
+
+func generateRandom(opts Options) (*Result, error) {
+	if opts.Tamanho < MinLength || opts.Tamanho > MaxLength {
+		return nil, errors.New("o tamanho deve estar entre 4 e 128")
+	}
+
+	var classes []charClass
+	total := 0
+	for _, c := range []struct {
+		enabled bool
+		chars   string
+		minimum int
+	}{
+		{opts.Minusculas, lowercase, opts.MinMinusculas},
+		{opts.Maiusculas, uppercase, opts.MinMaiusculas},
+		{opts.Numeros, digits, opts.MinNumeros},
+		{opts.Simbolos, symbols, opts.MinSimbolos},
+	} {
+		if c.minimum < 0 {
+			return nil, errors.New("os mínimos por classe não podem ser negativos")
+		}
+		if !c.enabled {
+			if c.minimum > 0 {
+				return nil, errors.New("há um mínimo para uma classe de caracteres desativada")
+			}
+			continue
+		}
+		classes = append(classes, charClass{chars: filter(c.chars, opts.ExcluirAmbiguos), minimum: c.minimum})
+		total += c.minimum
+	}
+
+	if len(classes) == 0 {
+		return nil, errors.New("ative pelo menos uma classe de caracteres")
+	}
+	if total > opts.Tamanho {
+		return nil, errors.New("a soma dos mínimos por classe excede o tamanho")
+	}
+
+	// ways[i][t] conta as sequências de tamanho t formadas pelas classes i em
+	// diante respeitando os mínimos. Sortear a quantidade de cada classe com
+	// esses pesos torna o resultado uniforme entre todas as senhas válidas, e
+	// a entropia é exatamente log2(ways[0][tamanho]).
+	n := opts.Tamanho
+	ways := make([][]float64, len(classes)+1)
+	ways[len(classes)] = make([]float64, n+1)
+	ways[len(classes)][0] = 1
+	for i := len(classes) - 1; i >= 0; i-- {
+		ways[i] = make([]float64, n+1)
+		size := float64(len(classes[i].chars))
+		for t := 0; t <= n; t++ {
+			for k := classes[i].minimum; k <= t; k++ {
+				ways[i][t] += binomial(t, k) * math.Pow(size, float64(k)) * ways[i+1][t-k]
+			}
+		}
+	}
+
+	labels := make([]int, 0, n)
+	remaining := n
+	for i, class := range classes {
+		size := float64(len(class.chars))
+		target, err := randomFloat()
+		if err != nil {
+			return nil, err
+		}
+		target *= ways[i][remaining]
+
+		k := class.minimum
+		for ; k < remaining; k++ {
+			weight := binomial(remaining, k) * math.Pow(size, float64(k)) * ways[i+1][remaining-k]
+			if target < weight {
+				break
+			}
+			target -= weight
+		}
+		if i == len(classes)-1 {
+			k = remaining
+		}
+		// Protege contra arredondamento: as classes seguintes ainda precisam
+		// caber no que sobrou.
+		for k > class.minimum && ways[i+1][remaining-k] == 0 {
+			k--
+		}
+
+		for j := 0; j < k; j++ {
+			labels = append(labels, i)
+		}
+		remaining -= k
+	}
+
+	if err := shuffle(labels); err != nil {
+		return nil, err
+	}
+
+	password := make([]rune, n)
+	for i, label := range labels {
+		c, err := pick(classes[label].chars)
+		if err != nil {
+			return nil, err
+		}
+		password[i] = c
+	}
+
+	return &Result{Senha: string(password), Entropia: math.Log2(ways[0][n])}, nil
+}
+
+// generatePronounceable alterna consoantes e vogais, começando por qualquer
+// uma das duas, e pode terminar com dígitos.
+func generatePronounceable(opts Options) (*Result, error) {
+	if opts.Tamanho < MinLength || opts.Tamanho > MaxLength {
+		return nil, errors.New("o tamanho deve estar entre 4 e 128")
+	}
+	if opts.MinNumeros < 0 || opts.MinNumeros >= opts.Tamanho {
+		return nil, errors.New("a quantidade de dígitos deve ser menor que o tamanho")
+	}
+	if !opts.Minusculas && !opts.Maiusculas {
+		return nil, errors.New("ative letras minúsculas ou maiúsculas")
+	}
+
+	// Os ambíguos são filtrados depois da troca de caixa: "i" é aceitável,
+	// mas "I" não.
+	lower := [][]rune{filter(consonants, opts.ExcluirAmbiguos), filter(vowels, opts.ExcluirAmbiguos)}
+	upper := [][]rune{filter(strings.ToUpper(consonants), opts.ExcluirAmbiguos), filter(strings.ToUpper(vowels), opts.ExcluirAmbiguos)}
+	first, err := randomInt(2)
+	if err != nil {
+		return nil, err
+	}
+
+	letters := opts.Tamanho - opts.MinNumeros
+	entropy := 1.0
+	var b strings.Builder
+	for i := 0; i < letters; i++ {
+		groups := lower
+		if !opts.Minusculas || (opts.Maiusculas && i == 0) {
+			groups = upper
+		}
+		group := groups[(first+i)%2]
+		c, err := pick(group)
+		if err != nil {
+			return nil, err
+		}
+		entropy += math.Log2(float64(len(group)))
+		b.WriteRune(c)
+	}
+
+	digitSet := filter(digits, opts.ExcluirAmbiguos)
+	for i := 0; i < opts.MinNumeros; i++ {
+		c, err := pick(digitSet)
+		if err != nil {
+			return nil, err
+		}
+		entropy += math.Log2(float64(len(digitSet)))
+		b.WriteRune(c)
+	}
+
+	return &Result{Senha: b.String(), Entropia: entropy}, nil
+}
+
+func generatePassphrase(opts Options) (*Result, error) {
+	if opts.Palavras < MinWords || opts.Palavras > MaxWords {
+		return nil, errors.New("a quantidade de palavras deve estar entre 3 e 20")
+	}
+
+	list := wordList()
+	words := make([]string, opts.Palavras)
+	for i := range words {
+		index, err := randomInt(len(list))
+		if err != nil {
+			return nil, err
+		}
+		words[i] = list[index]
+		if opts.Capitalizar {
+			words[i] = strings.ToUpper(words[i][:1]) + words[i][1:]
+		}
+	}
+	entropy := float64(opts.Palavras) * math.Log2(float64(len(list)))
+
+	if opts.IncluirNumero {
+		word, err := randomInt(len(words))
+		if err != nil {
+			return nil, err
+		}
+		digit, err := randomInt(10)
+		if err != nil {
+			return nil, err
+		}
+		words[word] += digits[digit : digit+1]
+		entropy += math.Log2(float64(len(words))) + math.Log2(10)
+	}
+
+	return &Result{Senha: strings.Join(words, opts.Separador), Entropia: entropy}, nil
+}
+
+func filter(chars string, excludeAmbiguous bool) []rune {
+	if !excludeAmbiguous {
+		return []rune(chars)
+	}
+
+	var out []rune
+	for _, c := range chars {
+		if !strings.ContainsRune(ambiguous, c) {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+func binomial(n, k int) float64 {
+	if k < 0 || k > n {
+		return 0
+	}
+	result := 1.0
+	for i := 1; i <= k; i++ {
+		result = result * float64(n-k+i) / float64(i)
+	}
+	return result
+}
+
+func randomInt(max int) (int, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(int64(max)))
+	if err != nil {
+		return 0, err
+	}
+	return int(n.Int64()), nil
+}
+
+// randomFloat devolve um número uniforme em [0, 1) com 53 bits aleatórios.
+func randomFloat() (float64, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(1<<53))
+	if err != nil {
+		return 0, err
+	}
+	return float64(n.Int64()) / (1 << 53), nil
+}
+
+func pick(chars []rune) (rune, error) {
+	i, err := randomInt(len(chars))
+	if err != nil {
+		return 0, err
+	}
+	return chars[i], nil
+}
+
+func shuffle(values []int) error {
+	for i := len(values) - 1; i > 0; i-- {
+		j, err := randomInt(i + 1)
+		if err != nil {
+			return err
+		}
+		values[i], values[j] = values[j], values[i]
+	}
+	return nil
+}
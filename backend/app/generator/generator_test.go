@@ -0,0 +1,137 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+// Cada caso é gerado várias vezes para que um caractere proibido apareça
+// com alta probabilidade se o filtro falhar.
+const samples = 200
+
+func count(password, chars string) int {
+	n := 0
+	for _, c := range password {
+		if strings.ContainsRune(chars, c) {
+			n++
+		}
+	}
+	return n
+}
+
+func TestExcludeAmbiguous(t *testing.T) {
+	tests := []struct {
+		name string
+		opts Options
+	}{
+		{"aleatória", Options{Modo: Random, Tamanho: 64, Minusculas: true, Maiusculas: true, Numeros: true, Simbolos: true, ExcluirAmbiguos: true}},
+		{"pronunciável", Options{Modo: Pronounceable, Tamanho: 32, Minusculas: true, Maiusculas: true, MinNumeros: 4, ExcluirAmbiguos: true}},
+		{"pronunciável maiúscula", Options{Modo: Pronounceable, Tamanho: 32, Maiusculas: true, MinNumeros: 4, ExcluirAmbiguos: true}},
+	}
+
+	for _, tt := range tests {
+		for i := 0; i < samples; i++ {
+			result, err := Generate(tt.opts)
+			if err != nil {
+				t.Fatalf("%s: %v", tt.name, err)
+			}
+			if strings.ContainsAny(result.Senha, ambiguous) {
+				t.Fatalf("%s: %q tem caractere ambíguo", tt.name, result.Senha)
+			}
+		}
+	}
+}
+
+func TestLength(t *testing.T) {
+	for _, tamanho := range []int{MinLength, DefaultPronounceableLength, DefaultLength, MaxLength} {
+		for _, opts := range []Options{
+			{Modo: Random, Tamanho: tamanho, Minusculas: true, Simbolos: true},
+			{Modo: Pronounceable, Tamanho: tamanho, Minusculas: true, MinNumeros: 2},
+		} {
+			result, err := Generate(opts)
+			if err != nil {
+				t.Fatalf("%s %d: %v", opts.Modo, tamanho, err)
+			}
+			if n := utf8.RuneCountInString(result.Senha); n != tamanho {
+				t.Errorf("%s: %q tem %d caracteres, esperado %d", opts.Modo, result.Senha, n, tamanho)
+			}
+		}
+	}
+
+	for _, opts := range []Options{
+		{Modo: Random, Tamanho: MinLength - 1, Minusculas: true},
+		{Modo: Random, Tamanho: MaxLength + 1, Minusculas: true},
+		{Modo: Pronounceable, Tamanho: MaxLength + 1, Minusculas: true},
+		{Modo: Random, Tamanho: 6, Minusculas: true, Numeros: true, MinMinusculas: 4, MinNumeros: 3},
+	} {
+		if _, err := Generate(opts); err == nil {
+			t.Errorf("%+v aceito", opts)
+		}
+	}
+
+	result, err := Generate(Options{Modo: Diceware, Palavras: DefaultWords, Separador: DefaultSeparator})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := len(strings.Split(result.Senha, DefaultSeparator)); n != DefaultWords {
+		t.Errorf("%q tem %d palavras, esperado %d", result.Senha, n, DefaultWords)
+	}
+}
+
+func TestRequiredClasses(t *testing.T) {
+	opts := Options{
+		Modo:          Random,
+		Tamanho:       12,
+		Minusculas:    true,
+		Maiusculas:    true,
+		Numeros:       true,
+		Simbolos:      true,
+		MinMinusculas: 2,
+		MinMaiusculas: 3,
+		MinNumeros:    3,
+		MinSimbolos:   4,
+	}
+
+	for i := 0; i < samples; i++ {
+		result, err := Generate(opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, c := range []struct {
+			chars   string
+			minimum int
+		}{
+			{lowercase, opts.MinMinusculas},
+			{uppercase, opts.MinMaiusculas},
+			{digits, opts.MinNumeros},
+			{symbols, opts.MinSimbolos},
+		} {
+			if n := count(result.Senha, c.chars); n < c.minimum {
+				t.Fatalf("%q tem %d de %q, mínimo %d", result.Senha, n, c.chars, c.minimum)
+			}
+		}
+	}
+
+	// Classes desativadas não aparecem.
+	result, err := Generate(Options{Modo: Random, Tamanho: 64, Numeros: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count(result.Senha, digits) != 64 {
+		t.Errorf("%q tem caracteres fora dos dígitos", result.Senha)
+	}
+
+	for i := 0; i < samples; i++ {
+		result, err := Generate(Options{Modo: Pronounceable, Tamanho: 10, Minusculas: true, Maiusculas: true, MinNumeros: 3})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.ContainsAny(result.Senha[:1], uppercase) {
+			t.Fatalf("%q não começa com maiúscula", result.Senha)
+		}
+		if count(result.Senha[7:], digits) != 3 {
+			t.Fatalf("%q não termina com 3 dígitos", result.Senha)
+		}
+	}
+}
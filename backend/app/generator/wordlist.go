@@ -0,0 +1,24 @@
+package generator
+
+import (
+	_ "embed"
+	"strings"
+	"sync"
+)
+
+// Lista longa da EFF (7776 palavras, uma por combinação de cinco dados):
+// https://www.eff.org/deeplinks/2016/07/new-wordlists-random-passphrases
+//
+//go:embed eff_large_wordlist.txt
+var effLargeWordlist string
+
+var wordList = sync.OnceValue(func() []string {
+	lines := strings.Split(strings.TrimSpace(effLargeWordlist), "\n")
+	words := make([]string, 0, len(lines))
+	for _, line := range lines {
+		if _, word, ok := strings.Cut(line, "\t"); ok {
+			words = append(words, word)
+		}
+	}
+	return words
+})
@@ -0,0 +1,269 @@
+// Package importer lê exportações CSV de outros gerenciadores de senha e
+// bancos do KeePass e os converte em registros no formato dos itens do cofre.
+package importer
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+)
+
+type Format string
+
+const (
+	Bitwarden   Format = "bitwarden"
+	LastPass    Format = "lastpass"
+	Chrome      Format = "chrome"
+	Firefox     Format = "firefox"
+	OnePassword Format = "1password"
+)
+
+// Record é um item lido do arquivo. Pasta é o caminho da pasta a partir da
+// raiz, separado por "/", e fica vazio fora de pastas. TOTP é o segredo do
+// autenticador, em base32 ou como URI. Nota marca as notas seguras, que só têm
+// nome e texto. Aviso descreve um dado descartado sem impedir a importação.
+type Record struct {
+	Linha   int
+	Nome    string
+	Usuario string
+	Senha   string
+	URL     string
+	TOTP    string
+	Notas   string
+	Pasta   string
+	Tags    []string
+	Campos  []Field
+	Nota    bool
+	Aviso   string
+}
+
+// Field é um campo personalizado do item, na ordem do arquivo. Oculto marca
+// os campos que o gerenciador de origem guardava protegidos.
+type Field struct {
+	Nome   string
+	Valor  string
+	Oculto bool
+}
+
+type RowError struct {
+	Linha  int
+	Motivo string
+}
+
+type columns struct {
+	nome, usuario, senha, url, notas, tipo, pasta, campos, totp string
+}
+
+// As colunas são comparadas em minúsculas. A ordem importa: formatos mais
+// específicos vêm antes, pois o Chrome/Edge usa um subconjunto das colunas
+// dos demais.
+var formats = []struct {
+	format   Format
+	required []string
+	columns  columns
+}{
+	{Bitwarden, []string{"login_password", "login_username", "name"}, columns{"name", "login_username", "login_password", "login_uri", "notes", "type", "folder", "fields", "login_totp"}},
+	{Firefox, []string{"url", "username", "password", "httprealm", "guid"}, columns{"", "username", "password", "url", "", "", "", "", ""}},
+	{LastPass, []string{"url", "username", "password", "extra", "name", "grouping"}, columns{"name", "username", "password", "url", "extra", "", "grouping", "", "totp"}},
+	{OnePassword, []string{"title", "username", "password"}, columns{"title", "username", "password", "url|website|urls", "notes|notesplain", "", "", "", "otpauth|one-time password"}},
+	{Chrome, []string{"name", "url", "username", "password"}, columns{"name", "username", "password", "url", "note|notes", "", "", "", ""}},
+}
+
+// Parse detecta o formato pelo cabeçalho e devolve os registros válidos e os
+// erros por linha. Linhas são numeradas como no arquivo (o cabeçalho é a 1).
+func Parse(r io.Reader) (Format, []Record, []RowError, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return "", nil, nil, err
+	}
+	data = bytes.TrimPrefix(data, []byte("\ufeff"))
+
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return "", nil, nil, errors.New("arquivo CSV vazio")
+	} else if err != nil {
+		return "", nil, nil, fmt.Errorf("CSV inválido: %w", err)
+	}
+
+	index := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if _, ok := index[name]; !ok {
+			index[name] = i
+		}
+	}
+
+	format, cols, ok := detect(index)
+	if !ok {
+		return "", nil, nil, errors.New("formato de CSV não reconhecido, use uma exportação do Bitwarden, LastPass, Chrome/Edge, Firefox ou 1Password")
+	}
+
+	var records []Record
+	var rowErrors []RowError
+	for {
+		row, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		line, _ := reader.FieldPos(0)
+		if err != nil {
+			rowErrors = append(rowErrors, RowError{Linha: line, Motivo: "linha CSV inválida"})
+			continue
+		}
+
+		if isBlank(row) {
+			continue
+		}
+
+		record, err := toRecord(format, cols, index, row)
+		if err != nil {
+			rowErrors = append(rowErrors, RowError{Linha: line, Motivo: err.Error()})
+			continue
+		}
+		record.Linha = line
+		records = append(records, record)
+	}
+
+	return format, records, rowErrors, nil
+}
+
+func detect(index map[string]int) (Format, columns, bool) {
+	for _, candidate := range formats {
+		matched := true
+		for _, name := range candidate.required {
+			if _, ok := index[name]; !ok {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return candidate.format, candidate.columns, true
+		}
+	}
+	return "", columns{}, false
+}
+
+func toRecord(format Format, cols columns, index map[string]int, row []string) (Record, error) {
+	// field devolve o valor como exportado: espaços podem fazer parte de
+	// senhas, segredos e notas. Só nome, URL, pasta e tipo passam por trimmed.
+	field := func(names string) string {
+		if names == "" {
+			return ""
+		}
+		for _, name := range strings.Split(names, "|") {
+			if i, ok := index[name]; ok && i < len(row) && strings.TrimSpace(row[i]) != "" {
+				return row[i]
+			}
+		}
+		return ""
+	}
+	trimmed := func(names string) string {
+		return strings.TrimSpace(field(names))
+	}
+
+	tipo := trimmed(cols.tipo)
+	if tipo != "" && tipo != "login" && tipo != "note" {
+		return Record{}, fmt.Errorf("tipo %q não suportado, apenas logins e notas seguras são importados", tipo)
+	}
+
+	record := Record{
+		Nome:    trimmed(cols.nome),
+		Usuario: field(cols.usuario),
+		Senha:   field(cols.senha),
+		URL:     trimmed(cols.url),
+		TOTP:    field(cols.totp),
+		Notas:   field(cols.notas),
+		Pasta:   trimmed(cols.pasta),
+		Campos:  parseFields(field(cols.campos)),
+		Nota:    tipo == "note",
+	}
+
+	// O LastPass exporta notas seguras com a URL "http://sn".
+	if format == LastPass && record.URL == "http://sn" {
+		record.Nota = true
+	}
+
+	// O LastPass separa os níveis da pasta com "\".
+	if format == LastPass {
+		record.Pasta = strings.ReplaceAll(record.Pasta, `\`, "/")
+	}
+
+	if format == OnePassword && strings.Contains(record.URL, ",") {
+		record.URL = strings.TrimSpace(strings.Split(record.URL, ",")[0])
+	}
+
+	return record, validate(&record)
+}
+
+// validate completa o nome a partir da URL quando ausente e confere os campos
+// obrigatórios de um item. Das notas seguras só ficam o nome e o texto.
+func validate(record *Record) error {
+	if record.Nota {
+		record.Usuario, record.Senha, record.URL, record.TOTP = "", "", "", ""
+		if record.Nome == "" {
+			return errors.New("nome é obrigatório")
+		}
+		if record.Notas == "" {
+			return errors.New("o conteúdo da nota é obrigatório")
+		}
+		return nil
+	}
+
+	if record.Nome == "" {
+		record.Nome = hostname(record.URL)
+	}
+	if record.Nome == "" {
+		return errors.New("nome é obrigatório")
+	}
+
+	if record.Senha == "" {
+		return errors.New("senha é obrigatória")
+	}
+
+	return nil
+}
+
+// parseFields lê a coluna de campos personalizados do Bitwarden, com um campo
+// por linha no formato "nome: valor".
+func parseFields(raw string) []Field {
+	var fields []Field
+	for _, line := range strings.Split(raw, "\n") {
+		line = strings.TrimRight(line, "\r")
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		nome, valor, _ := strings.Cut(line, ": ")
+		fields = append(fields, Field{Nome: strings.TrimSpace(nome), Valor: valor})
+	}
+	return fields
+}
+
+func hostname(raw string) string {
+	if raw == "" {
+		return ""
+	}
+
+	parsed, err := url.Parse(raw)
+	if err != nil || parsed.Hostname() == "" {
+		return ""
+	}
+	return strings.TrimPrefix(parsed.Hostname(), "www.")
+}
+
+func isBlank(row []string) bool {
+	for _, value := range row {
+		if strings.TrimSpace(value) != "" {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,148 @@
+package importer
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseDetectsFormat(t *testing.T) {
+	tests := []struct {
+		name   string
+		csv    string
+		format Format
+		record Record
+	}{
+		{
+			"bitwarden",
+			"folder,favorite,type,name,notes,fields,reprompt,login_uri,login_username,login_password,login_totp\n" +
+				"Trabalho,,login,GitHub,nota,\"PIN: 1234\",0,https://github.com,joao,s3nh@,JBSWY3DPEHPK3PXP\n",
+			Bitwarden,
+			Record{Linha: 2, Nome: "GitHub", Usuario: "joao", Senha: "s3nh@", URL: "https://github.com", TOTP: "JBSWY3DPEHPK3PXP", Notas: "nota", Pasta: "Trabalho", Campos: []Field{{Nome: "PIN", Valor: "1234"}}},
+		},
+		{
+			"lastpass",
+			"url,username,password,totp,extra,name,grouping,fav\n" +
+				"https://github.com,joao,s3nh@,,nota,GitHub,Trabalho,0\n",
+			LastPass,
+			Record{Linha: 2, Nome: "GitHub", Usuario: "joao", Senha: "s3nh@", URL: "https://github.com", Notas: "nota", Pasta: "Trabalho"},
+		},
+		{
+			"chrome",
+			"name,url,username,password,note\n" +
+				"github.com,https://github.com/login,joao,s3nh@,nota\n",
+			Chrome,
+			Record{Linha: 2, Nome: "github.com", Usuario: "joao", Senha: "s3nh@", URL: "https://github.com/login", Notas: "nota"},
+		},
+		{
+			"firefox",
+			"\ufeff\"url\",\"username\",\"password\",\"httpRealm\",\"formActionOrigin\",\"guid\",\"timeCreated\",\"timeLastUsed\",\"timePasswordChanged\"\n" +
+				"\"https://www.github.com\",\"joao\",\"s3nh@\",,\"https://github.com\",\"{1}\",\"1\",\"1\",\"1\"\n",
+			Firefox,
+			Record{Linha: 2, Nome: "github.com", Usuario: "joao", Senha: "s3nh@", URL: "https://www.github.com"},
+		},
+		{
+			"1password",
+			"Title,Url,Username,Password,OTPAuth,Favorite,Archived,Tags,Notes\n" +
+				"GitHub,https://github.com,joao,s3nh@,otpauth://totp/GitHub?secret=JBSWY3DPEHPK3PXP,false,false,,nota\n",
+			OnePassword,
+			Record{Linha: 2, Nome: "GitHub", Usuario: "joao", Senha: "s3nh@", URL: "https://github.com", TOTP: "otpauth://totp/GitHub?secret=JBSWY3DPEHPK3PXP", Notas: "nota"},
+		},
+	}
+
+	for _, tt := range tests {
+		format, records, rowErrors, err := Parse(strings.NewReader(tt.csv))
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if format != tt.format {
+			t.Errorf("%s: formato %s, esperado %s", tt.name, format, tt.format)
+		}
+		if len(rowErrors) > 0 || len(records) != 1 {
+			t.Fatalf("%s: registros %+v, erros %+v", tt.name, records, rowErrors)
+		}
+		if !reflect.DeepEqual(records[0], tt.record) {
+			t.Errorf("%s: registro %+v, esperado %+v", tt.name, records[0], tt.record)
+		}
+	}
+
+	if _, _, _, err := Parse(strings.NewReader("titulo,login,segredo\na,b,c\n")); err == nil {
+		t.Error("cabeçalho desconhecido aceito")
+	}
+}
+
+// Espaços nas bordas fazem parte de senhas, segredos, notas e campos; só
+// nome, URL e pasta são aparados.
+func TestParseKeepsSecretsAsExported(t *testing.T) {
+	csv := "folder,favorite,type,name,notes,fields,reprompt,login_uri,login_username,login_password,login_totp\n" +
+		"\" Trabalho \",,login,\" GitHub \",\"  nota\n\",\"PIN:  1234 \",0,\" https://github.com \",joao,\" s3nh@ \",\" JBSWY3DPEHPK3PXP\"\n"
+
+	_, records, rowErrors, err := Parse(strings.NewReader(csv))
+	if err != nil || len(rowErrors) > 0 || len(records) != 1 {
+		t.Fatalf("registros %+v, erros %+v, %v", records, rowErrors, err)
+	}
+
+	want := Record{
+		Linha:   2,
+		Nome:    "GitHub",
+		Usuario: "joao",
+		Senha:   " s3nh@ ",
+		URL:     "https://github.com",
+		TOTP:    " JBSWY3DPEHPK3PXP",
+		Notas:   "  nota\n",
+		Pasta:   "Trabalho",
+		Campos:  []Field{{Nome: "PIN", Valor: " 1234 "}},
+	}
+	if !reflect.DeepEqual(records[0], want) {
+		t.Errorf("registro %+v, esperado %+v", records[0], want)
+	}
+}
+
+func TestParseLastPassNotesAndFolders(t *testing.T) {
+	csv := "url,username,password,totp,extra,name,grouping,fav\n" +
+		"http://sn,,,,\"NoteType:Server\nHostname:db\",Servidor,Trabalho\\Infra\\Banco,0\n" +
+		"https://github.com,joao,s3nh@,,,GitHub,Pessoal,0\n" +
+		"http://sn,,,,,Nota vazia,,0\n"
+
+	format, records, rowErrors, err := Parse(strings.NewReader(csv))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if format != LastPass {
+		t.Fatalf("formato %s", format)
+	}
+
+	want := []Record{
+		{Linha: 2, Nome: "Servidor", Notas: "NoteType:Server\nHostname:db", Pasta: "Trabalho/Infra/Banco", Nota: true},
+		{Linha: 4, Nome: "GitHub", Usuario: "joao", Senha: "s3nh@", URL: "https://github.com", Pasta: "Pessoal"},
+	}
+	if !reflect.DeepEqual(records, want) {
+		t.Errorf("registros %+v, esperado %+v", records, want)
+	}
+
+	// A nota segura sem conteúdo é recusada na sua linha.
+	if len(rowErrors) != 1 || rowErrors[0].Linha != 5 {
+		t.Errorf("erros %+v, esperado um na linha 5", rowErrors)
+	}
+}
+
+func TestParseOnePasswordURLs(t *testing.T) {
+	tests := []struct {
+		header, url, want string
+	}{
+		{"Url", "https://github.com, https://gist.github.com", "https://github.com"},
+		{"URLs", " https://github.com,https://gist.github.com ", "https://github.com"},
+		{"Website", "https://github.com", "https://github.com"},
+	}
+
+	for _, tt := range tests {
+		csv := "Title," + tt.header + ",Username,Password\nGitHub,\"" + tt.url + "\",joao,s3nh@\n"
+		format, records, rowErrors, err := Parse(strings.NewReader(csv))
+		if err != nil || format != OnePassword || len(rowErrors) > 0 || len(records) != 1 {
+			t.Fatalf("%s: formato %s, registros %+v, erros %+v, %v", tt.header, format, records, rowErrors, err)
+		}
+		if records[0].URL != tt.want {
+			t.Errorf("%s: URL %q, esperado %q", tt.header, records[0].URL, tt.want)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package importer
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/Vicente/Password-Mobile-App/backend/app/kdbx"
+	"github.com/Vicente/Password-Mobile-App/backend/app/otp"
+)
+
+const KeePass Format = "keepass"
+
+// ParseKDBX abre um banco KeePass (KDBX 4) com a senha informada e converte
+// suas entradas. Como não há linhas, Linha é a posição da entrada no banco,
+// começando em 1.
+func ParseKDBX(r io.Reader, senha string) (Format, []Record, []RowError, error) {
+	db, err := kdbx.Read(r, senha)
+	if err != nil {
+		return "", nil, nil, err
+	}
+
+	var records []Record
+	var rowErrors []RowError
+	for i, entry := range db.Entradas {
+		record := Record{
+			Linha:   i + 1,
+			Nome:    strings.TrimSpace(entry.Titulo),
+			Usuario: strings.TrimSpace(entry.Usuario),
+			Senha:   entry.Senha,
+			URL:     strings.TrimSpace(entry.URL),
+			Notas:   strings.TrimSpace(entry.Notas),
+			Pasta:   entry.Grupo,
+			Tags:    entry.Tags,
+		}
+		var keePassOTP map[string]string
+		for _, campo := range entry.Campos {
+			switch {
+			case campo.Nome == "otp":
+				record.TOTP = strings.TrimSpace(campo.Valor)
+			case strings.HasPrefix(campo.Nome, "TimeOtp-"):
+				if keePassOTP == nil {
+					keePassOTP = make(map[string]string)
+				}
+				keePassOTP[campo.Nome] = strings.TrimSpace(campo.Valor)
+			default:
+				record.Campos = append(record.Campos, Field{Nome: campo.Nome, Valor: campo.Valor, Oculto: campo.Protegido})
+			}
+		}
+		if record.TOTP == "" && keePassOTP != nil {
+			totp, err := keePassTOTP(keePassOTP)
+			if err != nil {
+				record.Aviso = err.Error()
+			}
+			record.TOTP = totp
+		}
+
+		if err := validate(&record); err != nil {
+			rowErrors = append(rowErrors, RowError{Linha: record.Linha, Motivo: err.Error()})
+			continue
+		}
+		records = append(records, record)
+	}
+
+	return KeePass, records, rowErrors, nil
+}
+
+// keePassTOTP monta a URI otpauth:// a partir dos campos TimeOtp-* do
+// KeePass 2, que guarda o segredo em texto, hexadecimal, base32 ou base64.
+// Sem segredo a URI fica vazia; com um segredo ilegível ou parâmetros fora do
+// aceito o TOTP é descartado e o erro explica o motivo.
+func keePassTOTP(fields map[string]string) (string, error) {
+	var secret []byte
+	var err error
+	switch {
+	case fields["TimeOtp-Secret-Base32"] != "":
+		secret, err = otp.DecodeSecret(fields["TimeOtp-Secret-Base32"])
+	case fields["TimeOtp-Secret-Hex"] != "":
+		secret, err = hex.DecodeString(strings.ReplaceAll(fields["TimeOtp-Secret-Hex"], " ", ""))
+	case fields["TimeOtp-Secret-Base64"] != "":
+		secret, err = base64.StdEncoding.DecodeString(fields["TimeOtp-Secret-Base64"])
+	default:
+		secret = []byte(fields["TimeOtp-Secret"])
+	}
+	if err != nil {
+		return "", errors.New("segredo TimeOtp ilegível, o TOTP foi descartado")
+	}
+	if len(secret) == 0 {
+		return "", nil
+	}
+
+	key := otp.Key{Secret: secret, Type: otp.TypeTOTP}
+	if value := fields["TimeOtp-Length"]; value != "" {
+		digits, err := strconv.Atoi(value)
+		if err != nil || digits < otp.MinDigits || digits > otp.MaxDigits {
+			return "", fmt.Errorf("TimeOtp-Length inválido (%s), o TOTP foi descartado", value)
+		}
+		key.Digits = digits
+	}
+	if value := fields["TimeOtp-Period"]; value != "" {
+		period, err := strconv.Atoi(value)
+		if err != nil || period <= 0 || period > otp.MaxPeriod {
+			return "", fmt.Errorf("TimeOtp-Period inválido (%s), o TOTP foi descartado", value)
+		}
+		key.Period = period
+	}
+	switch value := fields["TimeOtp-Algorithm"]; value {
+	case "", "HMAC-SHA-1":
+	case "HMAC-SHA-256":
+		key.Algorithm = otp.SHA256
+	case "HMAC-SHA-512":
+		key.Algorithm = otp.SHA512
+	default:
+		return "", fmt.Errorf("TimeOtp-Algorithm desconhecido (%s), o TOTP foi descartado", value)
+	}
+	return key.URI(), nil
+}
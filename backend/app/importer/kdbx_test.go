@@ -0,0 +1,87 @@
+package importer
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/Vicente/Password-Mobile-App/backend/app/kdbx"
+)
+
+func TestKeePassTOTP(t *testing.T) {
+	tests := []struct {
+		name   string
+		fields map[string]string
+		uri    string
+		aviso  string
+	}{
+		{"base32", map[string]string{"TimeOtp-Secret-Base32": "JBSWY3DPEHPK3PXP"}, "otpauth://totp/?secret=JBSWY3DPEHPK3PXP", ""},
+		{"parâmetros", map[string]string{"TimeOtp-Secret-Hex": "48 65 6c 6c 6f", "TimeOtp-Length": "8", "TimeOtp-Period": "60", "TimeOtp-Algorithm": "HMAC-SHA-256"}, "otpauth://totp/?algorithm=SHA256&digits=8&period=60&secret=JBSWY3DP", ""},
+		{"sem segredo", map[string]string{"TimeOtp-Length": "6"}, "", ""},
+		{"tamanho grande", map[string]string{"TimeOtp-Secret": "Hello", "TimeOtp-Length": "10"}, "", "TimeOtp-Length"},
+		{"tamanho pequeno", map[string]string{"TimeOtp-Secret": "Hello", "TimeOtp-Length": "4"}, "", "TimeOtp-Length"},
+		{"tamanho não numérico", map[string]string{"TimeOtp-Secret": "Hello", "TimeOtp-Length": "seis"}, "", "TimeOtp-Length"},
+		{"período zero", map[string]string{"TimeOtp-Secret": "Hello", "TimeOtp-Period": "0"}, "", "TimeOtp-Period"},
+		{"período grande", map[string]string{"TimeOtp-Secret": "Hello", "TimeOtp-Period": "86400"}, "", "TimeOtp-Period"},
+		{"algoritmo", map[string]string{"TimeOtp-Secret": "Hello", "TimeOtp-Algorithm": "HMAC-MD5"}, "", "TimeOtp-Algorithm"},
+		{"base64 ilegível", map[string]string{"TimeOtp-Secret-Base64": "não é base64"}, "", "segredo"},
+	}
+
+	for _, tt := range tests {
+		uri, err := keePassTOTP(tt.fields)
+		if tt.aviso == "" {
+			if err != nil {
+				t.Errorf("%s: %v", tt.name, err)
+			} else if uri != tt.uri {
+				t.Errorf("%s: %s, esperado %s", tt.name, uri, tt.uri)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), tt.aviso) {
+			t.Errorf("%s: erro %v, esperado aviso sobre %s", tt.name, err, tt.aviso)
+		}
+		if uri != "" {
+			t.Errorf("%s: TOTP mantido: %s", tt.name, uri)
+		}
+	}
+}
+
+// Uma entrada com TimeOtp-* inválido é importada sem o autenticador.
+func TestParseKDBXDropsInvalidTOTP(t *testing.T) {
+	db := &kdbx.Database{Entradas: []kdbx.Entry{
+		{Titulo: "Válido", Senha: "s1", Campos: []kdbx.Field{
+			{Nome: "TimeOtp-Secret-Base32", Valor: "JBSWY3DPEHPK3PXP", Protegido: true},
+			{Nome: "TimeOtp-Length", Valor: "8"},
+		}},
+		{Titulo: "Inválido", Senha: "s2", Campos: []kdbx.Field{
+			{Nome: "TimeOtp-Secret-Base32", Valor: "JBSWY3DPEHPK3PXP", Protegido: true},
+			{Nome: "TimeOtp-Period", Valor: "-30"},
+			{Nome: "Pergunta", Valor: "cor favorita"},
+		}},
+	}}
+
+	var buf bytes.Buffer
+	if err := kdbx.Write(&buf, db, "senha", kdbx.Options{Cifra: kdbx.AES256, KDF: kdbx.AESKDF, Iteracoes: 1000}); err != nil {
+		t.Fatal(err)
+	}
+
+	_, records, rowErrors, err := ParseKDBX(&buf, "senha")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rowErrors) > 0 || len(records) != 2 {
+		t.Fatalf("%d registros, erros %+v", len(records), rowErrors)
+	}
+
+	if records[0].TOTP != "otpauth://totp/?digits=8&secret=JBSWY3DPEHPK3PXP" || records[0].Aviso != "" {
+		t.Errorf("entrada válida: TOTP %q, aviso %q", records[0].TOTP, records[0].Aviso)
+	}
+
+	invalid := records[1]
+	if invalid.TOTP != "" || !strings.Contains(invalid.Aviso, "TimeOtp-Period") {
+		t.Errorf("entrada inválida: TOTP %q, aviso %q", invalid.TOTP, invalid.Aviso)
+	}
+	if invalid.Senha != "s2" || len(invalid.Campos) != 1 || invalid.Campos[0].Nome != "Pergunta" {
+		t.Errorf("entrada inválida não mantida por inteiro: %+v", invalid)
+	}
+}
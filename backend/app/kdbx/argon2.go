@@ -0,0 +1,282 @@
+package kdbx
+
+import (
+	"encoding/binary"
+	"math/bits"
+	"sync"
+
+	"golang.org/x/crypto/blake2b"
+)
+
+// Implementação do Argon2 (RFC 9106, versão 0x13). O golang.org/x/crypto/argon2
+// só exporta Argon2i e Argon2id, mas o KeePass usa Argon2d por padrão; esta
+// versão segue a mesma estrutura do pacote original (licença BSD).
+
+const (
+	argon2d  = 0
+	argon2id = 2
+
+	argon2Version    = 0x13
+	argon2SyncPoints = 4
+	argon2BlockWords = 128
+)
+
+type argon2Block [argon2BlockWords]uint64
+
+func argon2Key(mode int, password, salt, secret, data []byte, time, memory uint32, threads uint8, keyLen uint32) []byte {
+	h0 := argon2InitHash(mode, password, salt, secret, data, time, memory, uint32(threads), keyLen)
+
+	lanesCount := uint32(threads)
+	memory = memory / (argon2SyncPoints * lanesCount) * (argon2SyncPoints * lanesCount)
+	if memory < 2*argon2SyncPoints*lanesCount {
+		memory = 2 * argon2SyncPoints * lanesCount
+	}
+
+	blocks := argon2InitBlocks(&h0, memory, lanesCount)
+	argon2Process(mode, blocks, time, memory, lanesCount)
+	return argon2Extract(blocks, memory, lanesCount, keyLen)
+}
+
+func argon2InitHash(mode int, password, salt, secret, data []byte, time, memory, threads, keyLen uint32) [blake2b.Size + 8]byte {
+	var h0 [blake2b.Size + 8]byte
+	var params [24]byte
+	var tmp [4]byte
+
+	h, _ := blake2b.New512(nil)
+	binary.LittleEndian.PutUint32(params[0:4], threads)
+	binary.LittleEndian.PutUint32(params[4:8], keyLen)
+	binary.LittleEndian.PutUint32(params[8:12], memory)
+	binary.LittleEndian.PutUint32(params[12:16], time)
+	binary.LittleEndian.PutUint32(params[16:20], argon2Version)
+	binary.LittleEndian.PutUint32(params[20:24], uint32(mode))
+	h.Write(params[:])
+
+	for _, value := range [][]byte{password, salt, secret, data} {
+		binary.LittleEndian.PutUint32(tmp[:], uint32(len(value)))
+		h.Write(tmp[:])
+		h.Write(value)
+	}
+
+	h.Sum(h0[:0])
+	return h0
+}
+
+func argon2InitBlocks(h0 *[blake2b.Size + 8]byte, memory, threads uint32) []argon2Block {
+	var buf [1024]byte
+	blocks := make([]argon2Block, memory)
+	laneLength := memory / threads
+
+	for lane := uint32(0); lane < threads; lane++ {
+		j := lane * laneLength
+		binary.LittleEndian.PutUint32(h0[blake2b.Size+4:], lane)
+
+		binary.LittleEndian.PutUint32(h0[blake2b.Size:], 0)
+		blake2bLong(buf[:], h0[:])
+		for i := range blocks[j] {
+			blocks[j][i] = binary.LittleEndian.Uint64(buf[i*8:])
+		}
+
+		binary.LittleEndian.PutUint32(h0[blake2b.Size:], 1)
+		blake2bLong(buf[:], h0[:])
+		for i := range blocks[j+1] {
+			blocks[j+1][i] = binary.LittleEndian.Uint64(buf[i*8:])
+		}
+	}
+	return blocks
+}
+
+func argon2Process(mode int, blocks []argon2Block, time, memory, threads uint32) {
+	laneLength := memory / threads
+	segmentLength := laneLength / argon2SyncPoints
+
+	processSegment := func(n, slice, lane uint32, wg *sync.WaitGroup) {
+		defer wg.Done()
+
+		var addresses, in, zero argon2Block
+		dataIndependent := mode == argon2id && n == 0 && slice < argon2SyncPoints/2
+		if dataIndependent {
+			in[0] = uint64(n)
+			in[1] = uint64(lane)
+			in[2] = uint64(slice)
+			in[3] = uint64(memory)
+			in[4] = uint64(time)
+			in[5] = uint64(mode)
+		}
+
+		index := uint32(0)
+		if n == 0 && slice == 0 {
+			// Os dois primeiros blocos de cada faixa já foram gerados.
+			index = 2
+			if dataIndependent {
+				in[6]++
+				argon2Compress(&addresses, &in, &zero, false)
+				argon2Compress(&addresses, &addresses, &zero, false)
+			}
+		}
+
+		offset := lane*laneLength + slice*segmentLength + index
+		for index < segmentLength {
+			prev := offset - 1
+			if index == 0 && slice == 0 {
+				prev += laneLength
+			}
+
+			var random uint64
+			if dataIndependent {
+				if index%argon2BlockWords == 0 {
+					in[6]++
+					argon2Compress(&addresses, &in, &zero, false)
+					argon2Compress(&addresses, &addresses, &zero, false)
+				}
+				random = addresses[index%argon2BlockWords]
+			} else {
+				random = blocks[prev][0]
+			}
+
+			ref := argon2IndexAlpha(random, laneLength, segmentLength, threads, n, slice, lane, index)
+			argon2Compress(&blocks[offset], &blocks[prev], &blocks[ref], true)
+			index, offset = index+1, offset+1
+		}
+	}
+
+	for n := uint32(0); n < time; n++ {
+		for slice := uint32(0); slice < argon2SyncPoints; slice++ {
+			var wg sync.WaitGroup
+			for lane := uint32(0); lane < threads; lane++ {
+				wg.Add(1)
+				go processSegment(n, slice, lane, &wg)
+			}
+			wg.Wait()
+		}
+	}
+}
+
+func argon2Extract(blocks []argon2Block, memory, threads, keyLen uint32) []byte {
+	laneLength := memory / threads
+	for lane := uint32(0); lane < threads-1; lane++ {
+		for i, v := range blocks[(lane*laneLength)+(laneLength-1)] {
+			blocks[memory-1][i] ^= v
+		}
+	}
+
+	var buf [1024]byte
+	for i, v := range blocks[memory-1] {
+		binary.LittleEndian.PutUint64(buf[i*8:], v)
+	}
+
+	key := make([]byte, keyLen)
+	blake2bLong(key, buf[:])
+	return key
+}
+
+func argon2IndexAlpha(random uint64, laneLength, segmentLength, threads, n, slice, lane, index uint32) uint32 {
+	refLane := uint32(random>>32) % threads
+	if n == 0 && slice == 0 {
+		refLane = lane
+	}
+
+	m, s := 3*segmentLength, ((slice+1)%argon2SyncPoints)*segmentLength
+	if lane == refLane {
+		m += index
+	}
+	if n == 0 {
+		m, s = slice*segmentLength, 0
+		if slice == 0 || lane == refLane {
+			m += index
+		}
+	}
+	if index == 0 || lane == refLane {
+		m--
+	}
+
+	p := random & 0xFFFFFFFF
+	p = (p * p) >> 32
+	p = (p * uint64(m)) >> 32
+	return refLane*laneLength + uint32((uint64(s)+uint64(m)-(p+1))%uint64(laneLength))
+}
+
+// argon2Compress é a função de compressão G: aplica as rodadas BlaMka às
+// linhas e depois às colunas da matriz 8x8 de palavras de 128 bits.
+func argon2Compress(out, in1, in2 *argon2Block, xor bool) {
+	var t argon2Block
+	for i := range t {
+		t[i] = in1[i] ^ in2[i]
+	}
+
+	for i := 0; i < argon2BlockWords; i += 16 {
+		blamkaRound(&t, i, i+1, i+2, i+3, i+4, i+5, i+6, i+7, i+8, i+9, i+10, i+11, i+12, i+13, i+14, i+15)
+	}
+	for i := 0; i < argon2BlockWords/8; i += 2 {
+		blamkaRound(&t, i, i+1, 16+i, 16+i+1, 32+i, 32+i+1, 48+i, 48+i+1,
+			64+i, 64+i+1, 80+i, 80+i+1, 96+i, 96+i+1, 112+i, 112+i+1)
+	}
+
+	if xor {
+		for i := range t {
+			out[i] ^= in1[i] ^ in2[i] ^ t[i]
+		}
+	} else {
+		for i := range t {
+			out[i] = in1[i] ^ in2[i] ^ t[i]
+		}
+	}
+}
+
+func blamkaRound(b *argon2Block, i0, i1, i2, i3, i4, i5, i6, i7, i8, i9, i10, i11, i12, i13, i14, i15 int) {
+	blamkaG(b, i0, i4, i8, i12)
+	blamkaG(b, i1, i5, i9, i13)
+	blamkaG(b, i2, i6, i10, i14)
+	blamkaG(b, i3, i7, i11, i15)
+	blamkaG(b, i0, i5, i10, i15)
+	blamkaG(b, i1, i6, i11, i12)
+	blamkaG(b, i2, i7, i8, i13)
+	blamkaG(b, i3, i4, i9, i14)
+}
+
+func blamkaG(b *argon2Block, ia, ib, ic, id int) {
+	a, bb, c, d := b[ia], b[ib], b[ic], b[id]
+
+	a += bb + 2*uint64(uint32(a))*uint64(uint32(bb))
+	d = bits.RotateLeft64(d^a, -32)
+	c += d + 2*uint64(uint32(c))*uint64(uint32(d))
+	bb = bits.RotateLeft64(bb^c, -24)
+	a += bb + 2*uint64(uint32(a))*uint64(uint32(bb))
+	d = bits.RotateLeft64(d^a, -16)
+	c += d + 2*uint64(uint32(c))*uint64(uint32(d))
+	bb = bits.RotateLeft64(bb^c, -63)
+
+	b[ia], b[ib], b[ic], b[id] = a, bb, c, d
+}
+
+// blake2bLong é a função de hash de tamanho variável H' do Argon2.
+func blake2bLong(out []byte, in []byte) {
+	var length [4]byte
+	binary.LittleEndian.PutUint32(length[:], uint32(len(out)))
+
+	if len(out) <= blake2b.Size {
+		h, _ := blake2b.New(len(out), nil)
+		h.Write(length[:])
+		h.Write(in)
+		h.Sum(out[:0])
+		return
+	}
+
+	h, _ := blake2b.New512(nil)
+	h.Write(length[:])
+	h.Write(in)
+
+	var v [blake2b.Size]byte
+	h.Sum(v[:0])
+	copy(out, v[:32])
+	out = out[32:]
+
+	for len(out) > blake2b.Size {
+		v = blake2b.Sum512(v[:])
+		copy(out, v[:32])
+		out = out[32:]
+	}
+
+	last, _ := blake2b.New(len(out), nil)
+	last.Write(v[:])
+	last.Sum(out[:0])
+}
@@ -0,0 +1,61 @@
+package kdbx
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+
+	"golang.org/x/crypto/argon2"
+)
+
+// Vetores da seção 5 da RFC 9106, que usam segredo e dados associados.
+func TestArgon2RFC9106(t *testing.T) {
+	password := bytes.Repeat([]byte{0x01}, 32)
+	salt := bytes.Repeat([]byte{0x02}, 16)
+	secret := bytes.Repeat([]byte{0x03}, 8)
+	data := bytes.Repeat([]byte{0x04}, 12)
+
+	tests := []struct {
+		name string
+		mode int
+		tag  string
+	}{
+		{"argon2d", argon2d, "512b391b6f1162975371d30919734294f868e3be3984f3c1a13a4db9fabe4acb"},
+		{"argon2id", argon2id, "0d640df58d78766c08c037a34a8b53c9d01ef0452d75b65eb52520e96b01e659"},
+	}
+
+	for _, tt := range tests {
+		got := argon2Key(tt.mode, password, salt, secret, data, 3, 32, 4, 32)
+		if hex.EncodeToString(got) != tt.tag {
+			t.Errorf("%s: %x, esperado %s", tt.name, got, tt.tag)
+		}
+	}
+}
+
+// O Argon2id daqui precisa bater com o de golang.org/x/crypto/argon2, que
+// serviu de base para a implementação.
+func TestArgon2idMatchesXCrypto(t *testing.T) {
+	tests := []struct {
+		time    uint32
+		memory  uint32
+		threads uint8
+		keyLen  uint32
+	}{
+		{1, 64, 1, 32},
+		{3, 32, 4, 32},
+		{2, 1024, 2, 32},
+		{4, 257, 3, 64},
+		{1, 8, 1, 16},
+	}
+
+	password := []byte("correct horse battery staple")
+	salt := []byte("sal do keepass!!")
+
+	for _, tt := range tests {
+		want := argon2.IDKey(password, salt, tt.time, tt.memory, tt.threads, tt.keyLen)
+		got := argon2Key(argon2id, password, salt, nil, nil, tt.time, tt.memory, tt.threads, tt.keyLen)
+		if !bytes.Equal(got, want) {
+			t.Errorf("t=%d m=%d p=%d: %x, esperado %x", tt.time, tt.memory, tt.threads, got, want)
+		}
+	}
+}
@@ -0,0 +1,309 @@
+package kdbx
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+const (
+	signature1 uint32 = 0x9AA2D903
+	signature2 uint32 = 0xB54BFB67
+
+	versionMajor uint16 = 4
+	versionMinor uint16 = 0
+
+	maxHeaderField = 1 << 20
+)
+
+// Campos do cabeçalho externo do KDBX 4.
+const (
+	fieldEndOfHeader      byte = 0
+	fieldCipherID         byte = 2
+	fieldCompressionFlags byte = 3
+	fieldMasterSeed       byte = 4
+	fieldEncryptionIV     byte = 7
+	fieldKdfParameters    byte = 11
+	fieldPublicCustomData byte = 12
+)
+
+// Campos do cabeçalho interno, gravado no início do conteúdo decifrado.
+const (
+	innerEndOfHeader byte = 0
+	innerStreamID    byte = 1
+	innerStreamKey   byte = 2
+	innerBinary      byte = 3
+)
+
+var (
+	cipherAES256   = [16]byte{0x31, 0xc1, 0xf2, 0xe6, 0xbf, 0x71, 0x43, 0x50, 0xbe, 0x58, 0x05, 0x21, 0x6a, 0xfc, 0x5a, 0xff}
+	cipherChaCha20 = [16]byte{0xd6, 0x03, 0x8a, 0x2b, 0x8b, 0x6f, 0x4c, 0xb5, 0xa5, 0x24, 0x33, 0x9a, 0x31, 0xdb, 0xb5, 0x9a}
+
+	kdfAES      = [16]byte{0xc9, 0xd9, 0xf3, 0x9a, 0x62, 0x8a, 0x44, 0x60, 0xbf, 0x74, 0x0d, 0x08, 0xc1, 0x8a, 0x4f, 0xea}
+	kdfArgon2d  = [16]byte{0xef, 0x63, 0x6d, 0xdf, 0x8c, 0x29, 0x44, 0x4b, 0x91, 0xf7, 0xa9, 0xa4, 0x03, 0xe3, 0x0a, 0x0c}
+	kdfArgon2id = [16]byte{0x9e, 0x29, 0x8b, 0x19, 0x56, 0xdb, 0x47, 0x73, 0xb2, 0x3d, 0xfc, 0x3e, 0xc6, 0xf0, 0xa1, 0xe6}
+)
+
+type header struct {
+	cipherID   [16]byte
+	compressed bool
+	masterSeed []byte
+	iv         []byte
+	kdf        variantDictionary
+	raw        []byte
+}
+
+func readHeader(r io.Reader) (*header, error) {
+	var buf bytes.Buffer
+	tee := io.TeeReader(r, &buf)
+
+	var prefix struct {
+		Sig1, Sig2   uint32
+		Minor, Major uint16
+	}
+	if err := binary.Read(tee, binary.LittleEndian, &prefix); err != nil {
+		return nil, errors.New("kdbx: arquivo muito curto")
+	}
+	if prefix.Sig1 != signature1 || prefix.Sig2 != signature2 {
+		return nil, errors.New("kdbx: o arquivo não é um banco KeePass")
+	}
+	if prefix.Major != versionMajor {
+		return nil, fmt.Errorf("kdbx: versão %d.%d não suportada, apenas KDBX 4", prefix.Major, prefix.Minor)
+	}
+
+	h := &header{}
+	for {
+		var id byte
+		var size uint32
+		if err := binary.Read(tee, binary.LittleEndian, &id); err != nil {
+			return nil, errors.New("kdbx: cabeçalho truncado")
+		}
+		if err := binary.Read(tee, binary.LittleEndian, &size); err != nil {
+			return nil, errors.New("kdbx: cabeçalho truncado")
+		}
+		if size > maxHeaderField {
+			return nil, errors.New("kdbx: campo do cabeçalho muito grande")
+		}
+
+		data := make([]byte, size)
+		if _, err := io.ReadFull(tee, data); err != nil {
+			return nil, errors.New("kdbx: cabeçalho truncado")
+		}
+
+		switch id {
+		case fieldEndOfHeader:
+			h.raw = buf.Bytes()
+			return h, h.validate()
+		case fieldCipherID:
+			if len(data) != 16 {
+				return nil, errors.New("kdbx: identificador de cifra inválido")
+			}
+			copy(h.cipherID[:], data)
+		case fieldCompressionFlags:
+			if len(data) != 4 {
+				return nil, errors.New("kdbx: flag de compressão inválida")
+			}
+			switch binary.LittleEndian.Uint32(data) {
+			case 0:
+			case 1:
+				h.compressed = true
+			default:
+				return nil, errors.New("kdbx: algoritmo de compressão não suportado")
+			}
+		case fieldMasterSeed:
+			h.masterSeed = data
+		case fieldEncryptionIV:
+			h.iv = data
+		case fieldKdfParameters:
+			kdf, err := readVariantDictionary(data)
+			if err != nil {
+				return nil, err
+			}
+			h.kdf = kdf
+		case fieldPublicCustomData:
+		default:
+			return nil, fmt.Errorf("kdbx: campo de cabeçalho desconhecido %d", id)
+		}
+	}
+}
+
+func (h *header) validate() error {
+	if len(h.masterSeed) != 32 {
+		return errors.New("kdbx: semente mestra inválida")
+	}
+
+	switch h.cipherID {
+	case cipherAES256:
+		if len(h.iv) != 16 {
+			return errors.New("kdbx: vetor de inicialização inválido")
+		}
+	case cipherChaCha20:
+		if len(h.iv) != 12 {
+			return errors.New("kdbx: vetor de inicialização inválido")
+		}
+	default:
+		return errors.New("kdbx: cifra não suportada, use AES-256 ou ChaCha20")
+	}
+
+	if h.kdf == nil {
+		return errors.New("kdbx: parâmetros de derivação ausentes")
+	}
+	return nil
+}
+
+func (h *header) write() []byte {
+	var buf bytes.Buffer
+	binary.Write(&buf, binary.LittleEndian, signature1)
+	binary.Write(&buf, binary.LittleEndian, signature2)
+	binary.Write(&buf, binary.LittleEndian, versionMinor)
+	binary.Write(&buf, binary.LittleEndian, versionMajor)
+
+	compression := make([]byte, 4)
+	if h.compressed {
+		binary.LittleEndian.PutUint32(compression, 1)
+	}
+
+	writeField(&buf, fieldCipherID, h.cipherID[:])
+	writeField(&buf, fieldCompressionFlags, compression)
+	writeField(&buf, fieldMasterSeed, h.masterSeed)
+	writeField(&buf, fieldEncryptionIV, h.iv)
+	writeField(&buf, fieldKdfParameters, h.kdf.bytes())
+	writeField(&buf, fieldEndOfHeader, []byte("\r\n\r\n"))
+
+	h.raw = buf.Bytes()
+	return h.raw
+}
+
+func writeField(buf *bytes.Buffer, id byte, data []byte) {
+	buf.WriteByte(id)
+	binary.Write(buf, binary.LittleEndian, uint32(len(data)))
+	buf.Write(data)
+}
+
+// Tipos de valor do VariantDictionary usado nos parâmetros do KDF.
+const (
+	variantEnd       byte = 0x00
+	variantUint32    byte = 0x04
+	variantUint64    byte = 0x05
+	variantBool      byte = 0x08
+	variantInt32     byte = 0x0C
+	variantInt64     byte = 0x0D
+	variantString    byte = 0x18
+	variantByteArray byte = 0x42
+
+	variantVersion uint16 = 0x0100
+)
+
+type variantValue struct {
+	kind byte
+	data []byte
+}
+
+type variantDictionary map[string]variantValue
+
+func readVariantDictionary(data []byte) (variantDictionary, error) {
+	invalid := errors.New("kdbx: parâmetros de derivação inválidos")
+
+	if len(data) < 2 || binary.LittleEndian.Uint16(data)&0xFF00 > variantVersion {
+		return nil, invalid
+	}
+	data = data[2:]
+
+	dict := variantDictionary{}
+	for {
+		if len(data) < 1 {
+			return nil, invalid
+		}
+		kind := data[0]
+		data = data[1:]
+		if kind == variantEnd {
+			return dict, nil
+		}
+
+		key, rest, ok := readVariantChunk(data)
+		if !ok {
+			return nil, invalid
+		}
+		value, rest, ok := readVariantChunk(rest)
+		if !ok {
+			return nil, invalid
+		}
+
+		dict[string(key)] = variantValue{kind: kind, data: value}
+		data = rest
+	}
+}
+
+func readVariantChunk(data []byte) ([]byte, []byte, bool) {
+	if len(data) < 4 {
+		return nil, nil, false
+	}
+	size := binary.LittleEndian.Uint32(data)
+	data = data[4:]
+	if uint64(size) > uint64(len(data)) {
+		return nil, nil, false
+	}
+	return data[:size], data[size:], true
+}
+
+func (d variantDictionary) bytes() []byte {
+	var buf bytes.Buffer
+	binary.Write(&buf, binary.LittleEndian, variantVersion)
+
+	// A ordem das chaves não importa para o formato, mas mantê-la estável
+	// deixa a saída reproduzível.
+	for _, key := range []string{"$UUID", "R", "S", "P", "M", "I", "V", "K", "A"} {
+		value, ok := d[key]
+		if !ok {
+			continue
+		}
+		buf.WriteByte(value.kind)
+		binary.Write(&buf, binary.LittleEndian, uint32(len(key)))
+		buf.WriteString(key)
+		binary.Write(&buf, binary.LittleEndian, uint32(len(value.data)))
+		buf.Write(value.data)
+	}
+
+	buf.WriteByte(variantEnd)
+	return buf.Bytes()
+}
+
+func (d variantDictionary) bytesValue(key string) ([]byte, bool) {
+	value, ok := d[key]
+	if !ok || value.kind != variantByteArray {
+		return nil, false
+	}
+	return value.data, true
+}
+
+func (d variantDictionary) uintValue(key string) (uint64, bool) {
+	value, ok := d[key]
+	if !ok {
+		return 0, false
+	}
+	switch {
+	case value.kind == variantUint32 && len(value.data) == 4:
+		return uint64(binary.LittleEndian.Uint32(value.data)), true
+	case value.kind == variantUint64 && len(value.data) == 8:
+		return binary.LittleEndian.Uint64(value.data), true
+	}
+	return 0, false
+}
+
+func (d variantDictionary) setBytes(key string, data []byte) {
+	d[key] = variantValue{kind: variantByteArray, data: data}
+}
+
+func (d variantDictionary) setUint32(key string, v uint32) {
+	data := make([]byte, 4)
+	binary.LittleEndian.PutUint32(data, v)
+	d[key] = variantValue{kind: variantUint32, data: data}
+}
+
+func (d variantDictionary) setUint64(key string, v uint64) {
+	data := make([]byte, 8)
+	binary.LittleEndian.PutUint64(data, v)
+	d[key] = variantValue{kind: variantUint64, data: data}
+}
@@ -0,0 +1,321 @@
+// Package kdbx lê e grava bancos do KeePass no formato KDBX 4 (KeePass 2.35+,
+// KeePassXC 2.3+), com derivação por Argon2d, Argon2id ou AES-KDF e conteúdo
+// cifrado com AES-256 ou ChaCha20. Só a senha é suportada como chave;
+// arquivos-chave e anexos ficam de fora.
+package kdbx
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"io"
+	"time"
+)
+
+type Cipher string
+
+const (
+	AES256   Cipher = "aes256"
+	ChaCha20 Cipher = "chacha20"
+)
+
+type KDF string
+
+const (
+	Argon2d  KDF = "argon2d"
+	Argon2id KDF = "argon2id"
+	AESKDF   KDF = "aes-kdf"
+)
+
+// DefaultAESRounds é o número de rodadas usado quando o AES-KDF é escolhido.
+const DefaultAESRounds = 10_000_000
+
+type Database struct {
+	Nome     string
+	Entradas []Entry
+}
+
+// Entry traz os campos padrão de uma entrada do KeePass. Grupo é o caminho
+// do grupo a partir da raiz, separado por "/", e fica vazio na raiz.
+type Entry struct {
+	Grupo        string
+	Titulo       string
+	Usuario      string
+	Senha        string
+	URL          string
+	Notas        string
+	Tags         []string
+	Campos       []Field
+	CriadoEm     time.Time
+	AtualizadoEm time.Time
+}
+
+// Field é um campo extra da entrada, gravado como uma String com nome
+// próprio. Campos protegidos são cifrados no arquivo como a senha.
+type Field struct {
+	Nome      string
+	Valor     string
+	Protegido bool
+}
+
+// Options define a proteção usada na gravação. Para Argon2, Memoria é dada em
+// bytes e Iteracoes é o número de passadas; para AES-KDF, Iteracoes é o
+// número de rodadas e os demais campos são ignorados.
+type Options struct {
+	Cifra       Cipher
+	KDF         KDF
+	Memoria     uint64
+	Iteracoes   uint64
+	Paralelismo uint32
+}
+
+func DefaultOptions() Options {
+	return Options{
+		Cifra:       AES256,
+		KDF:         Argon2id,
+		Memoria:     64 << 20,
+		Iteracoes:   2,
+		Paralelismo: 2,
+	}
+}
+
+func (o Options) validate() error {
+	if o.Cifra != AES256 && o.Cifra != ChaCha20 {
+		return errors.New("kdbx: cifra não suportada, use aes256 ou chacha20")
+	}
+
+	switch o.KDF {
+	case Argon2d, Argon2id:
+		if o.Memoria < 8<<10 || o.Memoria > MaxArgon2Memory {
+			return errors.New("kdbx: memória do Argon2 fora do intervalo permitido")
+		}
+		if o.Iteracoes == 0 || o.Iteracoes > MaxArgon2Iterations {
+			return errors.New("kdbx: iterações do Argon2 fora do intervalo permitido")
+		}
+		if o.Paralelismo == 0 || uint64(o.Paralelismo) > MaxArgon2Parallelism {
+			return errors.New("kdbx: paralelismo do Argon2 fora do intervalo permitido")
+		}
+	case AESKDF:
+		if o.Iteracoes == 0 || o.Iteracoes > MaxAESRounds {
+			return errors.New("kdbx: rodadas do AES-KDF fora do intervalo permitido")
+		}
+	default:
+		return errors.New("kdbx: algoritmo de derivação não suportado")
+	}
+	return nil
+}
+
+// Read abre um banco KDBX 4 com a senha informada. Entradas da lixeira e o
+// histórico de cada entrada são ignorados.
+func Read(r io.Reader, password string) (*Database, error) {
+	h, err := readHeader(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var hash, mac [32]byte
+	if _, err := io.ReadFull(r, hash[:]); err != nil {
+		return nil, errors.New("kdbx: cabeçalho truncado")
+	}
+	if _, err := io.ReadFull(r, mac[:]); err != nil {
+		return nil, errors.New("kdbx: cabeçalho truncado")
+	}
+	if sum := sha256.Sum256(h.raw); !hmac.Equal(sum[:], hash[:]) {
+		return nil, errors.New("kdbx: cabeçalho corrompido")
+	}
+
+	masterKey, hmacKey, err := deriveKeys(h, password)
+	if err != nil {
+		return nil, err
+	}
+	if !hmac.Equal(mac[:], headerHMAC(hmacKey, h.raw)) {
+		return nil, errCorrupted
+	}
+
+	payload, err := readBlocks(r, hmacKey)
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := decryptPayload(h, masterKey, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	if h.compressed {
+		if content, err = gunzip(content); err != nil {
+			return nil, err
+		}
+	}
+
+	stream, data, err := readInnerHeader(content)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseXML(data, stream)
+}
+
+// Write grava o banco como KDBX 4.0, com o conteúdo compactado e as senhas
+// das entradas protegidas pelo fluxo interno ChaCha20.
+func Write(w io.Writer, db *Database, password string, opts Options) error {
+	return writeDocument(w, password, opts, func(stream cipher.Stream) ([]byte, error) {
+		return buildXML(db, stream, time.Now().UTC())
+	})
+}
+
+// writeDocument grava o contêiner KDBX 4 em volta do XML devolvido por build,
+// que recebe o fluxo interno para proteger os valores sensíveis.
+func writeDocument(w io.Writer, password string, opts Options, build func(stream cipher.Stream) ([]byte, error)) error {
+	if err := opts.validate(); err != nil {
+		return err
+	}
+
+	h := &header{compressed: true, masterSeed: make([]byte, 32)}
+	if opts.Cifra == ChaCha20 {
+		h.cipherID, h.iv = cipherChaCha20, make([]byte, 12)
+	} else {
+		h.cipherID, h.iv = cipherAES256, make([]byte, 16)
+	}
+	innerKey := make([]byte, 64)
+	for _, buf := range [][]byte{h.masterSeed, h.iv, innerKey} {
+		if _, err := rand.Read(buf); err != nil {
+			return err
+		}
+	}
+
+	kdf, err := newKDFParams(opts)
+	if err != nil {
+		return err
+	}
+	h.kdf = kdf
+	raw := h.write()
+
+	masterKey, hmacKey, err := deriveKeys(h, password)
+	if err != nil {
+		return err
+	}
+
+	stream, err := newInnerStream(streamChaCha20, innerKey)
+	if err != nil {
+		return err
+	}
+	data, err := build(stream)
+	if err != nil {
+		return err
+	}
+
+	var content bytes.Buffer
+	gz := gzip.NewWriter(&content)
+	gz.Write(innerHeader(streamChaCha20, innerKey))
+	gz.Write(data)
+	if err := gz.Close(); err != nil {
+		return err
+	}
+
+	payload, err := encryptPayload(h, masterKey, content.Bytes())
+	if err != nil {
+		return err
+	}
+
+	hash := sha256.Sum256(raw)
+	for _, part := range [][]byte{raw, hash[:], headerHMAC(hmacKey, raw)} {
+		if _, err := w.Write(part); err != nil {
+			return err
+		}
+	}
+	return writeBlocks(w, hmacKey, payload)
+}
+
+func deriveKeys(h *header, password string) ([]byte, []byte, error) {
+	transformed, err := transformKey(compositeKey(password), h.kdf)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	master := sha256.New()
+	master.Write(h.masterSeed)
+	master.Write(transformed)
+
+	mac := sha512.New()
+	mac.Write(h.masterSeed)
+	mac.Write(transformed)
+	mac.Write([]byte{0x01})
+
+	return master.Sum(nil), mac.Sum(nil), nil
+}
+
+func gunzip(data []byte) ([]byte, error) {
+	reader, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, errors.New("kdbx: conteúdo compactado inválido")
+	}
+	defer reader.Close()
+
+	content, err := io.ReadAll(io.LimitReader(reader, MaxContentSize+1))
+	if err != nil {
+		return nil, errors.New("kdbx: conteúdo compactado inválido")
+	}
+	if int64(len(content)) > MaxContentSize {
+		return nil, errors.New("kdbx: conteúdo muito grande")
+	}
+	return content, nil
+}
+
+func readInnerHeader(data []byte) (cipher.Stream, []byte, error) {
+	var streamID uint32
+	var key []byte
+
+	for {
+		if len(data) < 5 {
+			return nil, nil, errors.New("kdbx: cabeçalho interno truncado")
+		}
+		id := data[0]
+		size := binary.LittleEndian.Uint32(data[1:5])
+		data = data[5:]
+		if uint64(size) > uint64(len(data)) {
+			return nil, nil, errors.New("kdbx: cabeçalho interno truncado")
+		}
+		value := data[:size]
+		data = data[size:]
+
+		switch id {
+		case innerEndOfHeader:
+			stream, err := newInnerStream(streamID, key)
+			return stream, data, err
+		case innerStreamID:
+			if len(value) != 4 {
+				return nil, nil, errors.New("kdbx: cabeçalho interno inválido")
+			}
+			streamID = binary.LittleEndian.Uint32(value)
+		case innerStreamKey:
+			key = value
+		case innerBinary:
+			// Anexos não são importados.
+		default:
+			return nil, nil, errors.New("kdbx: cabeçalho interno inválido")
+		}
+	}
+}
+
+func innerHeader(streamID uint32, key []byte) []byte {
+	var buf bytes.Buffer
+	id := make([]byte, 4)
+	binary.LittleEndian.PutUint32(id, streamID)
+
+	for _, field := range []struct {
+		id   byte
+		data []byte
+	}{{innerStreamID, id}, {innerStreamKey, key}, {innerEndOfHeader, nil}} {
+		buf.WriteByte(field.id)
+		binary.Write(&buf, binary.LittleEndian, uint32(len(field.data)))
+		buf.Write(field.data)
+	}
+	return buf.Bytes()
+}
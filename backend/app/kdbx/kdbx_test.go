@@ -0,0 +1,338 @@
+package kdbx
+
+import (
+	"bytes"
+	"crypto/cipher"
+	"encoding/base64"
+	"flag"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+var update = flag.Bool("update", false, "regrava os bancos de testdata")
+
+const fixturePassword = "senha do cofre ✓"
+
+var (
+	fixtureCreated  = time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)
+	fixtureModified = time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC)
+)
+
+// fixtureDocument imita o XML gravado pelo KeePassXC 2.7: metadados que não
+// são lidos, histórico das entradas com valores protegidos, tags separadas
+// por ";" ou ",", campos extras, o TOTP em "otp" e a lixeira. Os valores
+// com Protected="True" estão em claro e são cifrados por protectFixture.
+var fixtureDocument = strings.NewReplacer("{created}", formatTime(fixtureCreated), "{modified}", formatTime(fixtureModified)).Replace(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<KeePassFile>
+	<Meta>
+		<Generator>KeePassXC</Generator>
+		<DatabaseName>Cofre de teste</DatabaseName>
+		<DatabaseNameChanged>{created}</DatabaseNameChanged>
+		<MemoryProtection>
+			<ProtectTitle>False</ProtectTitle>
+			<ProtectUserName>False</ProtectUserName>
+			<ProtectPassword>True</ProtectPassword>
+			<ProtectURL>False</ProtectURL>
+			<ProtectNotes>False</ProtectNotes>
+		</MemoryProtection>
+		<RecycleBinEnabled>True</RecycleBinEnabled>
+		<RecycleBinUUID>3q2+796tvu/erb7v3q2+7w==</RecycleBinUUID>
+		<CustomData>
+			<Item>
+				<Key>KPXC_DECRYPTION_TIME_PREFERENCE</Key>
+				<Value>1000</Value>
+			</Item>
+		</CustomData>
+	</Meta>
+	<Root>
+		<Group>
+			<UUID>AAAAAAAAAAAAAAAAAAAAAQ==</UUID>
+			<Name>Cofre de teste</Name>
+			<IsExpanded>True</IsExpanded>
+			<Entry>
+				<UUID>AAAAAAAAAAAAAAAAAAAAAg==</UUID>
+				<IconID>0</IconID>
+				<Tags>dev;trabalho</Tags>
+				<Times>
+					<LastModificationTime>{modified}</LastModificationTime>
+					<CreationTime>{created}</CreationTime>
+					<LastAccessTime>{modified}</LastAccessTime>
+					<ExpiryTime>{modified}</ExpiryTime>
+					<Expires>False</Expires>
+					<UsageCount>3</UsageCount>
+					<LocationChanged>{created}</LocationChanged>
+				</Times>
+				<String>
+					<Key>Notes</Key>
+					<Value>linha 1
+linha 2 &amp; &lt;fim&gt;</Value>
+				</String>
+				<String>
+					<Key>Password</Key>
+					<Value Protected="True">s3nh@ Ünicode</Value>
+				</String>
+				<String>
+					<Key>Pergunta</Key>
+					<Value>cor favorita</Value>
+				</String>
+				<String>
+					<Key>Title</Key>
+					<Value>GitHub</Value>
+				</String>
+				<String>
+					<Key>URL</Key>
+					<Value>https://github.com</Value>
+				</String>
+				<String>
+					<Key>UserName</Key>
+					<Value>joao</Value>
+				</String>
+				<String>
+					<Key>otp</Key>
+					<Value Protected="True">otpauth://totp/GitHub:joao?secret=JBSWY3DPEHPK3PXP&issuer=GitHub</Value>
+				</String>
+				<AutoType>
+					<Enabled>True</Enabled>
+					<DataTransferObfuscation>0</DataTransferObfuscation>
+				</AutoType>
+				<History>
+					<Entry>
+						<UUID>AAAAAAAAAAAAAAAAAAAAAg==</UUID>
+						<String>
+							<Key>Password</Key>
+							<Value Protected="True">senha antiga</Value>
+						</String>
+						<String>
+							<Key>Title</Key>
+							<Value>GitHub</Value>
+						</String>
+					</Entry>
+				</History>
+			</Entry>
+			<Group>
+				<UUID>AAAAAAAAAAAAAAAAAAAAAw==</UUID>
+				<Name>Trabalho</Name>
+				<Entry>
+					<UUID>AAAAAAAAAAAAAAAAAAAABA==</UUID>
+					<String>
+						<Key>PIN</Key>
+						<Value Protected="True">1234</Value>
+					</String>
+					<String>
+						<Key>Password</Key>
+						<Value Protected="True">outra-senha</Value>
+					</String>
+					<String>
+						<Key>Title</Key>
+						<Value>Email</Value>
+					</String>
+					<String>
+						<Key>UserName</Key>
+						<Value>joao@empresa.com</Value>
+					</String>
+				</Entry>
+				<Group>
+					<UUID>AAAAAAAAAAAAAAAAAAAABQ==</UUID>
+					<Name>Servidores</Name>
+					<Entry>
+						<UUID>AAAAAAAAAAAAAAAAAAAABg==</UUID>
+						<Tags>infra, linux</Tags>
+						<String>
+							<Key>Password</Key>
+							<Value Protected="True"></Value>
+						</String>
+						<String>
+							<Key>Title</Key>
+							<Value>SSH</Value>
+						</String>
+					</Entry>
+				</Group>
+			</Group>
+			<Group>
+				<UUID>3q2+796tvu/erb7v3q2+7w==</UUID>
+				<Name>Lixeira</Name>
+				<Entry>
+					<UUID>AAAAAAAAAAAAAAAAAAAABw==</UUID>
+					<String>
+						<Key>Password</Key>
+						<Value Protected="True">não deve aparecer</Value>
+					</String>
+					<String>
+						<Key>Title</Key>
+						<Value>Apagado</Value>
+					</String>
+				</Entry>
+			</Group>
+		</Group>
+		<DeletedObjects/>
+	</Root>
+</KeePassFile>
+`)
+
+var fixtureDatabase = &Database{
+	Nome: "Cofre de teste",
+	Entradas: []Entry{
+		{
+			Titulo:  "GitHub",
+			Usuario: "joao",
+			Senha:   "s3nh@ Ünicode",
+			URL:     "https://github.com",
+			Notas:   "linha 1\nlinha 2 & <fim>",
+			Tags:    []string{"dev", "trabalho"},
+			Campos: []Field{
+				{Nome: "Pergunta", Valor: "cor favorita"},
+				{Nome: "otp", Valor: "otpauth://totp/GitHub:joao?secret=JBSWY3DPEHPK3PXP&issuer=GitHub", Protegido: true},
+			},
+			CriadoEm:     fixtureCreated,
+			AtualizadoEm: fixtureModified,
+		},
+		{
+			Grupo:   "Trabalho",
+			Titulo:  "Email",
+			Usuario: "joao@empresa.com",
+			Senha:   "outra-senha",
+			Campos:  []Field{{Nome: "PIN", Valor: "1234", Protegido: true}},
+		},
+		{
+			Grupo:  "Trabalho/Servidores",
+			Titulo: "SSH",
+			Tags:   []string{"infra", "linux"},
+		},
+	},
+}
+
+var protectedValue = regexp.MustCompile(`(<Value Protected="True">)([^<]*)(</Value>)`)
+
+// protectFixture cifra os valores protegidos na ordem do documento, como o
+// KeePassXC faz ao gravar.
+func protectFixture(document string, stream cipher.Stream) []byte {
+	return []byte(protectedValue.ReplaceAllStringFunc(document, func(match string) string {
+		parts := protectedValue.FindStringSubmatch(match)
+		value := []byte(parts[2])
+		stream.XORKeyStream(value, value)
+		return parts[1] + base64.StdEncoding.EncodeToString(value) + parts[3]
+	}))
+}
+
+// fixtureOptions cobre as combinações de cifra e derivação aceitas pelo
+// KeePassXC, com custos baixos para o teste ser rápido.
+var fixtureOptions = map[string]Options{
+	"aes256-argon2d":    {Cifra: AES256, KDF: Argon2d, Memoria: 1 << 20, Iteracoes: 2, Paralelismo: 2},
+	"aes256-argon2id":   {Cifra: AES256, KDF: Argon2id, Memoria: 1 << 20, Iteracoes: 2, Paralelismo: 2},
+	"aes256-aeskdf":     {Cifra: AES256, KDF: AESKDF, Iteracoes: 1000},
+	"chacha20-argon2d":  {Cifra: ChaCha20, KDF: Argon2d, Memoria: 1 << 20, Iteracoes: 2, Paralelismo: 2},
+	"chacha20-argon2id": {Cifra: ChaCha20, KDF: Argon2id, Memoria: 1 << 20, Iteracoes: 2, Paralelismo: 2},
+	"chacha20-aeskdf":   {Cifra: ChaCha20, KDF: AESKDF, Iteracoes: 1000},
+}
+
+func TestReadFixtures(t *testing.T) {
+	for name, opts := range fixtureOptions {
+		path := filepath.Join("testdata", name+".kdbx")
+
+		if *update {
+			var buf bytes.Buffer
+			err := writeDocument(&buf, fixturePassword, opts, func(stream cipher.Stream) ([]byte, error) {
+				return protectFixture(fixtureDocument, stream), nil
+			})
+			if err != nil {
+				t.Fatalf("%s: %v", name, err)
+			}
+			if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		db, err := Read(bytes.NewReader(data), fixturePassword)
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		if !reflect.DeepEqual(db, fixtureDatabase) {
+			t.Errorf("%s: banco lido\n%+v\nesperado\n%+v", name, db, fixtureDatabase)
+		}
+
+		if _, err := Read(bytes.NewReader(data), "senha errada"); err != errCorrupted {
+			t.Errorf("%s: senha errada: %v", name, err)
+		}
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	for name, opts := range fixtureOptions {
+		db := fixtureDatabase
+		for i := 0; i < 2; i++ {
+			var buf bytes.Buffer
+			if err := Write(&buf, db, fixturePassword, opts); err != nil {
+				t.Fatalf("%s: gravar: %v", name, err)
+			}
+
+			read, err := Read(&buf, fixturePassword)
+			if err != nil {
+				t.Fatalf("%s: ler: %v", name, err)
+			}
+			if !reflect.DeepEqual(withoutTimes(read), withoutTimes(fixtureDatabase)) {
+				t.Fatalf("%s: passada %d\n%+v\nesperado\n%+v", name, i+1, read, fixtureDatabase)
+			}
+			db = read
+		}
+	}
+}
+
+// withoutTimes zera as datas, já que entradas sem data ganham a hora da
+// gravação.
+func withoutTimes(db *Database) *Database {
+	out := *db
+	out.Entradas = append([]Entry(nil), db.Entradas...)
+	for i := range out.Entradas {
+		out.Entradas[i].CriadoEm = time.Time{}
+		out.Entradas[i].AtualizadoEm = time.Time{}
+	}
+	return &out
+}
+
+func TestKDFLimits(t *testing.T) {
+	salt := bytes.Repeat([]byte{0x01}, 32)
+
+	argon := func(memory, iterations uint64) variantDictionary {
+		params := variantDictionary{}
+		params.setBytes("$UUID", kdfArgon2d[:])
+		params.setBytes("S", salt)
+		params.setUint32("P", 2)
+		params.setUint64("M", memory)
+		params.setUint64("I", iterations)
+		params.setUint32("V", argon2Version)
+		return params
+	}
+	aes := func(rounds uint64) variantDictionary {
+		params := variantDictionary{}
+		params.setBytes("$UUID", kdfAES[:])
+		params.setBytes("S", salt)
+		params.setUint64("R", rounds)
+		return params
+	}
+
+	tests := []struct {
+		name   string
+		params variantDictionary
+	}{
+		{"memória do Argon2", argon(MaxArgon2Memory+1<<20, 2)},
+		{"passadas do Argon2", argon(1<<20, MaxArgon2Iterations+1)},
+		{"rodadas do AES-KDF", aes(MaxAESRounds + 1)},
+	}
+
+	for _, tt := range tests {
+		if _, err := transformKey(compositeKey(fixturePassword), tt.params); err == nil {
+			t.Errorf("%s acima do limite aceita", tt.name)
+		}
+	}
+}
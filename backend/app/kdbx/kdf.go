@@ -0,0 +1,132 @@
+package kdbx
+
+import (
+	"crypto/aes"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+)
+
+// Limites aplicados aos parâmetros lidos de arquivos enviados por usuários,
+// para que um banco forjado não consuma memória ou CPU sem controle. Cobrem
+// os padrões do KeePass e do KeePassXC (64 MiB e poucas passadas no Argon2,
+// alguns milhões de rodadas no AES-KDF); cada importação custa no máximo
+// cerca de um segundo de CPU.
+var (
+	MaxArgon2Memory      uint64 = 64 << 20
+	MaxArgon2Iterations  uint64 = 10
+	MaxArgon2Parallelism uint64 = 16
+	MaxAESRounds         uint64 = 20_000_000
+)
+
+func compositeKey(password string) []byte {
+	// O KeePass aceita várias fontes de chave (senha, arquivo-chave, Windows);
+	// aqui só existe a senha, que entra como SHA-256 de si mesma.
+	first := sha256.Sum256([]byte(password))
+	second := sha256.Sum256(first[:])
+	return second[:]
+}
+
+func transformKey(composite []byte, params variantDictionary) ([]byte, error) {
+	uuid, ok := params.bytesValue("$UUID")
+	if !ok || len(uuid) != 16 {
+		return nil, errors.New("kdbx: algoritmo de derivação ausente")
+	}
+
+	var id [16]byte
+	copy(id[:], uuid)
+
+	switch id {
+	case kdfAES:
+		return aesKDF(composite, params)
+	case kdfArgon2d:
+		return argon2KDF(argon2d, composite, params)
+	case kdfArgon2id:
+		return argon2KDF(argon2id, composite, params)
+	}
+	return nil, errors.New("kdbx: algoritmo de derivação não suportado")
+}
+
+func aesKDF(composite []byte, params variantDictionary) ([]byte, error) {
+	seed, ok := params.bytesValue("S")
+	rounds, hasRounds := params.uintValue("R")
+	if !ok || len(seed) != 32 || !hasRounds {
+		return nil, errors.New("kdbx: parâmetros do AES-KDF inválidos")
+	}
+	if rounds > MaxAESRounds {
+		return nil, errors.New("kdbx: número de rodadas do AES-KDF acima do permitido")
+	}
+
+	block, err := aes.NewCipher(seed)
+	if err != nil {
+		return nil, err
+	}
+
+	key := make([]byte, 32)
+	copy(key, composite)
+	for i := uint64(0); i < rounds; i++ {
+		block.Encrypt(key[:16], key[:16])
+		block.Encrypt(key[16:], key[16:])
+	}
+
+	sum := sha256.Sum256(key)
+	return sum[:], nil
+}
+
+func argon2KDF(mode int, composite []byte, params variantDictionary) ([]byte, error) {
+	salt, ok := params.bytesValue("S")
+	if !ok || len(salt) < 8 {
+		return nil, errors.New("kdbx: salt do Argon2 inválido")
+	}
+
+	memory, okM := params.uintValue("M")
+	iterations, okI := params.uintValue("I")
+	parallelism, okP := params.uintValue("P")
+	version, okV := params.uintValue("V")
+	if !okM || !okI || !okP || !okV {
+		return nil, errors.New("kdbx: parâmetros do Argon2 incompletos")
+	}
+	if version != argon2Version {
+		return nil, errors.New("kdbx: versão do Argon2 não suportada")
+	}
+	if memory < 8<<10 || iterations == 0 || parallelism == 0 {
+		return nil, errors.New("kdbx: parâmetros do Argon2 inválidos")
+	}
+	if memory > MaxArgon2Memory || iterations > MaxArgon2Iterations || parallelism > MaxArgon2Parallelism {
+		return nil, errors.New("kdbx: parâmetros do Argon2 acima do permitido")
+	}
+
+	secret, _ := params.bytesValue("K")
+	data, _ := params.bytesValue("A")
+
+	return argon2Key(mode, composite, salt, secret, data, uint32(iterations), uint32(memory/1024), uint8(parallelism), 32), nil
+}
+
+func newKDFParams(opts Options) (variantDictionary, error) {
+	salt := make([]byte, 32)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+
+	params := variantDictionary{}
+	params.setBytes("S", salt)
+
+	switch opts.KDF {
+	case AESKDF:
+		params.setBytes("$UUID", kdfAES[:])
+		params.setUint64("R", opts.Iteracoes)
+	case Argon2d, Argon2id:
+		if opts.KDF == Argon2d {
+			params.setBytes("$UUID", kdfArgon2d[:])
+		} else {
+			params.setBytes("$UUID", kdfArgon2id[:])
+		}
+		params.setUint32("P", opts.Paralelismo)
+		params.setUint64("M", opts.Memoria)
+		params.setUint64("I", opts.Iteracoes)
+		params.setUint32("V", argon2Version)
+	default:
+		return nil, errors.New("kdbx: algoritmo de derivação não suportado")
+	}
+	return params, nil
+}
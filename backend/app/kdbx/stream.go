@@ -0,0 +1,224 @@
+package kdbx
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"io"
+
+	"golang.org/x/crypto/chacha20"
+	"golang.org/x/crypto/salsa20/salsa"
+)
+
+const (
+	blockSize    = 1 << 20
+	maxBlockSize = 64 << 20
+)
+
+// MaxContentSize limita o conteúdo decifrado e descompactado, evitando
+// bombas de gzip.
+var MaxContentSize int64 = 64 << 20
+
+// Identificadores do gerador de fluxo usado nos valores protegidos do XML.
+const (
+	streamSalsa20  uint32 = 2
+	streamChaCha20 uint32 = 3
+)
+
+var salsa20Nonce = [8]byte{0xE8, 0x30, 0x09, 0x4B, 0x97, 0x20, 0x5D, 0x2A}
+
+var errCorrupted = errors.New("kdbx: senha incorreta ou arquivo corrompido")
+
+func blockHMAC(hmacKey []byte, index uint64, data []byte) []byte {
+	var idx [8]byte
+	binary.LittleEndian.PutUint64(idx[:], index)
+
+	key := sha512.New()
+	key.Write(idx[:])
+	key.Write(hmacKey)
+
+	mac := hmac.New(sha256.New, key.Sum(nil))
+	mac.Write(idx[:])
+	var size [4]byte
+	binary.LittleEndian.PutUint32(size[:], uint32(len(data)))
+	mac.Write(size[:])
+	mac.Write(data)
+	return mac.Sum(nil)
+}
+
+// headerHMAC usa o índice reservado 2^64-1 e cobre apenas os bytes do
+// cabeçalho, sem o campo de tamanho dos blocos comuns.
+func headerHMAC(hmacKey, raw []byte) []byte {
+	var idx [8]byte
+	binary.LittleEndian.PutUint64(idx[:], ^uint64(0))
+
+	key := sha512.New()
+	key.Write(idx[:])
+	key.Write(hmacKey)
+
+	mac := hmac.New(sha256.New, key.Sum(nil))
+	mac.Write(raw)
+	return mac.Sum(nil)
+}
+
+func readBlocks(r io.Reader, hmacKey []byte) ([]byte, error) {
+	var out bytes.Buffer
+
+	for index := uint64(0); ; index++ {
+		var mac [32]byte
+		var size uint32
+		if _, err := io.ReadFull(r, mac[:]); err != nil {
+			return nil, errors.New("kdbx: conteúdo truncado")
+		}
+		if err := binary.Read(r, binary.LittleEndian, &size); err != nil {
+			return nil, errors.New("kdbx: conteúdo truncado")
+		}
+		if size > maxBlockSize || int64(out.Len())+int64(size) > MaxContentSize {
+			return nil, errors.New("kdbx: conteúdo muito grande")
+		}
+
+		data := make([]byte, size)
+		if _, err := io.ReadFull(r, data); err != nil {
+			return nil, errors.New("kdbx: conteúdo truncado")
+		}
+		if !hmac.Equal(mac[:], blockHMAC(hmacKey, index, data)) {
+			return nil, errCorrupted
+		}
+		if size == 0 {
+			return out.Bytes(), nil
+		}
+		out.Write(data)
+	}
+}
+
+func writeBlocks(w io.Writer, hmacKey, data []byte) error {
+	var index uint64
+	for {
+		n := len(data)
+		if n > blockSize {
+			n = blockSize
+		}
+		chunk := data[:n]
+		data = data[n:]
+
+		var size [4]byte
+		binary.LittleEndian.PutUint32(size[:], uint32(len(chunk)))
+		for _, part := range [][]byte{blockHMAC(hmacKey, index, chunk), size[:], chunk} {
+			if _, err := w.Write(part); err != nil {
+				return err
+			}
+		}
+
+		if n == 0 {
+			return nil
+		}
+		index++
+	}
+}
+
+func decryptPayload(h *header, key, data []byte) ([]byte, error) {
+	switch h.cipherID {
+	case cipherAES256:
+		if len(data) == 0 || len(data)%aes.BlockSize != 0 {
+			return nil, errCorrupted
+		}
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, err
+		}
+		plaintext := make([]byte, len(data))
+		cipher.NewCBCDecrypter(block, h.iv).CryptBlocks(plaintext, data)
+
+		padding := int(plaintext[len(plaintext)-1])
+		if padding == 0 || padding > aes.BlockSize {
+			return nil, errCorrupted
+		}
+		for _, b := range plaintext[len(plaintext)-padding:] {
+			if int(b) != padding {
+				return nil, errCorrupted
+			}
+		}
+		return plaintext[:len(plaintext)-padding], nil
+	case cipherChaCha20:
+		stream, err := chacha20.NewUnauthenticatedCipher(key, h.iv)
+		if err != nil {
+			return nil, err
+		}
+		plaintext := make([]byte, len(data))
+		stream.XORKeyStream(plaintext, data)
+		return plaintext, nil
+	}
+	return nil, errors.New("kdbx: cifra não suportada")
+}
+
+func encryptPayload(h *header, key, data []byte) ([]byte, error) {
+	switch h.cipherID {
+	case cipherAES256:
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, err
+		}
+		padding := aes.BlockSize - len(data)%aes.BlockSize
+		ciphertext := make([]byte, len(data)+padding)
+		copy(ciphertext, data)
+		for i := len(data); i < len(ciphertext); i++ {
+			ciphertext[i] = byte(padding)
+		}
+		cipher.NewCBCEncrypter(block, h.iv).CryptBlocks(ciphertext, ciphertext)
+		return ciphertext, nil
+	case cipherChaCha20:
+		stream, err := chacha20.NewUnauthenticatedCipher(key, h.iv)
+		if err != nil {
+			return nil, err
+		}
+		ciphertext := make([]byte, len(data))
+		stream.XORKeyStream(ciphertext, data)
+		return ciphertext, nil
+	}
+	return nil, errors.New("kdbx: cifra não suportada")
+}
+
+func newInnerStream(id uint32, key []byte) (cipher.Stream, error) {
+	switch id {
+	case streamChaCha20:
+		sum := sha512.Sum512(key)
+		return chacha20.NewUnauthenticatedCipher(sum[:32], sum[32:44])
+	case streamSalsa20:
+		s := &salsa20Stream{key: sha256.Sum256(key), pos: 64}
+		copy(s.counter[:8], salsa20Nonce[:])
+		return s, nil
+	}
+	return nil, errors.New("kdbx: gerador de fluxo interno não suportado")
+}
+
+// salsa20Stream mantém o estado do Salsa20 entre valores protegidos, já que
+// o pacote salsa20 só cifra mensagens inteiras.
+type salsa20Stream struct {
+	key     [32]byte
+	counter [16]byte
+	buf     [64]byte
+	pos     int
+}
+
+func (s *salsa20Stream) XORKeyStream(dst, src []byte) {
+	for i := range src {
+		if s.pos == len(s.buf) {
+			var zero [64]byte
+			salsa.XORKeyStream(s.buf[:], zero[:], &s.counter, &s.key)
+			for j := 8; j < 16; j++ {
+				s.counter[j]++
+				if s.counter[j] != 0 {
+					break
+				}
+			}
+			s.pos = 0
+		}
+		dst[i] = src[i] ^ s.buf[s.pos]
+		s.pos++
+	}
+}
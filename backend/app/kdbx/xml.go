@@ -0,0 +1,404 @@
+package kdbx
+
+import (
+	"bytes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/xml"
+	"errors"
+	"io"
+	"strings"
+	"time"
+)
+
+const generator = "Password-Mobile-App"
+
+// Segundos entre 0001-01-01 e a época Unix; o KDBX 4 grava datas como
+// segundos desde o ano 1 em base64.
+const kdbxEpochOffset = 62135596800
+
+type xmlFile struct {
+	XMLName xml.Name `xml:"KeePassFile"`
+	Meta    xmlMeta  `xml:"Meta"`
+	Root    xmlRoot  `xml:"Root"`
+}
+
+type xmlMeta struct {
+	Generator         string               `xml:"Generator"`
+	DatabaseName      string               `xml:"DatabaseName"`
+	MemoryProtection  *xmlMemoryProtection `xml:"MemoryProtection,omitempty"`
+	RecycleBinEnabled string               `xml:"RecycleBinEnabled,omitempty"`
+	RecycleBinUUID    string               `xml:"RecycleBinUUID,omitempty"`
+}
+
+type xmlMemoryProtection struct {
+	ProtectTitle    string `xml:"ProtectTitle"`
+	ProtectUserName string `xml:"ProtectUserName"`
+	ProtectPassword string `xml:"ProtectPassword"`
+	ProtectURL      string `xml:"ProtectURL"`
+	ProtectNotes    string `xml:"ProtectNotes"`
+}
+
+type xmlRoot struct {
+	Group          xmlGroup `xml:"Group"`
+	DeletedObjects struct{} `xml:"DeletedObjects"`
+}
+
+type xmlGroup struct {
+	UUID    string     `xml:"UUID"`
+	Name    string     `xml:"Name"`
+	Times   *xmlTimes  `xml:"Times,omitempty"`
+	Entries []xmlEntry `xml:"Entry"`
+	Groups  []xmlGroup `xml:"Group"`
+}
+
+type xmlEntry struct {
+	UUID    string      `xml:"UUID"`
+	Tags    string      `xml:"Tags,omitempty"`
+	Times   *xmlTimes   `xml:"Times,omitempty"`
+	Strings []xmlString `xml:"String"`
+}
+
+type xmlString struct {
+	Key   string   `xml:"Key"`
+	Value xmlValue `xml:"Value"`
+}
+
+type xmlValue struct {
+	Protected string `xml:"Protected,attr,omitempty"`
+	Text      string `xml:",chardata"`
+}
+
+type xmlTimes struct {
+	CreationTime         string `xml:"CreationTime"`
+	LastModificationTime string `xml:"LastModificationTime"`
+	LastAccessTime       string `xml:"LastAccessTime"`
+	ExpiryTime           string `xml:"ExpiryTime"`
+	Expires              string `xml:"Expires"`
+	UsageCount           int    `xml:"UsageCount"`
+	LocationChanged      string `xml:"LocationChanged"`
+}
+
+func parseXML(data []byte, stream cipher.Stream) (*Database, error) {
+	plain, err := unprotect(data, stream)
+	if err != nil {
+		return nil, err
+	}
+
+	var file xmlFile
+	if err := xml.Unmarshal(plain, &file); err != nil {
+		return nil, errors.New("kdbx: XML inválido")
+	}
+
+	recycleBin := ""
+	if file.Meta.RecycleBinEnabled != "False" && !isZeroUUID(file.Meta.RecycleBinUUID) {
+		recycleBin = file.Meta.RecycleBinUUID
+	}
+
+	db := &Database{Nome: file.Meta.DatabaseName}
+	var walk func(group *xmlGroup, path string)
+	walk = func(group *xmlGroup, path string) {
+		for _, entry := range group.Entries {
+			db.Entradas = append(db.Entradas, entry.toEntry(path))
+		}
+		for i := range group.Groups {
+			child := &group.Groups[i]
+			if recycleBin != "" && child.UUID == recycleBin {
+				continue
+			}
+			childPath := child.Name
+			if path != "" {
+				childPath = path + "/" + child.Name
+			}
+			walk(child, childPath)
+		}
+	}
+	// O grupo raiz leva o nome do banco e não entra no caminho das entradas.
+	walk(&file.Root.Group, "")
+
+	return db, nil
+}
+
+func (e *xmlEntry) toEntry(path string) Entry {
+	entry := Entry{Grupo: path}
+	// O KeePass grava as tags separadas por ";", mas também aceita ",".
+	for _, tag := range strings.FieldsFunc(e.Tags, func(r rune) bool { return r == ';' || r == ',' }) {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			entry.Tags = append(entry.Tags, tag)
+		}
+	}
+	for _, s := range e.Strings {
+		switch s.Key {
+		case "Title":
+			entry.Titulo = s.Value.Text
+		case "UserName":
+			entry.Usuario = s.Value.Text
+		case "Password":
+			entry.Senha = s.Value.Text
+		case "URL":
+			entry.URL = s.Value.Text
+		case "Notes":
+			entry.Notas = s.Value.Text
+		default:
+			entry.Campos = append(entry.Campos, Field{
+				Nome:      s.Key,
+				Valor:     s.Value.Text,
+				Protegido: strings.EqualFold(s.Value.Protected, "true"),
+			})
+		}
+	}
+	if e.Times != nil {
+		entry.CriadoEm = parseTime(e.Times.CreationTime)
+		entry.AtualizadoEm = parseTime(e.Times.LastModificationTime)
+	}
+	return entry
+}
+
+// unprotect decifra os valores marcados com Protected="True". O fluxo interno
+// é contínuo, então os valores precisam ser visitados na ordem do documento,
+// inclusive os que ficam no histórico das entradas e que depois são ignorados.
+// A marca continua no valor decifrado para que os campos protegidos sejam
+// reconhecidos na leitura.
+func unprotect(data []byte, stream cipher.Stream) ([]byte, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	var out bytes.Buffer
+	encoder := xml.NewEncoder(&out)
+
+	protected := false
+	var value []byte
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, errors.New("kdbx: XML inválido")
+		}
+
+		switch t := token.(type) {
+		case xml.ProcInst, xml.Directive, xml.Comment:
+			continue
+		case xml.StartElement:
+			if t.Name.Local == "Value" && isProtected(t.Attr) {
+				protected = true
+				value = value[:0]
+				token = xml.StartElement{Name: t.Name, Attr: []xml.Attr{{Name: xml.Name{Local: "Protected"}, Value: "True"}}}
+			}
+		case xml.CharData:
+			if protected {
+				value = append(value, t...)
+				continue
+			}
+		case xml.EndElement:
+			if protected {
+				plain, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(value)))
+				if err != nil {
+					return nil, errors.New("kdbx: valor protegido inválido")
+				}
+				stream.XORKeyStream(plain, plain)
+				if err := encoder.EncodeToken(xml.CharData(plain)); err != nil {
+					return nil, err
+				}
+				protected = false
+			}
+		}
+
+		if err := encoder.EncodeToken(token); err != nil {
+			return nil, errors.New("kdbx: XML inválido")
+		}
+	}
+
+	if err := encoder.Flush(); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+func isProtected(attrs []xml.Attr) bool {
+	for _, attr := range attrs {
+		if attr.Name.Local == "Protected" && strings.EqualFold(attr.Value, "true") {
+			return true
+		}
+	}
+	return false
+}
+
+func buildXML(db *Database, stream cipher.Stream, now time.Time) ([]byte, error) {
+	rootUUID, err := newUUID()
+	if err != nil {
+		return nil, err
+	}
+
+	nome := db.Nome
+	if nome == "" {
+		nome = "Cofre"
+	}
+
+	file := xmlFile{
+		Meta: xmlMeta{
+			Generator:    generator,
+			DatabaseName: nome,
+			MemoryProtection: &xmlMemoryProtection{
+				ProtectTitle:    "False",
+				ProtectUserName: "False",
+				ProtectPassword: "True",
+				ProtectURL:      "False",
+				ProtectNotes:    "False",
+			},
+			RecycleBinEnabled: "False",
+		},
+		Root: xmlRoot{
+			Group: xmlGroup{UUID: rootUUID, Name: nome, Times: newTimes(now, now)},
+		},
+	}
+
+	for _, entry := range db.Entradas {
+		group, err := file.Root.Group.path(entry.Grupo, now)
+		if err != nil {
+			return nil, err
+		}
+
+		id, err := newUUID()
+		if err != nil {
+			return nil, err
+		}
+
+		criado, atualizado := entry.CriadoEm, entry.AtualizadoEm
+		if criado.IsZero() {
+			criado = now
+		}
+		if atualizado.IsZero() {
+			atualizado = criado
+		}
+
+		strs := []xmlString{
+			{Key: "Title", Value: xmlValue{Text: entry.Titulo}},
+			{Key: "UserName", Value: xmlValue{Text: entry.Usuario}},
+			{Key: "Password", Value: xmlValue{Protected: "True", Text: entry.Senha}},
+			{Key: "URL", Value: xmlValue{Text: entry.URL}},
+			{Key: "Notes", Value: xmlValue{Text: entry.Notas}},
+		}
+		for _, field := range entry.Campos {
+			value := xmlValue{Text: field.Valor}
+			if field.Protegido {
+				value.Protected = "True"
+			}
+			strs = append(strs, xmlString{Key: field.Nome, Value: value})
+		}
+
+		group.Entries = append(group.Entries, xmlEntry{
+			UUID:    id,
+			Tags:    strings.Join(entry.Tags, ";"),
+			Times:   newTimes(criado, atualizado),
+			Strings: strs,
+		})
+	}
+
+	// Cifra os valores protegidos na mesma ordem em que serão serializados.
+	file.Root.Group.protect(stream)
+
+	data, err := xml.MarshalIndent(file, "", "\t")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), data...), nil
+}
+
+// path devolve o subgrupo indicado por um caminho separado por "/", criando
+// os grupos que ainda não existem.
+func (g *xmlGroup) path(path string, now time.Time) (*xmlGroup, error) {
+	group := g
+	for _, name := range strings.Split(path, "/") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+
+		var next *xmlGroup
+		for i := range group.Groups {
+			if group.Groups[i].Name == name {
+				next = &group.Groups[i]
+				break
+			}
+		}
+		if next == nil {
+			id, err := newUUID()
+			if err != nil {
+				return nil, err
+			}
+			group.Groups = append(group.Groups, xmlGroup{UUID: id, Name: name, Times: newTimes(now, now)})
+			next = &group.Groups[len(group.Groups)-1]
+		}
+		group = next
+	}
+	return group, nil
+}
+
+func (g *xmlGroup) protect(stream cipher.Stream) {
+	for i := range g.Entries {
+		for j := range g.Entries[i].Strings {
+			value := &g.Entries[i].Strings[j].Value
+			if value.Protected != "True" {
+				continue
+			}
+			data := []byte(value.Text)
+			stream.XORKeyStream(data, data)
+			value.Text = base64.StdEncoding.EncodeToString(data)
+		}
+	}
+	for i := range g.Groups {
+		g.Groups[i].protect(stream)
+	}
+}
+
+func newUUID() (string, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(id), nil
+}
+
+func isZeroUUID(id string) bool {
+	data, err := base64.StdEncoding.DecodeString(id)
+	if err != nil || len(data) == 0 {
+		return true
+	}
+	for _, b := range data {
+		if b != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func newTimes(criado, atualizado time.Time) *xmlTimes {
+	return &xmlTimes{
+		CreationTime:         formatTime(criado),
+		LastModificationTime: formatTime(atualizado),
+		LastAccessTime:       formatTime(atualizado),
+		ExpiryTime:           formatTime(atualizado),
+		Expires:              "False",
+		LocationChanged:      formatTime(criado),
+	}
+}
+
+func formatTime(t time.Time) string {
+	var data [8]byte
+	binary.LittleEndian.PutUint64(data[:], uint64(t.Unix()+kdbxEpochOffset))
+	return base64.StdEncoding.EncodeToString(data[:])
+}
+
+// parseTime aceita o formato binário do KDBX 4 e, por compatibilidade com
+// arquivos convertidos do KDBX 3, datas ISO 8601.
+func parseTime(value string) time.Time {
+	value = strings.TrimSpace(value)
+	if data, err := base64.StdEncoding.DecodeString(value); err == nil && len(data) == 8 {
+		return time.Unix(int64(binary.LittleEndian.Uint64(data))-kdbxEpochOffset, 0).UTC()
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t
+	}
+	return time.Time{}
+}
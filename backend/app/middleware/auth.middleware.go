@@ -1,16 +1,13 @@
 package middleware
 
 import (
-	"os"
 	"strings"
 
+	"github.com/Vicente/Password-Mobile-App/backend/app/services"
 	"github.com/gofiber/fiber/v2"
-	"github.com/golang-jwt/jwt/v4"
 )
 
-var jwtSecret = os.Getenv("JWT_SECRET")
-
-func AuthMiddleware() fiber.Handler {
+func AuthMiddleware(sessionService *services.SessionService, verificationService *services.VerificationService) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		authHeader := c.Get("Authorization")
 		if authHeader == "" {
@@ -28,48 +25,28 @@ func AuthMiddleware() fiber.Handler {
 			})
 		}
 
-		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, fiber.NewError(fiber.StatusUnauthorized, "Método de assinatura inválido")
-			}
-			return []byte(jwtSecret), nil
-		})
-
+		claims, err := sessionService.ParseAccessToken(tokenString)
 		if err != nil {
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				"error": "Token inválido",
-			})
-		}
-
-		if !token.Valid {
 			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
 				"error": "Token expirado ou inválido",
 			})
 		}
 
-		claims, ok := token.Claims.(jwt.MapClaims)
-		if !ok {
+		if err := sessionService.ValidateSession(claims.SessionID, claims.UserID); err != nil {
 			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				"error": "Claims do token inválidos",
+				"error": "Sessão encerrada, faça login novamente",
 			})
 		}
 
-		userID, ok := claims["id"].(float64)
-		if !ok {
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				"error": "ID do usuário não encontrado no token",
-			})
-		}
-
-		userEmail, ok := claims["email"].(string)
-		if !ok {
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				"error": "Email do usuário não encontrado no token",
+		if err := verificationService.CheckAccess(claims.UserID, c.Method(), c.Path()); err != nil {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"error": err.Error(),
 			})
 		}
 
-		c.Locals("userID", uint(userID))
-		c.Locals("userEmail", userEmail)
+		c.Locals("userID", claims.UserID)
+		c.Locals("userEmail", claims.Email)
+		c.Locals("sessionID", claims.SessionID)
 
 		return c.Next()
 	}
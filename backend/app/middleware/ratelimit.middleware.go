@@ -0,0 +1,30 @@
+package middleware
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/Vicente/Password-Mobile-App/backend/app/ratelimit"
+	"github.com/gofiber/fiber/v2"
+)
+
+func RateLimitMiddleware(limiter *ratelimit.Limiter, prefix string, rule ratelimit.Rule) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		err := limiter.Allow(prefix+":"+c.IP(), rule)
+		if err == nil {
+			return c.Next()
+		}
+
+		var limitErr *ratelimit.Error
+		if errors.As(err, &limitErr) {
+			c.Set(fiber.HeaderRetryAfter, strconv.Itoa(limitErr.RetryAfterSeconds()))
+			return c.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{
+				"error": "Muitas requisições, tente novamente mais tarde",
+			})
+		}
+
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+}
@@ -0,0 +1,352 @@
+// Package otp implementa senhas de uso único HOTP (RFC 4226) e TOTP (RFC 6238).
+package otp
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Algorithm string
+
+const (
+	SHA1   Algorithm = "SHA1"
+	SHA256 Algorithm = "SHA256"
+	SHA512 Algorithm = "SHA512"
+)
+
+const (
+	TypeTOTP = "totp"
+	TypeHOTP = "hotp"
+)
+
+const (
+	DefaultDigits = 6
+	DefaultPeriod = 30
+	MinDigits     = 6
+	MaxDigits     = 8
+	SteamDigits   = 5
+	MaxPeriod     = 3600
+)
+
+// steamAlphabet são os caracteres dos códigos do Steam Guard.
+const steamAlphabet = "23456789BCDFGHJKMNPQRTVWXY"
+
+var b32 = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// Key descreve um autenticador. Type vazio é TOTP; Counter só vale para HOTP.
+// Steam gera os códigos de 5 caracteres do Steam Guard.
+type Key struct {
+	Secret    []byte
+	Algorithm Algorithm
+	Digits    int
+	Period    int
+	Issuer    string
+	Account   string
+	Type      string
+	Counter   uint64
+	Steam     bool
+}
+
+func GenerateSecret(size int) ([]byte, error) {
+	secret := make([]byte, size)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, err
+	}
+	return secret, nil
+}
+
+func EncodeSecret(secret []byte) string {
+	return b32.EncodeToString(secret)
+}
+
+func DecodeSecret(value string) ([]byte, error) {
+	value = strings.ToUpper(strings.NewReplacer(" ", "", "-", "").Replace(strings.TrimSpace(value)))
+	value = strings.TrimRight(value, "=")
+	if value == "" {
+		return nil, errors.New("otp: segredo vazio")
+	}
+
+	secret, err := b32.DecodeString(value)
+	if err != nil {
+		return nil, errors.New("otp: segredo base32 inválido")
+	}
+	return secret, nil
+}
+
+func (a Algorithm) hash() (func() hash.Hash, error) {
+	switch a {
+	case SHA1, "":
+		return sha1.New, nil
+	case SHA256:
+		return sha256.New, nil
+	case SHA512:
+		return sha512.New, nil
+	}
+	return nil, fmt.Errorf("otp: algoritmo não suportado: %s", a)
+}
+
+func HOTP(secret []byte, counter uint64, digits int, algorithm Algorithm) (string, error) {
+	if digits < MinDigits || digits > MaxDigits {
+		return "", fmt.Errorf("otp: quantidade de dígitos deve estar entre %d e %d", MinDigits, MaxDigits)
+	}
+
+	code, err := truncatedHash(secret, counter, algorithm)
+	if err != nil {
+		return "", err
+	}
+
+	mod := uint32(1)
+	for i := 0; i < digits; i++ {
+		mod *= 10
+	}
+
+	return fmt.Sprintf("%0*d", digits, code%mod), nil
+}
+
+func truncatedHash(secret []byte, counter uint64, algorithm Algorithm) (uint32, error) {
+	newHash, err := algorithm.hash()
+	if err != nil {
+		return 0, err
+	}
+
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], counter)
+
+	mac := hmac.New(newHash, secret)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	return binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff, nil
+}
+
+func TimeStep(t time.Time, period int) int64 {
+	if period <= 0 {
+		period = DefaultPeriod
+	}
+	return t.Unix() / int64(period)
+}
+
+func TOTP(secret []byte, t time.Time, period int, digits int, algorithm Algorithm) (string, error) {
+	return HOTP(secret, uint64(TimeStep(t, period)), digits, algorithm)
+}
+
+// Steam gera o código do Steam Guard: um TOTP com SHA1 e período de 30 s,
+// escrito com 5 caracteres do alfabeto do Steam.
+func Steam(secret []byte, t time.Time) (string, error) {
+	code, err := truncatedHash(secret, uint64(TimeStep(t, DefaultPeriod)), SHA1)
+	if err != nil {
+		return "", err
+	}
+
+	size := uint32(len(steamAlphabet))
+	out := make([]byte, SteamDigits)
+	for i := range out {
+		out[i] = steamAlphabet[code%size]
+		code /= size
+	}
+	return string(out), nil
+}
+
+// Code gera o código da chave no instante t (TOTP e Steam) ou no contador
+// atual (HOTP).
+func (k Key) Code(t time.Time) (string, error) {
+	digits := k.Digits
+	if digits == 0 {
+		digits = DefaultDigits
+	}
+
+	switch {
+	case k.Steam:
+		return Steam(k.Secret, t)
+	case k.Type == TypeHOTP:
+		return HOTP(k.Secret, k.Counter, digits, k.Algorithm)
+	}
+	return TOTP(k.Secret, t, k.Period, digits, k.Algorithm)
+}
+
+// Remaining devolve quantos segundos faltam para o código TOTP do instante t
+// expirar.
+func (k Key) Remaining(t time.Time) int {
+	period := int64(k.Period)
+	if period <= 0 {
+		period = DefaultPeriod
+	}
+	return int(period - t.Unix()%period)
+}
+
+// Parse lê um autenticador em qualquer dos formatos comuns: segredo em
+// base32, URI otpauth:// (TOTP ou HOTP, com encoder=steam para o Steam Guard)
+// ou URI steam://.
+func Parse(value string) (Key, error) {
+	value = strings.TrimSpace(value)
+	lower := strings.ToLower(value)
+
+	switch {
+	case strings.HasPrefix(lower, "otpauth://"):
+		return parseURI(value)
+	case strings.HasPrefix(lower, "steam://"):
+		secret, err := DecodeSecret(value[len("steam://"):])
+		if err != nil {
+			return Key{}, err
+		}
+		return Key{Secret: secret, Algorithm: SHA1, Digits: SteamDigits, Period: DefaultPeriod, Type: TypeTOTP, Steam: true}, nil
+	}
+
+	secret, err := DecodeSecret(value)
+	if err != nil {
+		return Key{}, err
+	}
+	return Key{Secret: secret, Algorithm: SHA1, Digits: DefaultDigits, Period: DefaultPeriod, Type: TypeTOTP}, nil
+}
+
+func parseURI(value string) (Key, error) {
+	u, err := url.Parse(value)
+	if err != nil {
+		return Key{}, errors.New("otp: URI inválida")
+	}
+
+	key := Key{Algorithm: SHA1, Digits: DefaultDigits, Period: DefaultPeriod, Type: strings.ToLower(u.Host)}
+	if key.Type != TypeTOTP && key.Type != TypeHOTP {
+		return Key{}, errors.New("otp: o tipo da URI deve ser totp ou hotp")
+	}
+
+	label := strings.TrimPrefix(u.Path, "/")
+	if issuer, account, ok := strings.Cut(label, ":"); ok {
+		key.Issuer, key.Account = strings.TrimSpace(issuer), strings.TrimSpace(account)
+	} else {
+		key.Account = strings.TrimSpace(label)
+	}
+
+	query := u.Query()
+	if issuer := query.Get("issuer"); issuer != "" {
+		key.Issuer = issuer
+	}
+
+	if key.Secret, err = DecodeSecret(query.Get("secret")); err != nil {
+		return Key{}, err
+	}
+
+	if algorithm := query.Get("algorithm"); algorithm != "" {
+		key.Algorithm = Algorithm(strings.ToUpper(algorithm))
+		if _, err := key.Algorithm.hash(); err != nil {
+			return Key{}, err
+		}
+	}
+
+	// Os códigos do Steam têm tamanho, algoritmo e período fixos.
+	if strings.EqualFold(query.Get("encoder"), "steam") {
+		if key.Type != TypeTOTP {
+			return Key{}, errors.New("otp: códigos do Steam só existem em TOTP")
+		}
+		key.Steam = true
+		key.Digits = SteamDigits
+		key.Algorithm = SHA1
+		return key, nil
+	}
+
+	if digits := query.Get("digits"); digits != "" {
+		n, err := strconv.Atoi(digits)
+		if err != nil || n < MinDigits || n > MaxDigits {
+			return Key{}, fmt.Errorf("otp: quantidade de dígitos deve estar entre %d e %d", MinDigits, MaxDigits)
+		}
+		key.Digits = n
+	}
+
+	if period := query.Get("period"); period != "" {
+		n, err := strconv.Atoi(period)
+		if err != nil || n <= 0 || n > MaxPeriod {
+			return Key{}, errors.New("otp: período inválido")
+		}
+		key.Period = n
+	}
+
+	if key.Type == TypeHOTP {
+		if counter := query.Get("counter"); counter != "" {
+			if key.Counter, err = strconv.ParseUint(counter, 10, 64); err != nil {
+				return Key{}, errors.New("otp: contador inválido")
+			}
+		}
+	}
+
+	return key, nil
+}
+
+// Validate confere o código aceitando até skew passos de diferença de relógio
+// e devolve o passo em que o código foi encontrado, para proteção contra replay.
+func Validate(key Key, code string, t time.Time, skew int) (int64, bool) {
+	code = strings.TrimSpace(code)
+	digits := key.Digits
+	if digits == 0 {
+		digits = DefaultDigits
+	}
+	if len(code) != digits {
+		return 0, false
+	}
+
+	step := TimeStep(t, key.Period)
+	for i := -skew; i <= skew; i++ {
+		candidate, err := HOTP(key.Secret, uint64(step+int64(i)), digits, key.Algorithm)
+		if err != nil {
+			return 0, false
+		}
+		if subtle.ConstantTimeCompare([]byte(candidate), []byte(code)) == 1 {
+			return step + int64(i), true
+		}
+	}
+
+	return 0, false
+}
+
+func (k Key) URI() string {
+	label := k.Account
+	if k.Issuer != "" {
+		label = k.Issuer + ":" + k.Account
+	}
+
+	host := k.Type
+	if host == "" {
+		host = TypeTOTP
+	}
+
+	params := url.Values{}
+	params.Set("secret", EncodeSecret(k.Secret))
+	if k.Issuer != "" {
+		params.Set("issuer", k.Issuer)
+	}
+	if k.Algorithm != "" {
+		params.Set("algorithm", string(k.Algorithm))
+	}
+	if k.Digits != 0 {
+		params.Set("digits", strconv.Itoa(k.Digits))
+	}
+	if host == TypeHOTP {
+		params.Set("counter", strconv.FormatUint(k.Counter, 10))
+	} else if k.Period != 0 {
+		params.Set("period", strconv.Itoa(k.Period))
+	}
+	if k.Steam {
+		params.Set("encoder", "steam")
+	}
+
+	u := url.URL{
+		Scheme:   "otpauth",
+		Host:     host,
+		Path:     "/" + label,
+		RawQuery: params.Encode(),
+	}
+	return u.String()
+}
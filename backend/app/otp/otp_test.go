@@ -0,0 +1,187 @@
+package otp
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// Segredos da RFC 6238, apêndice B: o mesmo texto repetido até o tamanho do
+// hash de cada algoritmo. O de SHA1 é também o da RFC 4226.
+var rfcSecrets = map[Algorithm][]byte{
+	SHA1:   []byte("12345678901234567890"),
+	SHA256: []byte("12345678901234567890123456789012"),
+	SHA512: []byte("1234567890123456789012345678901234567890123456789012345678901234"),
+}
+
+// RFC 4226, apêndice D.
+func TestHOTPRFC4226(t *testing.T) {
+	codes := []string{"755224", "287082", "359152", "969429", "338314", "254676", "287922", "162583", "399871", "520489"}
+
+	for counter, want := range codes {
+		got, err := HOTP(rfcSecrets[SHA1], uint64(counter), 6, SHA1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != want {
+			t.Errorf("contador %d: %s, esperado %s", counter, got, want)
+		}
+	}
+}
+
+// RFC 6238, apêndice B. A tabela traz códigos de 8 dígitos; os de 6 são os
+// mesmos sem os dois primeiros.
+func TestTOTPRFC6238(t *testing.T) {
+	tests := []struct {
+		unix  int64
+		codes map[Algorithm]string
+	}{
+		{59, map[Algorithm]string{SHA1: "94287082", SHA256: "46119246", SHA512: "90693936"}},
+		{1111111109, map[Algorithm]string{SHA1: "07081804", SHA256: "68084774", SHA512: "25091201"}},
+		{1111111111, map[Algorithm]string{SHA1: "14050471", SHA256: "67062674", SHA512: "99943326"}},
+		{1234567890, map[Algorithm]string{SHA1: "89005924", SHA256: "91819424", SHA512: "93441116"}},
+		{2000000000, map[Algorithm]string{SHA1: "69279037", SHA256: "90698825", SHA512: "38618901"}},
+		{20000000000, map[Algorithm]string{SHA1: "65353130", SHA256: "77737706", SHA512: "47863826"}},
+	}
+
+	for _, tt := range tests {
+		now := time.Unix(tt.unix, 0).UTC()
+		for algorithm, want := range tt.codes {
+			for _, digits := range []int{8, 6} {
+				got, err := TOTP(rfcSecrets[algorithm], now, DefaultPeriod, digits, algorithm)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if expected := want[8-digits:]; got != expected {
+					t.Errorf("%s %d dígitos em %d: %s, esperado %s", algorithm, digits, tt.unix, got, expected)
+				}
+			}
+		}
+	}
+}
+
+// Os mesmos vetores passando pela URI, como o cofre guarda o segredo.
+func TestKeyCodeFromURI(t *testing.T) {
+	tests := []struct {
+		uri  string
+		unix int64
+		want string
+	}{
+		{"otpauth://totp/ACME:joao?secret=GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ&digits=8", 59, "94287082"},
+		{"otpauth://totp/ACME:joao?secret=" + EncodeSecret(rfcSecrets[SHA256]) + "&algorithm=sha256&digits=8", 1111111109, "68084774"},
+		{"otpauth://totp/ACME:joao?secret=" + EncodeSecret(rfcSecrets[SHA512]) + "&algorithm=SHA512", 1234567890, "441116"},
+		{"otpauth://hotp/ACME:joao?secret=GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ&counter=9", 0, "520489"},
+		{"gezd gnbv gy3t qojq gezd gnbv gy3t qojq", 59, "287082"},
+	}
+
+	for _, tt := range tests {
+		key, err := Parse(tt.uri)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.uri, err)
+		}
+		got, err := key.Code(time.Unix(tt.unix, 0))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tt.want {
+			t.Errorf("%s: %s, esperado %s", tt.uri, got, tt.want)
+		}
+	}
+}
+
+// O código do Steam Guard usa o mesmo valor truncado do TOTP SHA1 (aqui o da
+// RFC 6238), escrito com 5 caracteres do alfabeto do Steam.
+func TestSteam(t *testing.T) {
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{59, "PV9M4"},
+		{1111111109, "PY4YB"},
+		{1234567890, "VHHQY"},
+		{2000000000, "9N776"},
+	}
+
+	for _, uri := range []string{
+		"steam://GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ",
+		"otpauth://totp/Steam:joao?secret=GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ&encoder=steam&digits=8&algorithm=SHA512",
+	} {
+		key, err := Parse(uri)
+		if err != nil {
+			t.Fatalf("%s: %v", uri, err)
+		}
+		if !key.Steam || key.Digits != SteamDigits || key.Algorithm != SHA1 {
+			t.Fatalf("%s: chave %+v", uri, key)
+		}
+
+		for _, tt := range tests {
+			got, err := key.Code(time.Unix(tt.unix, 0))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("%s em %d: %s, esperado %s", uri, tt.unix, got, tt.want)
+			}
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	const secret = "secret=GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+	tests := []struct {
+		value string
+		err   string
+	}{
+		{"otpauth://totp/ACME?" + secret + "&digits=5", "dígitos"},
+		{"otpauth://totp/ACME?" + secret + "&digits=9", "dígitos"},
+		{"otpauth://totp/ACME?" + secret + "&digits=seis", "dígitos"},
+		{"otpauth://hotp/ACME?" + secret + "&digits=10", "dígitos"},
+		{"otpauth://totp/ACME?" + secret + "&period=0", "período"},
+		{"otpauth://totp/ACME?" + secret + "&period=-30", "período"},
+		{"otpauth://totp/ACME?" + secret + "&period=3601", "período"},
+		{"otpauth://totp/ACME?" + secret + "&period=30s", "período"},
+		{"otpauth://totp/ACME?" + secret + "&algorithm=MD5", "algoritmo"},
+		{"otpauth://totp/ACME?" + secret + "&algorithm=SHA3-256", "algoritmo"},
+		{"otpauth://hotp/ACME?" + secret + "&counter=-1", "contador"},
+		{"otpauth://hotp/ACME?" + secret + "&encoder=steam", "Steam"},
+		{"otpauth://motp/ACME?" + secret, "tipo"},
+		{"otpauth://totp/ACME?secret=", "vazio"},
+		{"otpauth://totp/ACME?secret=1189", "base32"},
+		{"steam://", "vazio"},
+		{"não é base32!", "base32"},
+	}
+
+	for _, tt := range tests {
+		_, err := Parse(tt.value)
+		if err == nil {
+			t.Errorf("%q aceito", tt.value)
+		} else if !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("%q: erro %q, esperado algo sobre %q", tt.value, err, tt.err)
+		}
+	}
+
+	if _, err := HOTP(rfcSecrets[SHA1], 0, 5, SHA1); err == nil {
+		t.Error("HOTP com 5 dígitos aceito")
+	}
+	if _, err := HOTP(rfcSecrets[SHA1], 0, 6, "MD5"); err == nil {
+		t.Error("HOTP com algoritmo desconhecido aceito")
+	}
+}
+
+func TestURIRoundTrip(t *testing.T) {
+	key, err := Parse("otpauth://hotp/ACME:joao@example.com?secret=GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ&algorithm=SHA256&digits=8&counter=41")
+	if err != nil {
+		t.Fatal(err)
+	}
+	key.Counter++
+
+	parsed, err := Parse(key.URI())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parsed.Type != TypeHOTP || parsed.Counter != 42 || parsed.Digits != 8 || parsed.Algorithm != SHA256 ||
+		parsed.Issuer != "ACME" || parsed.Account != "joao@example.com" || string(parsed.Secret) != string(rfcSecrets[SHA1]) {
+		t.Fatalf("chave relida %+v, URI %s", parsed, key.URI())
+	}
+}
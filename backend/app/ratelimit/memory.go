@@ -0,0 +1,126 @@
+package ratelimit
+
+import (
+	"sync"
+	"time"
+)
+
+type MemoryStore struct {
+	mu     sync.Mutex
+	events map[string][]time.Time
+	locks  map[string]time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		events: make(map[string][]time.Time),
+		locks:  make(map[string]time.Time),
+	}
+}
+
+func (m *MemoryStore) Add(key string, at time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.events[key] = append(m.events[key], at)
+	return nil
+}
+
+func (m *MemoryStore) TryAdd(key string, at time.Time, since time.Time, limit int) (Stats, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stats := m.stats(key, since)
+	if stats.Count >= limit {
+		return stats, false, nil
+	}
+
+	m.events[key] = append(m.events[key], at)
+	return stats, true, nil
+}
+
+func (m *MemoryStore) AddIf(key string, at time.Time, since time.Time, check func(Stats) error) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := check(m.stats(key, since)); err != nil {
+		return err
+	}
+
+	m.events[key] = append(m.events[key], at)
+	return nil
+}
+
+func (m *MemoryStore) Stats(key string, since time.Time) (Stats, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.stats(key, since), nil
+}
+
+func (m *MemoryStore) stats(key string, since time.Time) Stats {
+	events := m.events[key]
+	kept := events[:0]
+	for _, at := range events {
+		if !at.Before(since) {
+			kept = append(kept, at)
+		}
+	}
+	m.events[key] = kept
+
+	stats := Stats{Count: len(kept)}
+	if len(kept) > 0 {
+		stats.Oldest = kept[0]
+		stats.Newest = kept[len(kept)-1]
+	}
+	return stats
+}
+
+func (m *MemoryStore) Reset(key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.events, key)
+	return nil
+}
+
+func (m *MemoryStore) Lock(key string, until time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.locks[key] = until
+	return nil
+}
+
+func (m *MemoryStore) LockedUntil(key string) (time.Time, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.locks[key], nil
+}
+
+func (m *MemoryStore) Prune(before time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for key, events := range m.events {
+		kept := events[:0]
+		for _, at := range events {
+			if !at.Before(before) {
+				kept = append(kept, at)
+			}
+		}
+		if len(kept) == 0 {
+			delete(m.events, key)
+		} else {
+			m.events[key] = kept
+		}
+	}
+
+	for key, until := range m.locks {
+		if until.Before(before) {
+			delete(m.locks, key)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,179 @@
+// Package ratelimit implementa limites por janela deslizante e proteção contra
+// força bruta (atraso progressivo e bloqueio temporário) sobre um Store plugável.
+package ratelimit
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+type Store interface {
+	Add(key string, at time.Time) error
+	// TryAdd registra um evento em at só se houver menos de limit eventos
+	// desde since, de forma atômica entre requisições concorrentes. Stats
+	// descreve os eventos anteriores ao novo.
+	TryAdd(key string, at time.Time, since time.Time, limit int) (Stats, bool, error)
+	// AddIf registra um evento em at se check, chamado com os eventos desde
+	// since, não devolver erro. Como em TryAdd, consulta e registro são
+	// atômicos; o erro de check é devolvido sem registrar nada.
+	AddIf(key string, at time.Time, since time.Time, check func(Stats) error) error
+	Stats(key string, since time.Time) (Stats, error)
+	Reset(key string) error
+	Lock(key string, until time.Time) error
+	LockedUntil(key string) (time.Time, error)
+	Prune(before time.Time) error
+}
+
+type Stats struct {
+	Count  int
+	Oldest time.Time
+	Newest time.Time
+}
+
+type Rule struct {
+	Limit  int
+	Window time.Duration
+}
+
+// FailurePolicy controla tentativas malsucedidas: depois de FreeAttempts falhas
+// na janela cada nova tentativa exige um intervalo que dobra a partir de
+// BaseDelay (até MaxDelay), e ao atingir LockAfter falhas a chave é bloqueada.
+type FailurePolicy struct {
+	Window       time.Duration
+	FreeAttempts int
+	BaseDelay    time.Duration
+	MaxDelay     time.Duration
+	LockAfter    int
+	LockDuration time.Duration
+}
+
+type Error struct {
+	RetryAfter time.Duration
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("muitas tentativas, tente novamente em %d segundos", e.RetryAfterSeconds())
+}
+
+func (e *Error) RetryAfterSeconds() int {
+	seconds := int(math.Ceil(e.RetryAfter.Seconds()))
+	if seconds < 1 {
+		return 1
+	}
+	return seconds
+}
+
+type Limiter struct {
+	Store Store
+	Now   func() time.Time
+}
+
+func NewLimiter(store Store) *Limiter {
+	return &Limiter{
+		Store: store,
+		Now:   time.Now,
+	}
+}
+
+func (l *Limiter) Allow(key string, rule Rule) error {
+	now := l.Now()
+
+	stats, added, err := l.Store.TryAdd(key, now, now.Add(-rule.Window), rule.Limit)
+	if err != nil {
+		return err
+	}
+
+	if !added {
+		return &Error{RetryAfter: stats.Oldest.Add(rule.Window).Sub(now)}
+	}
+
+	return nil
+}
+
+// ReserveFailure libera uma tentativa e já a conta como falha, na mesma
+// operação em que confere o atraso; requisições simultâneas não passam todas
+// pela mesma verificação. Se a tentativa der certo, ResetFailures apaga a
+// reserva; se falhar, ConfirmFailure aplica o bloqueio.
+func (l *Limiter) ReserveFailure(key string, policy FailurePolicy) error {
+	now := l.Now()
+
+	if err := l.checkLock(key, now); err != nil {
+		return err
+	}
+
+	err := l.Store.AddIf(failureKey(key), now, now.Add(-policy.Window), func(stats Stats) error {
+		if delay := policy.delay(stats.Count); delay > 0 {
+			if wait := stats.Newest.Add(delay).Sub(now); wait > 0 {
+				return &Error{RetryAfter: wait}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// O bloqueio pode ter sido aplicado, e as falhas apagadas, entre a
+	// primeira verificação e a reserva.
+	return l.checkLock(key, now)
+}
+
+func (l *Limiter) checkLock(key string, now time.Time) error {
+	lockedUntil, err := l.Store.LockedUntil(lockKey(key))
+	if err != nil {
+		return err
+	}
+	if lockedUntil.After(now) {
+		return &Error{RetryAfter: lockedUntil.Sub(now)}
+	}
+	return nil
+}
+
+// ConfirmFailure mantém a falha reservada por ReserveFailure e bloqueia a
+// chave ao atingir LockAfter falhas na janela.
+func (l *Limiter) ConfirmFailure(key string, policy FailurePolicy) error {
+	now := l.Now()
+
+	stats, err := l.Store.Stats(failureKey(key), now.Add(-policy.Window))
+	if err != nil {
+		return err
+	}
+
+	if policy.LockAfter > 0 && stats.Count >= policy.LockAfter {
+		if err := l.Store.Lock(lockKey(key), now.Add(policy.LockDuration)); err != nil {
+			return err
+		}
+		return l.Store.Reset(failureKey(key))
+	}
+
+	return nil
+}
+
+func (l *Limiter) ResetFailures(key string) error {
+	return l.Store.Reset(failureKey(key))
+}
+
+func (p FailurePolicy) delay(failures int) time.Duration {
+	extra := failures - p.FreeAttempts
+	if extra <= 0 || p.BaseDelay <= 0 {
+		return 0
+	}
+
+	delay := p.BaseDelay
+	for i := 1; i < extra && delay < p.MaxDelay; i++ {
+		delay *= 2
+	}
+	if p.MaxDelay > 0 && delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+	return delay
+}
+
+func failureKey(key string) string {
+	return "fail:" + key
+}
+
+func lockKey(key string) string {
+	return "lock:" + key
+}
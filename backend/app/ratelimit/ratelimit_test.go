@@ -0,0 +1,112 @@
+package ratelimit
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestAllowConcurrent(t *testing.T) {
+	limiter := NewLimiter(NewMemoryStore())
+	rule := Rule{Limit: 10, Window: time.Minute}
+
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		allowed int
+	)
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := limiter.Allow("signin:127.0.0.1", rule)
+			var limitErr *Error
+			if err != nil && !errors.As(err, &limitErr) {
+				t.Error(err)
+				return
+			}
+			if err == nil {
+				mu.Lock()
+				allowed++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if allowed != rule.Limit {
+		t.Fatalf("%d requisições aceitas, esperado %d", allowed, rule.Limit)
+	}
+}
+
+func TestAllowWindow(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	limiter := NewLimiter(NewMemoryStore())
+	limiter.Now = func() time.Time { return now }
+	rule := Rule{Limit: 2, Window: time.Minute}
+
+	for i := 0; i < rule.Limit; i++ {
+		if err := limiter.Allow("k", rule); err != nil {
+			t.Fatalf("requisição %d recusada: %v", i+1, err)
+		}
+		now = now.Add(10 * time.Second)
+	}
+
+	var limitErr *Error
+	if err := limiter.Allow("k", rule); !errors.As(err, &limitErr) {
+		t.Fatalf("requisição acima do limite aceita: %v", err)
+	}
+	if limitErr.RetryAfter != 40*time.Second {
+		t.Fatalf("RetryAfter = %s, esperado 40s", limitErr.RetryAfter)
+	}
+
+	now = now.Add(limitErr.RetryAfter + time.Second)
+	if err := limiter.Allow("k", rule); err != nil {
+		t.Fatalf("requisição recusada depois da janela: %v", err)
+	}
+}
+
+func TestReserveFailureConcurrent(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	policy := FailurePolicy{Window: time.Hour, FreeAttempts: 3, BaseDelay: time.Minute, MaxDelay: time.Hour}
+
+	// Em sequência, com o relógio parado, só as tentativas sem atraso passam.
+	sequential := NewLimiter(NewMemoryStore())
+	sequential.Now = func() time.Time { return now }
+	want := 0
+	for sequential.ReserveFailure("login:a@b.c", policy) == nil {
+		want++
+	}
+
+	limiter := NewLimiter(NewMemoryStore())
+	limiter.Now = func() time.Time { return now }
+
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		allowed int
+	)
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := limiter.ReserveFailure("login:a@b.c", policy)
+			var limitErr *Error
+			if err != nil && !errors.As(err, &limitErr) {
+				t.Error(err)
+				return
+			}
+			if err == nil {
+				mu.Lock()
+				allowed++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if allowed != want {
+		t.Fatalf("%d tentativas simultâneas aceitas, esperado %d", allowed, want)
+	}
+}
@@ -1,13 +1,29 @@
 package routes
 
 import (
+	"time"
+
 	"github.com/Vicente/Password-Mobile-App/backend/app/controllers"
+	"github.com/Vicente/Password-Mobile-App/backend/app/middleware"
+	"github.com/Vicente/Password-Mobile-App/backend/app/ratelimit"
 	"github.com/gofiber/fiber/v2"
 )
 
-func SetupAuthRoutes(app *fiber.App, authController *controllers.AuthController) {
+var (
+	signupRateLimit  = ratelimit.Rule{Limit: 5, Window: time.Hour}
+	signinRateLimit  = ratelimit.Rule{Limit: 10, Window: time.Minute}
+	refreshRateLimit = ratelimit.Rule{Limit: 30, Window: time.Minute}
+)
+
+func SetupAuthRoutes(app *fiber.App, authController *controllers.AuthController, authMiddleware fiber.Handler, limiter *ratelimit.Limiter) {
 	authRoutes := app.Group("/api/auth")
 
-	authRoutes.Post("/signup", authController.Signup)
-	authRoutes.Post("/signin", authController.Login)
+	authRoutes.Post("/signup", middleware.RateLimitMiddleware(limiter, "signup", signupRateLimit), authController.Signup)
+	authRoutes.Post("/signin", middleware.RateLimitMiddleware(limiter, "signin", signinRateLimit), authController.Login)
+	authRoutes.Post("/refresh", middleware.RateLimitMiddleware(limiter, "refresh", refreshRateLimit), authController.Refresh)
+	authRoutes.Post("/logout", authController.Logout)
+
+	authRoutes.Get("/sessions", authMiddleware, authController.ListSessions)
+	authRoutes.Delete("/sessions", authMiddleware, authController.RevokeOtherSessions)
+	authRoutes.Delete("/sessions/:id", authMiddleware, authController.RevokeSession)
 }
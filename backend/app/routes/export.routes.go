@@ -0,0 +1,16 @@
+package routes
+
+import (
+	"github.com/Vicente/Password-Mobile-App/backend/app/controllers"
+	"github.com/gofiber/fiber/v2"
+)
+
+func SetupExportRoutes(app *fiber.App, exportController *controllers.ExportController, authMiddleware fiber.Handler) {
+	exportRoutes := app.Group("/api/me/export")
+
+	exportRoutes.Get("/download", exportController.Download)
+
+	exportRoutes.Post("/", authMiddleware, exportController.RequestExport)
+	exportRoutes.Get("/", authMiddleware, exportController.GetJobs)
+	exportRoutes.Get("/:id", authMiddleware, exportController.GetJob)
+}
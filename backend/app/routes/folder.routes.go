@@ -0,0 +1,15 @@
+package routes
+
+import (
+	"github.com/Vicente/Password-Mobile-App/backend/app/controllers"
+	"github.com/gofiber/fiber/v2"
+)
+
+func SetupFolderRoutes(app *fiber.App, folderController *controllers.FolderController, authMiddleware fiber.Handler) {
+	folderRoutes := app.Group("/api/folders")
+
+	folderRoutes.Get("/", authMiddleware, folderController.ListFolders)
+	folderRoutes.Post("/", authMiddleware, folderController.CreateFolder)
+	folderRoutes.Patch("/:id", authMiddleware, folderController.UpdateFolder)
+	folderRoutes.Delete("/:id", authMiddleware, folderController.DeleteFolder)
+}
@@ -0,0 +1,10 @@
+package routes
+
+import (
+	"github.com/Vicente/Password-Mobile-App/backend/app/controllers"
+	"github.com/gofiber/fiber/v2"
+)
+
+func SetupGeneratorRoutes(app *fiber.App, generatorController *controllers.GeneratorController, authMiddleware fiber.Handler) {
+	app.Post("/api/generate", authMiddleware, generatorController.Generate)
+}
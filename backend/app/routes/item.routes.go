@@ -1,17 +1,37 @@
 package routes
 
 import (
+	"time"
+
 	"github.com/Vicente/Password-Mobile-App/backend/app/controllers"
 	"github.com/Vicente/Password-Mobile-App/backend/app/middleware"
+	"github.com/Vicente/Password-Mobile-App/backend/app/ratelimit"
 	"github.com/gofiber/fiber/v2"
 )
 
-func SetupItemRoutes(app *fiber.App, itemController *controllers.ItemController) {
+// Cada importação KDBX roda a derivação de chave do arquivo enviado.
+var kdbxImportRateLimit = ratelimit.Rule{Limit: 10, Window: 15 * time.Minute}
+
+func SetupItemRoutes(app *fiber.App, itemController *controllers.ItemController, authMiddleware fiber.Handler, limiter *ratelimit.Limiter) {
 	itemRoutes := app.Group("/api")
 
-	itemRoutes.Use(middleware.AuthMiddleware())
+	itemRoutes.Post("/item", authMiddleware, itemController.CreateItem)
+	itemRoutes.Get("/items", authMiddleware, itemController.GetItemsByUser)
+	itemRoutes.Post("/items/import", authMiddleware, itemController.ImportItems)
+	itemRoutes.Post("/items/import/kdbx", authMiddleware, middleware.RateLimitMiddleware(limiter, "kdbx", kdbxImportRateLimit), itemController.ImportKDBX)
+	itemRoutes.Post("/items/export/kdbx", authMiddleware, itemController.ExportKDBX)
+	itemRoutes.Post("/items/export", authMiddleware, itemController.ExportBackup)
+	itemRoutes.Post("/items/restore", authMiddleware, itemController.RestoreBackup)
+	itemRoutes.Get("/item/:id", authMiddleware, itemController.GetItem)
+	itemRoutes.Put("/item/:id", authMiddleware, itemController.ReplaceItem)
+	itemRoutes.Patch("/item/:id", authMiddleware, itemController.PatchItem)
+	itemRoutes.Delete("/item/:id", authMiddleware, itemController.DeleteItem)
+	itemRoutes.Get("/item/:id/totp", authMiddleware, itemController.GetItemTOTP)
+	itemRoutes.Post("/item/:id/hotp", authMiddleware, itemController.NextItemHOTP)
+	itemRoutes.Get("/item/:id/history", authMiddleware, itemController.GetItemHistory)
+	itemRoutes.Post("/item/:id/restore/:version", authMiddleware, itemController.RestoreItemVersion)
 
-	itemRoutes.Post("/item", itemController.CreateItem)
-	itemRoutes.Get("/items", itemController.GetItemsByUser)
-	itemRoutes.Delete("/item/:id", itemController.DeleteItem)
+	itemRoutes.Get("/trash", authMiddleware, itemController.GetTrash)
+	itemRoutes.Post("/trash/:id/restore", authMiddleware, itemController.RestoreFromTrash)
+	itemRoutes.Delete("/trash/:id", authMiddleware, itemController.DeleteFromTrash)
 }
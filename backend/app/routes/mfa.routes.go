@@ -0,0 +1,24 @@
+package routes
+
+import (
+	"time"
+
+	"github.com/Vicente/Password-Mobile-App/backend/app/controllers"
+	"github.com/Vicente/Password-Mobile-App/backend/app/middleware"
+	"github.com/Vicente/Password-Mobile-App/backend/app/ratelimit"
+	"github.com/gofiber/fiber/v2"
+)
+
+var mfaVerifyRateLimit = ratelimit.Rule{Limit: 10, Window: time.Minute}
+
+func SetupMFARoutes(app *fiber.App, mfaController *controllers.MFAController, authMiddleware fiber.Handler, limiter *ratelimit.Limiter) {
+	mfaRoutes := app.Group("/api/auth/mfa")
+
+	mfaRoutes.Post("/verify", middleware.RateLimitMiddleware(limiter, "mfa", mfaVerifyRateLimit), mfaController.Verify)
+
+	mfaRoutes.Get("/", authMiddleware, mfaController.Status)
+	mfaRoutes.Post("/setup", authMiddleware, mfaController.Setup)
+	mfaRoutes.Post("/confirm", authMiddleware, mfaController.Confirm)
+	mfaRoutes.Post("/disable", authMiddleware, mfaController.Disable)
+	mfaRoutes.Post("/recovery-codes", authMiddleware, mfaController.RegenerateRecoveryCodes)
+}
@@ -0,0 +1,23 @@
+package routes
+
+import (
+	"time"
+
+	"github.com/Vicente/Password-Mobile-App/backend/app/controllers"
+	"github.com/Vicente/Password-Mobile-App/backend/app/middleware"
+	"github.com/Vicente/Password-Mobile-App/backend/app/ratelimit"
+	"github.com/gofiber/fiber/v2"
+)
+
+var (
+	forgotPasswordRateLimit = ratelimit.Rule{Limit: 5, Window: time.Hour}
+	resetPasswordRateLimit  = ratelimit.Rule{Limit: 10, Window: time.Hour}
+)
+
+func SetupPasswordRoutes(app *fiber.App, passwordController *controllers.PasswordController, authMiddleware fiber.Handler, limiter *ratelimit.Limiter) {
+	passwordRoutes := app.Group("/api/auth/password")
+
+	passwordRoutes.Post("/", authMiddleware, passwordController.ChangePassword)
+	passwordRoutes.Post("/forgot", middleware.RateLimitMiddleware(limiter, "forgot", forgotPasswordRateLimit), passwordController.ForgotPassword)
+	passwordRoutes.Post("/reset", middleware.RateLimitMiddleware(limiter, "reset", resetPasswordRateLimit), passwordController.ResetPassword)
+}
@@ -0,0 +1,12 @@
+package routes
+
+import (
+	"github.com/Vicente/Password-Mobile-App/backend/app/controllers"
+	"github.com/gofiber/fiber/v2"
+)
+
+func SetupProfileRoutes(app *fiber.App, profileController *controllers.ProfileController, authMiddleware fiber.Handler) {
+	app.Get("/api/me", authMiddleware, profileController.GetProfile)
+	app.Patch("/api/me", authMiddleware, profileController.UpdateProfile)
+	app.Delete("/api/me", authMiddleware, profileController.DeleteAccount)
+}
@@ -0,0 +1,12 @@
+package routes
+
+import (
+	"github.com/Vicente/Password-Mobile-App/backend/app/controllers"
+	"github.com/gofiber/fiber/v2"
+)
+
+func SetupReportRoutes(app *fiber.App, reportController *controllers.ReportController, authMiddleware fiber.Handler) {
+	reportRoutes := app.Group("/api/report")
+
+	reportRoutes.Get("/health", authMiddleware, reportController.Health)
+}
@@ -0,0 +1,60 @@
+package routes_test
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Vicente/Password-Mobile-App/backend/app/controllers"
+	"github.com/Vicente/Password-Mobile-App/backend/app/ratelimit"
+	"github.com/Vicente/Password-Mobile-App/backend/app/routes"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/recover"
+)
+
+// TestAuthMiddlewareScope garante que o middleware de autenticação roda uma
+// única vez por rota protegida e não alcança caminhos que não existem.
+func TestAuthMiddlewareScope(t *testing.T) {
+	calls := 0
+	authMiddleware := func(c *fiber.Ctx) error {
+		calls++
+		return c.Next()
+	}
+
+	// Os controllers não têm serviços: o que importa é quantas vezes o
+	// middleware roda, e recover evita que um handler derrube o teste.
+	app := fiber.New()
+	app.Use(recover.New())
+	routes.SetupFolderRoutes(app, &controllers.FolderController{}, authMiddleware)
+	routes.SetupTagRoutes(app, &controllers.TagController{}, authMiddleware)
+	routes.SetupReportRoutes(app, &controllers.ReportController{}, authMiddleware)
+	routes.SetupItemRoutes(app, &controllers.ItemController{}, authMiddleware, ratelimit.NewLimiter(ratelimit.NewMemoryStore()))
+	routes.SetupVaultRoutes(app, &controllers.VaultController{}, authMiddleware)
+
+	tests := []struct {
+		method string
+		path   string
+		calls  int
+	}{
+		{"GET", "/api/items", 1},
+		{"GET", "/api/vault", 1},
+		{"POST", "/api/vault/zero-knowledge", 1},
+		{"GET", "/api/folders", 1},
+		{"GET", "/api/report/health", 1},
+		{"GET", "/api/nao-existe", 0},
+		{"GET", "/api/vault/nao-existe", 0},
+	}
+
+	for _, tt := range tests {
+		calls = 0
+		resp, err := app.Test(httptest.NewRequest(tt.method, tt.path, nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tt.calls == 0 && resp.StatusCode != fiber.StatusNotFound {
+			t.Errorf("%s %s: status %d, esperado 404", tt.method, tt.path, resp.StatusCode)
+		}
+		if calls != tt.calls {
+			t.Errorf("%s %s: middleware chamado %d vezes, esperado %d", tt.method, tt.path, calls, tt.calls)
+		}
+	}
+}
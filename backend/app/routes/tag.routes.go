@@ -0,0 +1,15 @@
+package routes
+
+import (
+	"github.com/Vicente/Password-Mobile-App/backend/app/controllers"
+	"github.com/gofiber/fiber/v2"
+)
+
+func SetupTagRoutes(app *fiber.App, tagController *controllers.TagController, authMiddleware fiber.Handler) {
+	tagRoutes := app.Group("/api/tags")
+
+	tagRoutes.Get("/", authMiddleware, tagController.ListTags)
+	tagRoutes.Post("/", authMiddleware, tagController.CreateTag)
+	tagRoutes.Patch("/:id", authMiddleware, tagController.RenameTag)
+	tagRoutes.Delete("/:id", authMiddleware, tagController.DeleteTag)
+}
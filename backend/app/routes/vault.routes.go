@@ -0,0 +1,13 @@
+package routes
+
+import (
+	"github.com/Vicente/Password-Mobile-App/backend/app/controllers"
+	"github.com/gofiber/fiber/v2"
+)
+
+func SetupVaultRoutes(app *fiber.App, vaultController *controllers.VaultController, authMiddleware fiber.Handler) {
+	vaultRoutes := app.Group("/api/vault")
+
+	vaultRoutes.Get("/", authMiddleware, vaultController.GetConfig)
+	vaultRoutes.Post("/zero-knowledge", authMiddleware, vaultController.EnableZeroKnowledge)
+}
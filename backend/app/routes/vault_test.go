@@ -0,0 +1,149 @@
+package routes_test
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/Vicente/Password-Mobile-App/backend/app/controllers"
+	"github.com/Vicente/Password-Mobile-App/backend/app/dal"
+	"github.com/Vicente/Password-Mobile-App/backend/app/ratelimit"
+	"github.com/Vicente/Password-Mobile-App/backend/app/routes"
+	"github.com/Vicente/Password-Mobile-App/backend/app/services"
+	"github.com/Vicente/Password-Mobile-App/backend/app/types"
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// openTestDB conecta ao banco de TEST_DATABASE_URL. Sem a variável o teste é
+// ignorado, já que não há Postgres disponível em todo ambiente.
+func openTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL não definido")
+	}
+
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("conectar ao banco: %v", err)
+	}
+
+	if err := db.AutoMigrate(
+		&types.User{},
+		&types.Item{},
+		&types.Folder{},
+		&types.Tag{},
+		&types.UserKey{},
+		&types.VaultConfig{},
+		&types.ItemHistory{},
+	); err != nil {
+		t.Fatalf("migrar modelos: %v", err)
+	}
+	return db
+}
+
+func TestZeroKnowledgeBlobRoundTrip(t *testing.T) {
+	db := openTestDB(t)
+
+	user := &types.User{
+		Nome:            "Blob",
+		Email:           fmt.Sprintf("blob-%d@example.com", time.Now().UnixNano()),
+		EmailVerificado: true,
+	}
+	if err := db.Create(user).Error; err != nil {
+		t.Fatalf("criar usuário: %v", err)
+	}
+	t.Cleanup(func() {
+		db.Unscoped().Where("user_id = ?", user.ID).Delete(&types.ItemHistory{})
+		db.Unscoped().Where("user_id = ?", user.ID).Delete(&types.Item{})
+		db.Unscoped().Where("user_id = ?", user.ID).Delete(&types.VaultConfig{})
+		db.Unscoped().Delete(user)
+	})
+
+	masterKey, err := services.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	itemDAL := dal.NewItemDAL(db)
+	vaultService := services.NewVaultService(dal.NewVaultDAL(db), itemDAL)
+	cryptoService := services.NewCryptoService(dal.NewKeyDAL(db), masterKey)
+	itemService := services.NewItemService(itemDAL, dal.NewFolderDAL(db), dal.NewTagDAL(db), cryptoService, vaultService, services.NewBreachService(nil))
+
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := vaultService.EnableZeroKnowledge(&types.EnableZeroKnowledgeRequest{
+		UserID: user.ID,
+		KDF:    types.KDFParams{Algoritmo: "argon2id", Salt: salt, Memoria: 65536, Iteracoes: 3, Paralelismo: 4},
+	}); err != nil {
+		t.Fatalf("ativar zero-knowledge: %v", err)
+	}
+
+	// Autenticação fora do escopo: o middleware só identifica o usuário.
+	authMiddleware := func(c *fiber.Ctx) error {
+		c.Locals("userID", user.ID)
+		return c.Next()
+	}
+
+	app := fiber.New()
+	routes.SetupItemRoutes(app, controllers.NewItemController(itemService), authMiddleware, ratelimit.NewLimiter(ratelimit.NewMemoryStore()))
+
+	// Blob do tamanho máximo aceito, para cobrir também o limite de 64 KiB.
+	blob := make([]byte, 64*1024)
+	if _, err := rand.Read(blob); err != nil {
+		t.Fatal(err)
+	}
+	blob[0] = 1
+
+	body, _ := json.Marshal(map[string]string{"blob": base64.StdEncoding.EncodeToString(blob)})
+	req := httptest.NewRequest("POST", "/api/item", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := app.Test(req, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != fiber.StatusCreated {
+		data, _ := io.ReadAll(resp.Body)
+		t.Fatalf("POST /api/item: status %d: %s", resp.StatusCode, data)
+	}
+
+	var created types.ItemResponse
+	if err := json.NewDecoder(resp.Body).Decode(&created); err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err = app.Test(httptest.NewRequest("GET", fmt.Sprintf("/api/item/%d", created.ID), nil), -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != fiber.StatusOK {
+		data, _ := io.ReadAll(resp.Body)
+		t.Fatalf("GET /api/item/%d: status %d: %s", created.ID, resp.StatusCode, data)
+	}
+
+	var fetched types.ItemResponse
+	if err := json.NewDecoder(resp.Body).Decode(&fetched); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := base64.StdEncoding.DecodeString(fetched.Blob)
+	if err != nil {
+		t.Fatalf("blob devolvido não é base64: %v", err)
+	}
+	if !bytes.Equal(got, blob) {
+		t.Fatal("blob devolvido difere do enviado")
+	}
+}
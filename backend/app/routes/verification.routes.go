@@ -0,0 +1,19 @@
+package routes
+
+import (
+	"time"
+
+	"github.com/Vicente/Password-Mobile-App/backend/app/controllers"
+	"github.com/Vicente/Password-Mobile-App/backend/app/middleware"
+	"github.com/Vicente/Password-Mobile-App/backend/app/ratelimit"
+	"github.com/gofiber/fiber/v2"
+)
+
+var verifyEmailRateLimit = ratelimit.Rule{Limit: 20, Window: time.Hour}
+
+func SetupVerificationRoutes(app *fiber.App, verificationController *controllers.VerificationController, authMiddleware fiber.Handler, limiter *ratelimit.Limiter) {
+	verifyRoutes := app.Group("/api/auth/verify")
+
+	verifyRoutes.Get("/", middleware.RateLimitMiddleware(limiter, "verify", verifyEmailRateLimit), verificationController.Verify)
+	verifyRoutes.Post("/resend", authMiddleware, verificationController.Resend)
+}
@@ -0,0 +1,37 @@
+package services
+
+import (
+	"log"
+
+	"github.com/Vicente/Password-Mobile-App/backend/app/dal"
+	"github.com/Vicente/Password-Mobile-App/backend/app/types"
+)
+
+type AuditService struct {
+	AuditDAL *dal.AuditDAL
+}
+
+func NewAuditService(auditDAL *dal.AuditDAL) *AuditService {
+	return &AuditService{
+		AuditDAL: auditDAL,
+	}
+}
+
+// Record registra um evento sem interromper a operação em caso de falha.
+func (s *AuditService) Record(userID uint, acao string, info types.SessionInfo, detalhes string) {
+	event := &types.AuditEvent{
+		UserID:    userID,
+		Acao:      acao,
+		IP:        info.IP,
+		UserAgent: truncate(info.UserAgent, maxUserAgentLength),
+		Detalhes:  detalhes,
+	}
+
+	if err := s.AuditDAL.CreateEvent(event); err != nil {
+		log.Printf("Falha ao registrar evento de auditoria %q: %v", acao, err)
+	}
+}
+
+func (s *AuditService) GetEvents(userID uint) ([]types.AuditEvent, error) {
+	return s.AuditDAL.GetEventsByUserID(userID)
+}
@@ -2,65 +2,64 @@ package services
 
 import (
 	"errors"
+	"log"
 	"net/mail"
-	"os"
 	"strings"
 	"time"
 
 	"github.com/Vicente/Password-Mobile-App/backend/app/dal"
+	"github.com/Vicente/Password-Mobile-App/backend/app/ratelimit"
 	"github.com/Vicente/Password-Mobile-App/backend/app/types"
-	"github.com/golang-jwt/jwt/v4"
 	"golang.org/x/crypto/bcrypt"
 )
 
-var (
-	jwtSecret = os.Getenv("JWT_SECRET")
-	jwtExpiry = 24 * time.Hour
-)
+var loginFailurePolicy = ratelimit.FailurePolicy{
+	Window:       15 * time.Minute,
+	FreeAttempts: 3,
+	BaseDelay:    time.Second,
+	MaxDelay:     30 * time.Second,
+	LockAfter:    10,
+	LockDuration: 15 * time.Minute,
+}
 
 type AuthService struct {
-	AuthDAL *dal.AuthDAL
+	AuthDAL             *dal.AuthDAL
+	SessionService      *SessionService
+	MFAService          *MFAService
+	VerificationService *VerificationService
+	AuditService        *AuditService
+	Limiter             *ratelimit.Limiter
+	BreachService       *BreachService
+	// MinPasswordScore é a força mínima (0 a 4) exigida da senha da conta.
+	MinPasswordScore int
 }
 
-func NewAuthService(authDAL *dal.AuthDAL) *AuthService {
+func NewAuthService(authDAL *dal.AuthDAL, sessionService *SessionService, mfaService *MFAService, verificationService *VerificationService, auditService *AuditService, limiter *ratelimit.Limiter, breachService *BreachService) *AuthService {
 	return &AuthService{
-		AuthDAL: authDAL,
+		AuthDAL:             authDAL,
+		SessionService:      sessionService,
+		MFAService:          mfaService,
+		VerificationService: verificationService,
+		AuditService:        auditService,
+		Limiter:             limiter,
+		BreachService:       breachService,
+		MinPasswordScore:    defaultMinPasswordScore,
 	}
 }
 
 func (s *AuthService) Signup(req *types.SignupRequest) (*types.User, error) {
-	email := strings.TrimSpace(req.Email)
-	if email == "" {
-		return nil, errors.New("email é obrigatório")
-	}
-
-	if _, err := mail.ParseAddress(email); err != nil {
-		return nil, errors.New("formato de email inválido")
-	}
-
-	if !strings.Contains(email, "@") || !strings.Contains(email, ".") {
-		return nil, errors.New("email deve conter @ e domínio válido")
-	}
-
-	if strings.HasPrefix(email, ".") || strings.HasSuffix(email, ".") ||
-		strings.HasPrefix(email, "@") || strings.HasSuffix(email, "@") {
-		return nil, errors.New("formato de email inválido")
+	email, err := normalizeEmail(req.Email)
+	if err != nil {
+		return nil, err
 	}
 
-	email = strings.ToLower(email)
-
 	if len(strings.TrimSpace(req.Nome)) == 0 {
 		return nil, errors.New("nome é obrigatório")
 	}
 
-	senha := strings.TrimSpace(req.Senha)
-	if len(senha) < 6 {
-		return nil, errors.New("a senha deve ter pelo menos 6 caracteres")
-	}
-
-	confirmacaoSenha := strings.TrimSpace(req.ConfirmacaoSenha)
-	if senha != confirmacaoSenha {
-		return nil, errors.New("as senhas não coincidem")
+	senha, err := validateAccountPassword(req.Senha, req.ConfirmacaoSenha, s.MinPasswordScore, s.BreachService, req.Nome, email)
+	if err != nil {
+		return nil, err
 	}
 
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(senha), bcrypt.DefaultCost)
@@ -79,6 +78,10 @@ func (s *AuthService) Signup(req *types.SignupRequest) (*types.User, error) {
 		return nil, err
 	}
 
+	if err := s.VerificationService.SendVerification(user, user.Email); err != nil {
+		log.Printf("Falha ao enviar email de verificação: %v", err)
+	}
+
 	user.Senha = ""
 
 	return user, nil
@@ -94,36 +97,79 @@ func (s *AuthService) Login(req *types.LoginRequest) (*types.AuthResponse, error
 		return nil, errors.New("formato de email inválido")
 	}
 
+	limitKey := "login:" + email
+	if err := s.Limiter.ReserveFailure(limitKey, loginFailurePolicy); err != nil {
+		return nil, err
+	}
+
 	user, err := s.AuthDAL.GetUserByEmail(email)
 	if err != nil {
-		return nil, errors.New("email ou senha inválidos")
+		return nil, s.loginFailed(limitKey)
 	}
 
 	if err := bcrypt.CompareHashAndPassword([]byte(user.SenhaHash), []byte(req.Senha)); err != nil {
-		return nil, errors.New("email ou senha inválidos")
+		s.AuditService.Record(user.ID, types.AuditLoginFailed, req.Sessao, "")
+		return nil, s.loginFailed(limitKey)
 	}
 
-	token, err := s.generateJWT(user)
+	if err := s.Limiter.ResetFailures(limitKey); err != nil {
+		return nil, err
+	}
+
+	mfaEnabled, err := s.MFAService.IsEnabled(user.ID)
 	if err != nil {
-		return nil, errors.New("erro ao gerar token de autenticação")
+		return nil, err
 	}
 
+	if mfaEnabled {
+		mfaToken, err := s.MFAService.GenerateMFAToken(user)
+		if err != nil {
+			return nil, errors.New("erro ao gerar token de verificação")
+		}
+		return &types.AuthResponse{
+			MFAObrigatorio: true,
+			MFAToken:       mfaToken,
+		}, nil
+	}
+
+	response, err := s.SessionService.CreateSession(user, req.Sessao)
+	if err != nil {
+		return nil, err
+	}
+
+	s.AuditService.Record(user.ID, types.AuditLogin, req.Sessao, "")
+
 	user.Senha = ""
+	response.User = *user
 
-	return &types.AuthResponse{
-		Token: token,
-		User:  *user,
-	}, nil
+	return response, nil
 }
 
-func (s *AuthService) generateJWT(user *types.User) (string, error) {
-	claims := jwt.MapClaims{
-		"id":    user.ID,
-		"email": user.Email,
-		"exp":   time.Now().Add(jwtExpiry).Unix(),
+func (s *AuthService) loginFailed(limitKey string) error {
+	if err := s.Limiter.ConfirmFailure(limitKey, loginFailurePolicy); err != nil {
+		return err
+	}
+	return errors.New("email ou senha inválidos")
+}
+
+func normalizeEmail(email string) (string, error) {
+	email = strings.TrimSpace(email)
+	if email == "" {
+		return "", errors.New("email é obrigatório")
+	}
+
+	if _, err := mail.ParseAddress(email); err != nil {
+		return "", errors.New("formato de email inválido")
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	if !strings.Contains(email, "@") || !strings.Contains(email, ".") {
+		return "", errors.New("email deve conter @ e domínio válido")
+	}
+
+	if strings.HasPrefix(email, ".") || strings.HasSuffix(email, ".") ||
+		strings.HasPrefix(email, "@") || strings.HasSuffix(email, "@") {
+		return "", errors.New("formato de email inválido")
+	}
 
-	return token.SignedString([]byte(jwtSecret))
+	return strings.ToLower(email), nil
 }
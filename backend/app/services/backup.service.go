@@ -0,0 +1,395 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Vicente/Password-Mobile-App/backend/app/backup"
+	"github.com/Vicente/Password-Mobile-App/backend/app/dal"
+	"github.com/Vicente/Password-Mobile-App/backend/app/types"
+	"gorm.io/gorm"
+)
+
+// BuildBackup monta a cópia completa do cofre: itens ativos e da lixeira, com
+// revisão, datas e histórico de senhas de cada um.
+func (s *ItemService) BuildBackup(userID uint) (*types.VaultBackup, error) {
+	zeroKnowledge, err := s.VaultService.IsZeroKnowledge(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := s.ItemDAL.GetAllItemsByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	history, err := s.ItemDAL.GetHistoryByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	historyByItem := make(map[uint][]types.ItemHistory)
+	for _, entry := range history {
+		historyByItem[entry.ItemID] = append(historyByItem[entry.ItemID], entry)
+	}
+
+	folders, err := s.FolderDAL.GetFoldersByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	tags, err := s.TagDAL.GetTagsByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	paths := folderPaths(folders)
+	doc := &types.VaultBackup{
+		Versao:        types.BackupVersion,
+		GeradoEm:      time.Now(),
+		ZeroKnowledge: zeroKnowledge,
+		Tags:          tagNames(tags),
+		Itens:         make([]types.BackupItem, 0, len(items)),
+	}
+	for _, folder := range folders {
+		doc.Pastas = append(doc.Pastas, paths[folder.ID])
+	}
+
+	for i := range items {
+		item := &items[i]
+		entry := types.BackupItem{
+			Revisao:      item.Revisao,
+			CriadoEm:     item.CreatedAt,
+			AtualizadoEm: item.UpdatedAt,
+		}
+		if item.DeletedAt.Valid {
+			excluidoEm := item.DeletedAt.Time
+			entry.ExcluidoEm = &excluidoEm
+		}
+
+		if len(item.Blob) > 0 {
+			entry.Blob = EncodeBlob(item.Blob)
+		} else {
+			content, err := s.itemContent(item)
+			if err != nil {
+				return nil, err
+			}
+			entry.Tipo = content.Tipo
+			entry.Nome = item.Nome
+			entry.Usuario = content.Usuario
+			entry.URL = content.URL
+			entry.Senha = content.Senha
+			entry.Notas = content.Notas
+			if len(item.DadosCifrados) > 0 {
+				entry.Dados = &content.Dados
+			}
+			entry.Tags = tagNames(item.Tags)
+			if item.PastaID != nil {
+				entry.Pasta = paths[*item.PastaID]
+			}
+		}
+
+		for _, version := range historyByItem[item.ID] {
+			record := types.BackupHistory{Versao: version.Versao, CriadoEm: version.CreatedAt}
+			if len(version.Blob) > 0 {
+				record.Blob = EncodeBlob(version.Blob)
+			} else if record.Senha, err = s.CryptoService.DecryptField(userID, version.ItemID, FieldSenha, version.SenhaCifrada); err != nil {
+				return nil, err
+			}
+			entry.Historico = append(entry.Historico, record)
+		}
+
+		doc.Itens = append(doc.Itens, entry)
+	}
+
+	return doc, nil
+}
+
+// ExportBackup gera a cópia de segurança cifrada com a senha informada.
+func (s *ItemService) ExportBackup(userID uint, senha string) ([]byte, error) {
+	if userID == 0 {
+		return nil, errors.New("usuário é obrigatório")
+	}
+
+	senha, err := validateNewPassword(senha, senha)
+	if err != nil {
+		return nil, err
+	}
+
+	doc, err := s.BuildBackup(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	return backup.Seal(content, senha)
+}
+
+// RestoreBackup abre uma cópia gerada por ExportBackup. No modo merge os itens
+// do arquivo são somados aos atuais, ignorando nomes já existentes e a
+// lixeira; no modo replace o cofre atual, com pastas e tags, é apagado e
+// recriado exatamente como no arquivo, e se algum item do arquivo não puder
+// ser restaurado nada é alterado. Tudo acontece em uma única transação.
+func (s *ItemService) RestoreBackup(userID uint, data []byte, senha string, modo string) (*types.RestoreResult, error) {
+	if userID == 0 {
+		return nil, errors.New("usuário é obrigatório")
+	}
+
+	modo = strings.ToLower(strings.TrimSpace(modo))
+	if modo == "" {
+		modo = types.RestoreMerge
+	}
+	if modo != types.RestoreMerge && modo != types.RestoreReplace {
+		return nil, errors.New("modo de restauração inválido, use merge ou replace")
+	}
+
+	content, err := backup.Open(data, strings.TrimSpace(senha))
+	if err != nil {
+		return nil, err
+	}
+
+	var doc types.VaultBackup
+	if err := json.Unmarshal(content, &doc); err != nil {
+		return nil, errors.New("conteúdo da cópia de segurança inválido")
+	}
+	if doc.Versao != types.BackupVersion {
+		return nil, errors.New("versão da cópia de segurança não suportada")
+	}
+
+	zeroKnowledge, err := s.VaultService.IsZeroKnowledge(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make(map[string]int)
+	if modo == types.RestoreMerge {
+		existing, err := s.ItemDAL.GetItemNamesByUserID(userID)
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range existing {
+			names[name] = 0
+		}
+	}
+
+	result := &types.RestoreResult{
+		Modo:      modo,
+		Total:     len(doc.Itens),
+		Ignorados: []types.ImportIssue{},
+	}
+
+	type restored struct {
+		item    *types.Item
+		history []types.ItemHistory
+		pasta   []string
+		tags    []string
+	}
+	var pending []restored
+
+	for i := range doc.Itens {
+		entry := &doc.Itens[i]
+		linha := i + 1
+
+		if entry.ExcluidoEm != nil && modo == types.RestoreMerge {
+			result.Ignorados = append(result.Ignorados, types.ImportIssue{Linha: linha, Nome: entry.Nome, Motivo: "itens da lixeira só são restaurados no modo replace"})
+			continue
+		}
+
+		item, history, err := s.restoreItem(userID, zeroKnowledge, entry)
+		if err != nil {
+			result.Ignorados = append(result.Ignorados, types.ImportIssue{Linha: linha, Nome: entry.Nome, Motivo: err.Error()})
+			continue
+		}
+
+		pasta, err := splitFolderPath(entry.Pasta)
+		if err != nil {
+			result.Ignorados = append(result.Ignorados, types.ImportIssue{Linha: linha, Nome: entry.Nome, Motivo: err.Error()})
+			continue
+		}
+
+		tags, err := normalizeTags(entry.Tags)
+		if err != nil {
+			result.Ignorados = append(result.Ignorados, types.ImportIssue{Linha: linha, Nome: entry.Nome, Motivo: err.Error()})
+			continue
+		}
+
+		// Itens da lixeira não disputam o nome com os ativos, e itens do modo
+		// zero-knowledge não têm nome visível para o servidor.
+		if !item.DeletedAt.Valid && item.Nome != "" {
+			if line, ok := names[item.Nome]; ok {
+				motivo := "já existe um item com este nome"
+				if line > 0 {
+					motivo = fmt.Sprintf("nome repetido no item %d do arquivo", line)
+				}
+				result.Ignorados = append(result.Ignorados, types.ImportIssue{Linha: linha, Nome: item.Nome, Motivo: motivo})
+				continue
+			}
+			names[item.Nome] = linha
+		}
+
+		pending = append(pending, restored{item: item, history: history, pasta: pasta, tags: tags})
+	}
+
+	// O replace apaga o cofre atual: se algum item do arquivo ficaria de
+	// fora, nada é alterado.
+	if modo == types.RestoreReplace && len(result.Ignorados) > 0 {
+		return result, errors.New("a cópia tem itens que não podem ser restaurados, nada foi alterado")
+	}
+
+	err = s.ItemDAL.Transaction(func(tx *dal.ItemDAL) error {
+		folderDAL := dal.NewFolderDAL(tx.DB)
+		tagDAL := dal.NewTagDAL(tx.DB)
+
+		if modo == types.RestoreReplace {
+			removed, err := tx.DeleteAllItemsByUserID(userID)
+			if err != nil {
+				return err
+			}
+			result.Removidos = int(removed)
+
+			if err := folderDAL.DeleteAllFoldersByUserID(userID); err != nil {
+				return err
+			}
+			if err := tagDAL.DeleteAllTagsByUserID(userID); err != nil {
+				return err
+			}
+		}
+
+		// Pastas e tags sem itens também fazem parte da cópia. Caminhos ou
+		// nomes inválidos são ignorados, como os itens.
+		for _, path := range doc.Pastas {
+			names, err := splitFolderPath(path)
+			if err != nil {
+				continue
+			}
+			if _, err := folderDAL.EnsurePath(userID, names); err != nil {
+				return err
+			}
+		}
+		tags := make([]string, 0, len(doc.Tags))
+		for _, name := range doc.Tags {
+			if nome, err := validateTagName(name); err == nil {
+				tags = append(tags, nome)
+			}
+		}
+		if _, err := tagDAL.FindOrCreateTags(userID, tags); err != nil {
+			return err
+		}
+
+		for _, entry := range pending {
+			pastaID, err := folderDAL.EnsurePath(userID, entry.pasta)
+			if err != nil {
+				return err
+			}
+			entry.item.PastaID = pastaID
+
+			if entry.item.Tags, err = tagDAL.FindOrCreateTags(userID, entry.tags); err != nil {
+				return err
+			}
+
+			if err := tx.InsertItem(entry.item, entry.history); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result.Restaurados = len(pending)
+	return result, nil
+}
+
+func (s *ItemService) restoreItem(userID uint, zeroKnowledge bool, entry *types.BackupItem) (*types.Item, []types.ItemHistory, error) {
+	var item *types.Item
+	if entry.Blob != "" {
+		if !zeroKnowledge {
+			return nil, nil, errors.New("itens cifrados no cliente só podem ser restaurados no modo zero-knowledge")
+		}
+		blob, err := DecodeBlob(entry.Blob)
+		if err != nil {
+			return nil, nil, err
+		}
+		item = &types.Item{Blob: blob, UserID: userID}
+	} else {
+		if zeroKnowledge {
+			return nil, nil, errors.New("o modo zero-knowledge só aceita itens cifrados no cliente")
+		}
+
+		nome := strings.TrimSpace(entry.Nome)
+		if nome == "" {
+			return nil, nil, errors.New("nome é obrigatório")
+		}
+
+		// Backups anteriores aos tipos de item só têm logins.
+		tipo, err := parseItemType(entry.Tipo)
+		if err != nil {
+			return nil, nil, err
+		}
+		content := &itemContent{
+			Tipo:    tipo,
+			Usuario: strings.TrimSpace(entry.Usuario),
+			URL:     strings.TrimSpace(entry.URL),
+			Senha:   entry.Senha,
+			Notas:   strings.TrimSpace(entry.Notas),
+		}
+		if entry.Dados != nil {
+			content.Dados = *entry.Dados
+		}
+		if err := validateItemContent(content); err != nil {
+			return nil, nil, err
+		}
+
+		if item, err = s.newTypedItem(userID, nome, content); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	item.Revisao = entry.Revisao
+	if item.Revisao == 0 {
+		item.Revisao = 1
+	}
+	item.CreatedAt = entry.CriadoEm
+	item.UpdatedAt = entry.AtualizadoEm
+	if entry.ExcluidoEm != nil {
+		item.DeletedAt = gorm.DeletedAt{Time: *entry.ExcluidoEm, Valid: true}
+	}
+
+	versions := make(map[uint]bool, len(entry.Historico))
+	history := make([]types.ItemHistory, 0, len(entry.Historico))
+	for _, version := range entry.Historico {
+		if version.Versao == 0 || version.Versao >= item.Revisao || versions[version.Versao] {
+			return nil, nil, errors.New("histórico do item inválido")
+		}
+		versions[version.Versao] = true
+
+		record := types.ItemHistory{Versao: version.Versao, CreatedAt: version.CriadoEm}
+		if version.Blob != "" {
+			if !zeroKnowledge {
+				return nil, nil, errors.New("itens cifrados no cliente só podem ser restaurados no modo zero-knowledge")
+			}
+			blob, err := DecodeBlob(version.Blob)
+			if err != nil {
+				return nil, nil, err
+			}
+			record.Blob = blob
+		} else {
+			senhaCifrada, err := s.CryptoService.EncryptField(userID, item.ID, FieldSenha, version.Senha)
+			if err != nil {
+				return nil, nil, err
+			}
+			record.SenhaCifrada = senhaCifrada
+		}
+		history = append(history, record)
+	}
+
+	return item, history, nil
+}
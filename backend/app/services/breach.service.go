@@ -0,0 +1,44 @@
+package services
+
+import (
+	"log"
+
+	"github.com/Vicente/Password-Mobile-App/backend/app/breach"
+)
+
+type BreachService struct {
+	Checker breach.Checker
+}
+
+// NewBreachService aceita um checker nil: sem conjunto configurado as senhas
+// simplesmente não são verificadas.
+func NewBreachService(checker breach.Checker) *BreachService {
+	return &BreachService{
+		Checker: checker,
+	}
+}
+
+func (s *BreachService) Enabled() bool {
+	return s != nil && s.Checker != nil
+}
+
+// Check informa se a senha aparece em vazamentos conhecidos. O resultado é
+// nil quando não há conjunto configurado ou a consulta falha, para que um
+// problema no arquivo local não impeça o uso do cofre.
+func (s *BreachService) Check(senha string) *bool {
+	if !s.Enabled() {
+		return nil
+	}
+
+	breached, err := s.Checker.Contains(senha)
+	if err != nil {
+		log.Printf("Falha ao consultar senhas vazadas: %v", err)
+		return nil
+	}
+	return &breached
+}
+
+func (s *BreachService) IsBreached(senha string) bool {
+	breached := s.Check(senha)
+	return breached != nil && *breached
+}
@@ -0,0 +1,279 @@
+package services
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/Vicente/Password-Mobile-App/backend/app/dal"
+	"github.com/Vicente/Password-Mobile-App/backend/app/types"
+	"gorm.io/gorm"
+)
+
+// Formato do texto cifrado: [versão (1 byte)][nonce (12 bytes)][dados + tag GCM].
+// Na versão 1 o AAD identifica só o usuário e é usada nas chaves de dados e em
+// dados que não pertencem a um item. Na versão 2 ele identifica também o item
+// e o campo, e um valor copiado para outro item ou campo não abre.
+const (
+	cipherVersion      byte = 1
+	fieldCipherVersion byte = 2
+	keySize                 = 32
+)
+
+// Campos cifrados de um item, como entram no AAD. O histórico guarda senhas
+// anteriores do item e usa o mesmo campo da senha atual.
+const (
+	FieldSenha = "senha"
+	FieldNotas = "notas"
+	FieldDados = "dados"
+)
+
+type CryptoService struct {
+	KeyDAL    *dal.KeyDAL
+	masterKey []byte
+	mu        sync.Mutex
+	dataKeys  map[uint][]byte
+}
+
+func NewCryptoService(keyDAL *dal.KeyDAL, masterKey []byte) *CryptoService {
+	return &CryptoService{
+		KeyDAL:    keyDAL,
+		masterKey: masterKey,
+		dataKeys:  make(map[uint][]byte),
+	}
+}
+
+func DecodeMasterKey(value string) ([]byte, error) {
+	value = strings.TrimSpace(value)
+
+	if key, err := hex.DecodeString(value); err == nil && len(key) == keySize {
+		return key, nil
+	}
+
+	if key, err := base64.StdEncoding.DecodeString(value); err == nil && len(key) == keySize {
+		return key, nil
+	}
+
+	return nil, fmt.Errorf("a chave mestra deve ter %d bytes em hex ou base64", keySize)
+}
+
+func GenerateKey() ([]byte, error) {
+	key := make([]byte, keySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+func (s *CryptoService) EncryptString(userID uint, plaintext string) ([]byte, error) {
+	return s.Encrypt(userID, []byte(plaintext))
+}
+
+func (s *CryptoService) DecryptString(userID uint, ciphertext []byte) (string, error) {
+	plaintext, err := s.Decrypt(userID, ciphertext)
+	if err != nil {
+		return "", err
+	}
+	return string(plaintext), nil
+}
+
+func (s *CryptoService) Encrypt(userID uint, plaintext []byte) ([]byte, error) {
+	key, err := s.dataKey(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	ciphertext, err := seal(key, cipherVersion, plaintext, userAAD(userID))
+	if err != nil {
+		return nil, errors.New("erro ao criptografar dados")
+	}
+	return ciphertext, nil
+}
+
+func (s *CryptoService) Decrypt(userID uint, ciphertext []byte) ([]byte, error) {
+	key, err := s.dataKey(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	plaintext, err := open(key, cipherVersion, ciphertext, userAAD(userID))
+	if err != nil {
+		return nil, errors.New("erro ao descriptografar dados")
+	}
+	return plaintext, nil
+}
+
+// EncryptField cifra um campo de um item, com o item e o campo no AAD.
+func (s *CryptoService) EncryptField(userID, itemID uint, field string, plaintext string) ([]byte, error) {
+	key, err := s.dataKey(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	ciphertext, err := seal(key, fieldCipherVersion, []byte(plaintext), fieldAAD(userID, itemID, field))
+	if err != nil {
+		return nil, errors.New("erro ao criptografar dados")
+	}
+	return ciphertext, nil
+}
+
+// DecryptField decifra um campo cifrado por EncryptField. Valores da versão 1
+// são recusados: BindItemFields os converte na inicialização.
+func (s *CryptoService) DecryptField(userID, itemID uint, field string, ciphertext []byte) (string, error) {
+	key, err := s.dataKey(userID)
+	if err != nil {
+		return "", err
+	}
+
+	plaintext, err := open(key, fieldCipherVersion, ciphertext, fieldAAD(userID, itemID, field))
+	if err != nil {
+		return "", errors.New("erro ao descriptografar dados")
+	}
+	return string(plaintext), nil
+}
+
+// IsFieldBound informa se o valor já está no formato com item e campo no AAD.
+func IsFieldBound(ciphertext []byte) bool {
+	return len(ciphertext) > 0 && ciphertext[0] == fieldCipherVersion
+}
+
+// PasswordMAC calcula um HMAC-SHA256 da senha com uma chave derivada da chave
+// de dados do usuário. Senhas iguais do mesmo usuário geram o mesmo valor, o
+// que permite compará-las sem decifrar; entre usuários os valores diferem.
+func (s *CryptoService) PasswordMAC(userID uint, senha string) ([]byte, error) {
+	key, err := s.dataKey(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	derive := hmac.New(sha256.New, key)
+	derive.Write([]byte("password-mobile-app:senha-hmac:v1"))
+
+	mac := hmac.New(sha256.New, derive.Sum(nil))
+	mac.Write([]byte(senha))
+	return mac.Sum(nil), nil
+}
+
+// dataKey devolve a chave de dados do usuário. O mutex protege só o cache: a
+// leitura e a criação no banco acontecem fora dele, e duas requisições que
+// carregam a mesma chave ao mesmo tempo chegam ao mesmo valor.
+func (s *CryptoService) dataKey(userID uint) ([]byte, error) {
+	s.mu.Lock()
+	key, ok := s.dataKeys[userID]
+	s.mu.Unlock()
+	if ok {
+		return key, nil
+	}
+
+	stored, err := s.KeyDAL.GetKeyByUserID(userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		stored, err = s.createDataKey(userID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	key, err = open(s.masterKey, cipherVersion, stored.ChaveCifrada, userAAD(userID))
+	if err != nil {
+		return nil, errors.New("erro ao abrir chave de dados do usuário")
+	}
+
+	s.mu.Lock()
+	s.dataKeys[userID] = key
+	s.mu.Unlock()
+	return key, nil
+}
+
+func (s *CryptoService) createDataKey(userID uint) (*types.UserKey, error) {
+	key, err := GenerateKey()
+	if err != nil {
+		return nil, errors.New("erro ao gerar chave de dados")
+	}
+
+	wrapped, err := seal(s.masterKey, cipherVersion, key, userAAD(userID))
+	if err != nil {
+		return nil, errors.New("erro ao proteger chave de dados")
+	}
+
+	stored := &types.UserKey{
+		UserID:       userID,
+		ChaveCifrada: wrapped,
+	}
+
+	if err := s.KeyDAL.CreateKey(stored); err != nil {
+		// Outra requisição pode ter criado a chave ao mesmo tempo.
+		if existing, getErr := s.KeyDAL.GetKeyByUserID(userID); getErr == nil {
+			return existing, nil
+		}
+		return nil, err
+	}
+
+	return stored, nil
+}
+
+func userAAD(userID uint) []byte {
+	return []byte(fmt.Sprintf("user:%d", userID))
+}
+
+func fieldAAD(userID, itemID uint, field string) []byte {
+	return []byte(fmt.Sprintf("user:%d:item:%d:%s", userID, itemID, field))
+}
+
+func seal(key []byte, version byte, plaintext, aad []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	out := make([]byte, 0, 1+len(nonce)+len(plaintext)+gcm.Overhead())
+	out = append(out, version)
+	out = append(out, nonce...)
+	return gcm.Seal(out, nonce, plaintext, aad), nil
+}
+
+func open(key []byte, version byte, data, aad []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) < 1+gcm.NonceSize()+gcm.Overhead() {
+		return nil, errors.New("texto cifrado muito curto")
+	}
+
+	if data[0] != version {
+		return nil, fmt.Errorf("versão de cifra não suportada: %d", data[0])
+	}
+
+	nonce := data[1 : 1+gcm.NonceSize()]
+	return gcm.Open(nil, nonce, data[1+gcm.NonceSize():], aad)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// ForgetKey descarta a chave de dados em cache, usado quando a conta é excluída.
+func (s *CryptoService) ForgetKey(userID uint) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.dataKeys, userID)
+}
@@ -0,0 +1,49 @@
+package services
+
+import "testing"
+
+func TestEncryptFieldBinding(t *testing.T) {
+	masterKey, _ := GenerateKey()
+	dataKey, _ := GenerateKey()
+	service := NewCryptoService(nil, masterKey)
+	service.dataKeys[1] = dataKey
+	service.dataKeys[2] = dataKey
+
+	ciphertext, err := service.EncryptField(1, 10, FieldSenha, "s3nh@")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !IsFieldBound(ciphertext) {
+		t.Fatal("valor cifrado sem a versão com item e campo")
+	}
+	if senha, err := service.DecryptField(1, 10, FieldSenha, ciphertext); err != nil || senha != "s3nh@" {
+		t.Fatalf("DecryptField = %q, %v", senha, err)
+	}
+
+	// Mesmo com a mesma chave, o valor só abre no item e no campo de origem.
+	tests := []struct {
+		userID, itemID uint
+		field          string
+	}{
+		{1, 11, FieldSenha},
+		{1, 10, FieldNotas},
+		{2, 10, FieldSenha},
+	}
+	for _, tt := range tests {
+		if _, err := service.DecryptField(tt.userID, tt.itemID, tt.field, ciphertext); err == nil {
+			t.Errorf("valor do item 10 aberto como usuário %d, item %d, campo %s", tt.userID, tt.itemID, tt.field)
+		}
+	}
+
+	// O formato anterior só é lido pela migração.
+	legacy, err := service.EncryptString(1, "s3nh@")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if IsFieldBound(legacy) {
+		t.Fatal("valor da versão 1 tratado como vinculado")
+	}
+	if _, err := service.DecryptField(1, 10, FieldSenha, legacy); err == nil {
+		t.Error("valor da versão 1 aceito por DecryptField")
+	}
+}
@@ -0,0 +1,468 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/Vicente/Password-Mobile-App/backend/app/dal"
+	"github.com/Vicente/Password-Mobile-App/backend/app/ratelimit"
+	"github.com/Vicente/Password-Mobile-App/backend/app/types"
+	"github.com/golang-jwt/jwt/v4"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+const (
+	exportVersion      = 1
+	exportTokenType    = "export"
+	exportDownloadPath = "/api/me/export/download"
+	defaultExportTTL   = 24 * time.Hour
+)
+
+type ExportService struct {
+	ExportDAL     *dal.ExportDAL
+	AuthDAL       *dal.AuthDAL
+	ItemDAL       *dal.ItemDAL
+	FolderDAL     *dal.FolderDAL
+	TagDAL        *dal.TagDAL
+	SessionDAL    *dal.SessionDAL
+	CryptoService *CryptoService
+	AuditService  *AuditService
+	Limiter       *ratelimit.Limiter
+	JWTSecret     []byte
+	TTL           time.Duration
+}
+
+func NewExportService(exportDAL *dal.ExportDAL, authDAL *dal.AuthDAL, itemDAL *dal.ItemDAL, folderDAL *dal.FolderDAL, tagDAL *dal.TagDAL, sessionDAL *dal.SessionDAL, cryptoService *CryptoService, auditService *AuditService, limiter *ratelimit.Limiter, jwtSecret string) *ExportService {
+	return &ExportService{
+		ExportDAL:     exportDAL,
+		AuthDAL:       authDAL,
+		ItemDAL:       itemDAL,
+		FolderDAL:     folderDAL,
+		TagDAL:        tagDAL,
+		SessionDAL:    sessionDAL,
+		CryptoService: cryptoService,
+		AuditService:  auditService,
+		Limiter:       limiter,
+		JWTSecret:     []byte(jwtSecret),
+		TTL:           defaultExportTTL,
+	}
+}
+
+// RequestExport registra o pedido e gera o arquivo em segundo plano; o link
+// de download aparece no status da exportação quando ela termina.
+func (s *ExportService) RequestExport(req *types.ExportRequest) (*types.ExportJobResponse, error) {
+	formato := strings.ToLower(strings.TrimSpace(req.Formato))
+	if formato == "" {
+		formato = types.ExportFormatJSON
+	}
+	if formato != types.ExportFormatJSON && formato != types.ExportFormatZIP {
+		return nil, errors.New("formato de exportação inválido, use json ou zip")
+	}
+
+	user, err := s.AuthDAL.GetUserByID(req.UserID)
+	if err != nil {
+		return nil, errors.New("usuário não encontrado")
+	}
+
+	if req.IncluirSenhas {
+		if err := allowPasswordCheck(s.Limiter, user.ID); err != nil {
+			return nil, err
+		}
+		if err := bcrypt.CompareHashAndPassword([]byte(user.SenhaHash), []byte(req.Senha)); err != nil {
+			return nil, errors.New("senha incorreta")
+		}
+	}
+
+	active, err := s.ExportDAL.HasActiveJob(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if active {
+		return nil, errors.New("já existe uma exportação em andamento")
+	}
+
+	job := &types.ExportJob{
+		UserID:        user.ID,
+		Formato:       formato,
+		IncluirSenhas: req.IncluirSenhas,
+		Status:        types.ExportPending,
+	}
+	if err := s.ExportDAL.CreateJob(job); err != nil {
+		return nil, err
+	}
+
+	s.AuditService.Record(user.ID, types.AuditExportRequested, req.Sessao, formato)
+
+	go s.process(job.ID)
+
+	return s.toResponse(job), nil
+}
+
+func (s *ExportService) GetJobs(userID uint) ([]types.ExportJobResponse, error) {
+	jobs, err := s.ExportDAL.GetJobsByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]types.ExportJobResponse, 0, len(jobs))
+	for i := range jobs {
+		response = append(response, *s.toResponse(&jobs[i]))
+	}
+	return response, nil
+}
+
+func (s *ExportService) GetJob(id uint, userID uint) (*types.ExportJobResponse, error) {
+	job, err := s.ExportDAL.GetUserJob(id, userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errors.New("exportação não encontrada")
+	} else if err != nil {
+		return nil, err
+	}
+
+	return s.toResponse(job), nil
+}
+
+// Download decifra o arquivo da exportação indicada pelo token do link e
+// remove a exportação: cada link só pode ser usado uma vez.
+func (s *ExportService) Download(tokenString string) (*types.ExportJob, []byte, error) {
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, errors.New("método de assinatura inválido")
+		}
+		return s.JWTSecret, nil
+	})
+	if err != nil || !token.Valid {
+		return nil, nil, errors.New("link de download inválido ou expirado")
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return nil, nil, errors.New("link de download inválido ou expirado")
+	}
+
+	if typ, _ := claims["typ"].(string); typ != exportTokenType {
+		return nil, nil, errors.New("link de download inválido ou expirado")
+	}
+
+	jobID, ok := claims["id"].(float64)
+	if !ok {
+		return nil, nil, errors.New("link de download inválido ou expirado")
+	}
+
+	job, err := s.ExportDAL.GetJobByID(uint(jobID))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil, errors.New("link de download inválido ou expirado")
+	} else if err != nil {
+		return nil, nil, err
+	}
+
+	if job.Status != types.ExportDone || job.ExpiraEm == nil || time.Now().After(*job.ExpiraEm) {
+		return nil, nil, errors.New("link de download inválido ou expirado")
+	}
+
+	content, err := s.CryptoService.Decrypt(job.UserID, job.Conteudo)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	deleted, err := s.ExportDAL.DeleteJob(job.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !deleted {
+		return nil, nil, errors.New("link de download inválido ou expirado")
+	}
+
+	return job, content, nil
+}
+
+func (s *ExportService) PurgeExpired() (int64, error) {
+	return s.ExportDAL.DeleteExpiredJobs(time.Now())
+}
+
+func (s *ExportService) FailInterrupted() (int64, error) {
+	return s.ExportDAL.FailInterruptedJobs()
+}
+
+func ExportFilename(job *types.ExportJob) string {
+	return fmt.Sprintf("exportacao-%d-%s.%s", job.ID, job.CreatedAt.Format("20060102"), job.Formato)
+}
+
+func (s *ExportService) process(jobID uint) {
+	job, err := s.ExportDAL.GetJobByID(jobID)
+	if err != nil {
+		log.Printf("Falha ao carregar exportação %d: %v", jobID, err)
+		return
+	}
+
+	job.Status = types.ExportRunning
+	if err := s.ExportDAL.SaveJob(job); err != nil {
+		log.Printf("Falha ao atualizar exportação %d: %v", jobID, err)
+		return
+	}
+
+	content, err := s.generate(job)
+	if err == nil {
+		// O arquivo fica no banco até o download: guarda cifrado com a chave
+		// de dados do usuário, como os itens.
+		content, err = s.CryptoService.Encrypt(job.UserID, content)
+	}
+	now := time.Now()
+	if err != nil {
+		log.Printf("Falha ao gerar exportação %d: %v", jobID, err)
+		job.Status = types.ExportFailed
+		job.Erro = "erro ao gerar exportação"
+	} else {
+		expiraEm := now.Add(s.TTL)
+		job.Status = types.ExportDone
+		job.Conteudo = content
+		job.ExpiraEm = &expiraEm
+	}
+	job.ConcluidoEm = &now
+
+	if err := s.ExportDAL.SaveJob(job); err != nil {
+		log.Printf("Falha ao salvar exportação %d: %v", jobID, err)
+	}
+}
+
+func (s *ExportService) generate(job *types.ExportJob) ([]byte, error) {
+	doc, err := s.buildDocument(job.UserID, job.IncluirSenhas)
+	if err != nil {
+		return nil, err
+	}
+
+	if job.Formato == types.ExportFormatZIP {
+		return encodeExportZIP(doc)
+	}
+	return json.MarshalIndent(doc, "", "  ")
+}
+
+func (s *ExportService) buildDocument(userID uint, incluirSenhas bool) (*types.ExportDocument, error) {
+	user, err := s.AuthDAL.GetUserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := s.ItemDAL.GetAllItemsByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	history, err := s.ItemDAL.GetHistoryByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	folders, err := s.FolderDAL.GetFoldersByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	tags, err := s.TagDAL.GetTagsByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	sessions, err := s.SessionDAL.GetSessionsByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	events, err := s.AuditService.GetEvents(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	doc := &types.ExportDocument{
+		Versao:    exportVersion,
+		GeradoEm:  time.Now(),
+		Usuario:   user.Profile(),
+		Pastas:    folders,
+		Tags:      tags,
+		Itens:     make([]types.ExportItem, 0, len(items)),
+		Historico: make([]types.ExportHistory, 0, len(history)),
+		Sessoes:   make([]types.ExportSession, 0, len(sessions)),
+		Eventos:   events,
+	}
+	if doc.Eventos == nil {
+		doc.Eventos = []types.AuditEvent{}
+	}
+	if doc.Pastas == nil {
+		doc.Pastas = []types.Folder{}
+	}
+	if doc.Tags == nil {
+		doc.Tags = []types.Tag{}
+	}
+
+	for _, item := range items {
+		entry := types.ExportItem{
+			ID:           item.ID,
+			Revisao:      item.Revisao,
+			CriadoEm:     item.CreatedAt,
+			AtualizadoEm: item.UpdatedAt,
+		}
+		if item.DeletedAt.Valid {
+			excluidoEm := item.DeletedAt.Time
+			entry.ExcluidoEm = &excluidoEm
+		}
+
+		if len(item.Blob) > 0 {
+			entry.Blob = EncodeBlob(item.Blob)
+		} else {
+			entry.Tipo = itemType(&item)
+			entry.Nome = item.Nome
+			entry.Usuario = item.Usuario
+			entry.URL = item.URL
+			entry.PastaID = item.PastaID
+			entry.Tags = tagNames(item.Tags)
+			if len(item.SenhaCifrada) > 0 {
+				if entry.Senha, entry.SenhaCifrada, err = s.exportSecret(userID, item.ID, FieldSenha, item.SenhaCifrada, incluirSenhas); err != nil {
+					return nil, err
+				}
+			}
+			if len(item.NotasCifradas) > 0 {
+				if entry.Notas, entry.NotasCifradas, err = s.exportSecret(userID, item.ID, FieldNotas, item.NotasCifradas, incluirSenhas); err != nil {
+					return nil, err
+				}
+			}
+			if len(item.DadosCifrados) > 0 {
+				var dados string
+				if dados, entry.DadosCifrados, err = s.exportSecret(userID, item.ID, FieldDados, item.DadosCifrados, incluirSenhas); err != nil {
+					return nil, err
+				}
+				if dados != "" {
+					entry.Dados = &types.ItemData{}
+					if err := json.Unmarshal([]byte(dados), entry.Dados); err != nil {
+						return nil, err
+					}
+				}
+			}
+		}
+		doc.Itens = append(doc.Itens, entry)
+	}
+
+	for _, version := range history {
+		entry := types.ExportHistory{
+			ItemID:   version.ItemID,
+			Versao:   version.Versao,
+			CriadoEm: version.CreatedAt,
+		}
+
+		if len(version.Blob) > 0 {
+			entry.Blob = EncodeBlob(version.Blob)
+		} else if entry.Senha, entry.SenhaCifrada, err = s.exportSecret(userID, version.ItemID, FieldSenha, version.SenhaCifrada, incluirSenhas); err != nil {
+			return nil, err
+		}
+		doc.Historico = append(doc.Historico, entry)
+	}
+
+	for _, session := range sessions {
+		doc.Sessoes = append(doc.Sessoes, types.ExportSession{
+			ID:           session.ID,
+			Dispositivo:  session.Dispositivo,
+			UserAgent:    session.UserAgent,
+			IP:           session.IP,
+			CriadoEm:     session.CreatedAt,
+			UltimoAcesso: session.UltimoAcesso,
+			RevogadaEm:   session.RevogadaEm,
+		})
+	}
+
+	return doc, nil
+}
+
+func (s *ExportService) exportSecret(userID, itemID uint, field string, senhaCifrada []byte, incluirSenhas bool) (string, []byte, error) {
+	if !incluirSenhas {
+		return "", senhaCifrada, nil
+	}
+
+	senha, err := s.CryptoService.DecryptField(userID, itemID, field, senhaCifrada)
+	if err != nil {
+		return "", nil, err
+	}
+	return senha, nil, nil
+}
+
+func encodeExportZIP(doc *types.ExportDocument) ([]byte, error) {
+	files := []struct {
+		name string
+		data interface{}
+	}{
+		{"manifesto.json", map[string]interface{}{"versao": doc.Versao, "geradoEm": doc.GeradoEm}},
+		{"usuario.json", doc.Usuario},
+		{"pastas.json", doc.Pastas},
+		{"tags.json", doc.Tags},
+		{"itens.json", doc.Itens},
+		{"historico.json", doc.Historico},
+		{"sessoes.json", doc.Sessoes},
+		{"eventos.json", doc.Eventos},
+	}
+
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	for _, file := range files {
+		w, err := archive.CreateHeader(&zip.FileHeader{
+			Name:     file.name,
+			Method:   zip.Deflate,
+			Modified: doc.GeradoEm,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(file.data); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := archive.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (s *ExportService) toResponse(job *types.ExportJob) *types.ExportJobResponse {
+	response := &types.ExportJobResponse{
+		ID:            job.ID,
+		Formato:       job.Formato,
+		IncluirSenhas: job.IncluirSenhas,
+		Status:        job.Status,
+		Erro:          job.Erro,
+		CriadoEm:      job.CreatedAt,
+		ConcluidoEm:   job.ConcluidoEm,
+		ExpiraEm:      job.ExpiraEm,
+	}
+
+	if job.Status == types.ExportDone && job.ExpiraEm != nil && time.Now().Before(*job.ExpiraEm) {
+		token, err := s.downloadToken(job)
+		if err != nil {
+			log.Printf("Falha ao gerar link da exportação %d: %v", job.ID, err)
+		} else {
+			response.Download = exportDownloadPath + "?token=" + url.QueryEscape(token)
+		}
+	}
+
+	return response
+}
+
+func (s *ExportService) downloadToken(job *types.ExportJob) (string, error) {
+	claims := jwt.MapClaims{
+		"id":  job.ID,
+		"typ": exportTokenType,
+		"exp": job.ExpiraEm.Unix(),
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+
+	return token.SignedString(s.JWTSecret)
+}
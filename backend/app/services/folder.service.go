@@ -0,0 +1,277 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/Vicente/Password-Mobile-App/backend/app/dal"
+	"github.com/Vicente/Password-Mobile-App/backend/app/types"
+	"gorm.io/gorm"
+)
+
+const maxFolderNameLength = 100
+
+type FolderService struct {
+	FolderDAL *dal.FolderDAL
+}
+
+func NewFolderService(folderDAL *dal.FolderDAL) *FolderService {
+	return &FolderService{
+		FolderDAL: folderDAL,
+	}
+}
+
+func (s *FolderService) ListFolders(userID uint) ([]types.FolderResponse, error) {
+	folders, err := s.FolderDAL.GetFoldersByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	counts, err := s.FolderDAL.CountItemsByFolder(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	paths := folderPaths(folders)
+	response := make([]types.FolderResponse, 0, len(folders))
+	for _, folder := range folders {
+		response = append(response, types.FolderResponse{
+			ID:       folder.ID,
+			Nome:     folder.Nome,
+			ParentID: folder.ParentID,
+			Caminho:  paths[folder.ID],
+			Itens:    counts[folder.ID],
+		})
+	}
+	return response, nil
+}
+
+func (s *FolderService) CreateFolder(req *types.FolderRequest) (*types.FolderResponse, error) {
+	if req.UserID == 0 {
+		return nil, errors.New("usuário é obrigatório")
+	}
+
+	nome, err := validateFolderName(valueOf(req.Nome))
+	if err != nil {
+		return nil, err
+	}
+
+	parentID, err := s.parentFolder(req.UserID, req.ParentID)
+	if err != nil {
+		return nil, err
+	}
+
+	folder := &types.Folder{Nome: nome, ParentID: parentID, UserID: req.UserID}
+	if err := s.FolderDAL.CreateFolder(folder); err != nil {
+		return nil, err
+	}
+
+	return s.folderResponse(folder)
+}
+
+// UpdateFolder renomeia e/ou move a pasta. parentId 0 leva a pasta para a
+// raiz; uma pasta não pode ir para dentro de si mesma nem de uma subpasta.
+func (s *FolderService) UpdateFolder(req *types.FolderRequest) (*types.FolderResponse, error) {
+	folder, err := s.getFolder(req.ID, req.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Nome != nil {
+		if folder.Nome, err = validateFolderName(*req.Nome); err != nil {
+			return nil, err
+		}
+	}
+
+	if req.ParentID != nil {
+		parentID, err := s.parentFolder(req.UserID, req.ParentID)
+		if err != nil {
+			return nil, err
+		}
+
+		if parentID != nil {
+			folders, err := s.FolderDAL.GetFoldersByUserID(req.UserID)
+			if err != nil {
+				return nil, err
+			}
+			for _, id := range folderTree(folders, folder.ID) {
+				if id == *parentID {
+					return nil, errors.New("a pasta não pode ser movida para dentro de si mesma")
+				}
+			}
+		}
+		folder.ParentID = parentID
+	}
+
+	if err := s.FolderDAL.UpdateFolder(folder); err != nil {
+		return nil, err
+	}
+
+	return s.folderResponse(folder)
+}
+
+// DeleteFolder remove a pasta. No modo mover (padrão) subpastas e itens vão
+// para a pasta pai; no modo cascata as subpastas também são removidas e os
+// itens de todas elas vão para a lixeira.
+func (s *FolderService) DeleteFolder(folderID uint, userID uint, modo string) (*types.FolderDeleteResult, error) {
+	if modo == "" {
+		modo = types.FolderDeleteMove
+	}
+	if modo != types.FolderDeleteMove && modo != types.FolderDeleteCascade {
+		return nil, errors.New("modo de exclusão inválido, use mover ou cascata")
+	}
+
+	folder, err := s.getFolder(folderID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &types.FolderDeleteResult{Modo: modo, PastasRemovidas: 1}
+	if modo == types.FolderDeleteMove {
+		if result.ItensMovidos, err = s.FolderDAL.DeleteFolderMovingContents(folder); err != nil {
+			return nil, err
+		}
+		return result, nil
+	}
+
+	folders, err := s.FolderDAL.GetFoldersByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := folderTree(folders, folder.ID)
+	if result.ItensExcluidos, err = s.FolderDAL.DeleteFolders(userID, ids); err != nil {
+		return nil, err
+	}
+	result.PastasRemovidas = len(ids)
+	return result, nil
+}
+
+func (s *FolderService) getFolder(folderID uint, userID uint) (*types.Folder, error) {
+	folder, err := s.FolderDAL.GetFolderByID(folderID, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("pasta não encontrada")
+		}
+		return nil, err
+	}
+	return folder, nil
+}
+
+func (s *FolderService) parentFolder(userID uint, parentID *uint) (*uint, error) {
+	if parentID == nil || *parentID == 0 {
+		return nil, nil
+	}
+
+	if _, err := s.FolderDAL.GetFolderByID(*parentID, userID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("pasta pai não encontrada")
+		}
+		return nil, err
+	}
+	return parentID, nil
+}
+
+func (s *FolderService) folderResponse(folder *types.Folder) (*types.FolderResponse, error) {
+	folders, err := s.FolderDAL.GetFoldersByUserID(folder.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	counts, err := s.FolderDAL.CountItemsByFolder(folder.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &types.FolderResponse{
+		ID:       folder.ID,
+		Nome:     folder.Nome,
+		ParentID: folder.ParentID,
+		Caminho:  folderPaths(folders)[folder.ID],
+		Itens:    counts[folder.ID],
+	}, nil
+}
+
+func validateFolderName(nome string) (string, error) {
+	nome = strings.TrimSpace(nome)
+	if nome == "" {
+		return "", errors.New("nome da pasta é obrigatório")
+	}
+	if strings.ContainsAny(nome, `/\`) {
+		return "", errors.New("o nome da pasta não pode conter / ou \\")
+	}
+	if utf8.RuneCountInString(nome) > maxFolderNameLength {
+		return "", errors.New("o nome da pasta deve ter no máximo 100 caracteres")
+	}
+	return nome, nil
+}
+
+// folderPaths monta o caminho de cada pasta a partir da raiz, com os nomes
+// separados por "/".
+func folderPaths(folders []types.Folder) map[uint]string {
+	byID := make(map[uint]*types.Folder, len(folders))
+	for i := range folders {
+		byID[folders[i].ID] = &folders[i]
+	}
+
+	paths := make(map[uint]string, len(folders))
+	var pathOf func(id uint, depth int) string
+	pathOf = func(id uint, depth int) string {
+		if path, ok := paths[id]; ok {
+			return path
+		}
+		folder := byID[id]
+		path := folder.Nome
+		// depth evita laço infinito caso o banco tenha um ciclo.
+		if folder.ParentID != nil && byID[*folder.ParentID] != nil && depth < len(folders) {
+			path = pathOf(*folder.ParentID, depth+1) + "/" + folder.Nome
+		}
+		paths[id] = path
+		return path
+	}
+
+	for _, folder := range folders {
+		pathOf(folder.ID, 0)
+	}
+	return paths
+}
+
+// folderTree devolve a pasta e todas as suas descendentes.
+func folderTree(folders []types.Folder, rootID uint) []uint {
+	children := make(map[uint][]uint)
+	for _, folder := range folders {
+		if folder.ParentID != nil {
+			children[*folder.ParentID] = append(children[*folder.ParentID], folder.ID)
+		}
+	}
+
+	ids := []uint{rootID}
+	seen := map[uint]bool{rootID: true}
+	for i := 0; i < len(ids); i++ {
+		for _, child := range children[ids[i]] {
+			if !seen[child] {
+				seen[child] = true
+				ids = append(ids, child)
+			}
+		}
+	}
+	return ids
+}
+
+// splitFolderPath separa um caminho de pasta como "Trabalho/Clientes" nos
+// nomes de cada nível, ignorando níveis vazios.
+func splitFolderPath(path string) ([]string, error) {
+	var names []string
+	for _, name := range strings.Split(path, "/") {
+		if strings.TrimSpace(name) == "" {
+			continue
+		}
+		nome, err := validateFolderName(name)
+		if err != nil {
+			return nil, err
+		}
+		names = append(names, nome)
+	}
+	return names, nil
+}
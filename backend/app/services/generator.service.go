@@ -0,0 +1,60 @@
+package services
+
+import (
+	"math"
+	"strings"
+
+	"github.com/Vicente/Password-Mobile-App/backend/app/generator"
+	"github.com/Vicente/Password-Mobile-App/backend/app/types"
+)
+
+func GeneratePassword(req *types.GenerateRequest) (*types.GenerateResponse, error) {
+	opts := generator.Options{
+		Modo:            generator.Mode(strings.ToLower(strings.TrimSpace(req.Modo))),
+		Tamanho:         req.Tamanho,
+		Minusculas:      enabled(req.Minusculas),
+		Maiusculas:      enabled(req.Maiusculas),
+		Numeros:         enabled(req.Numeros),
+		Simbolos:        enabled(req.Simbolos),
+		ExcluirAmbiguos: req.ExcluirAmbiguos,
+		MinMinusculas:   req.MinMinusculas,
+		MinMaiusculas:   req.MinMaiusculas,
+		MinNumeros:      req.MinNumeros,
+		MinSimbolos:     req.MinSimbolos,
+		Palavras:        req.Palavras,
+		Separador:       generator.DefaultSeparator,
+		Capitalizar:     req.Capitalizar,
+		IncluirNumero:   req.IncluirNumero,
+	}
+	if opts.Modo == "" {
+		opts.Modo = generator.Random
+	}
+	if req.Separador != nil {
+		opts.Separador = *req.Separador
+	}
+	if opts.Palavras == 0 {
+		opts.Palavras = generator.DefaultWords
+	}
+	if opts.Tamanho == 0 {
+		opts.Tamanho = generator.DefaultLength
+		if opts.Modo == generator.Pronounceable {
+			opts.Tamanho = generator.DefaultPronounceableLength
+		}
+	}
+
+	result, err := generator.Generate(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return &types.GenerateResponse{
+		Senha:    result.Senha,
+		Entropia: math.Round(result.Entropia*100) / 100,
+		Modo:     string(opts.Modo),
+	}, nil
+}
+
+// enabled trata uma opção omitida como ativada.
+func enabled(value *bool) bool {
+	return value == nil || *value
+}
@@ -0,0 +1,126 @@
+package services
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/Vicente/Password-Mobile-App/backend/app/types"
+)
+
+const defaultHealthMaxAgeDays = 180
+
+// HealthReport analisa os logins e redes Wi-Fi ativos do cofre sem decifrar
+// senhas: força e vazamento vêm da avaliação guardada com cada item e senhas
+// repetidas são agrupadas pelo HMAC guardado junto delas. A pontuação geral é a média das notas dos itens: 1 para itens sem problema,
+// 0,5 para itens apenas antigos e 0 para senhas fracas, repetidas ou vazadas.
+func (s *ItemService) HealthReport(userID uint, maxAgeDays int) (*types.HealthReport, error) {
+	zeroKnowledge, err := s.VaultService.IsZeroKnowledge(userID)
+	if err != nil {
+		return nil, err
+	}
+	if zeroKnowledge {
+		return nil, errors.New("o relatório de saúde não está disponível no modo zero-knowledge")
+	}
+
+	if maxAgeDays <= 0 {
+		maxAgeDays = s.HealthMaxAgeDays
+	}
+
+	all, err := s.ItemDAL.GetItemsByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]types.Item, 0, len(all))
+	for _, item := range all {
+		if len(item.Blob) == 0 && len(item.SenhaCifrada) > 0 && hasAccountPassword(itemType(&item)) {
+			items = append(items, item)
+		}
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].ID < items[j].ID })
+
+	now := time.Now()
+	report := &types.HealthReport{
+		Total:           len(items),
+		Reutilizadas:    []types.ReusedPasswordGroup{},
+		Fracas:          []types.HealthItem{},
+		Antigas:         []types.HealthItem{},
+		Vazadas:         []types.HealthItem{},
+		VerificaVazadas: s.BreachService.Enabled(),
+		DiasAntigas:     maxAgeDays,
+		GeradoEm:        now,
+	}
+
+	cutoff := now.AddDate(0, 0, -maxAgeDays)
+	groups := make(map[string][]types.HealthItem)
+	var groupOrder []string
+	problems := make(map[uint]bool)
+	old := make(map[uint]bool)
+
+	for _, item := range items {
+		entry := types.HealthItem{
+			ID:           item.ID,
+			Nome:         item.Nome,
+			Usuario:      item.Usuario,
+			URL:          item.URL,
+			Forca:        item.ForcaSenha,
+			AtualizadoEm: item.UpdatedAt,
+		}
+
+		if item.ForcaSenha != nil && *item.ForcaSenha < s.MinPasswordScore {
+			report.Fracas = append(report.Fracas, entry)
+			problems[item.ID] = true
+		}
+
+		if item.SenhaVazada != nil && *item.SenhaVazada {
+			report.Vazadas = append(report.Vazadas, entry)
+			problems[item.ID] = true
+		}
+
+		if item.UpdatedAt.Before(cutoff) {
+			report.Antigas = append(report.Antigas, entry)
+			old[item.ID] = true
+		}
+
+		if len(item.SenhaHMAC) > 0 {
+			key := string(item.SenhaHMAC)
+			if _, ok := groups[key]; !ok {
+				groupOrder = append(groupOrder, key)
+			}
+			groups[key] = append(groups[key], entry)
+		}
+	}
+
+	for _, key := range groupOrder {
+		if len(groups[key]) < 2 {
+			continue
+		}
+		report.Reutilizadas = append(report.Reutilizadas, types.ReusedPasswordGroup{Itens: groups[key]})
+		for _, entry := range groups[key] {
+			problems[entry.ID] = true
+		}
+	}
+
+	report.Pontuacao = vaultScore(items, problems, old)
+	return report, nil
+}
+
+func vaultScore(items []types.Item, problems map[uint]bool, old map[uint]bool) int {
+	if len(items) == 0 {
+		return 100
+	}
+
+	var total float64
+	for _, item := range items {
+		switch {
+		case problems[item.ID]:
+		case old[item.ID]:
+			total += 0.5
+		default:
+			total++
+		}
+	}
+	return int(math.Round(100 * total / float64(len(items))))
+}
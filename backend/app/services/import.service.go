@@ -0,0 +1,196 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/Vicente/Password-Mobile-App/backend/app/dal"
+	"github.com/Vicente/Password-Mobile-App/backend/app/importer"
+	"github.com/Vicente/Password-Mobile-App/backend/app/types"
+)
+
+// ImportCSV importa uma exportação CSV de outro gerenciador. Linhas com erro
+// ou com nome já existente são ignoradas e informadas no resultado; as demais
+// são gravadas em uma única transação. Com dryRun nada é gravado.
+func (s *ItemService) ImportCSV(userID uint, r io.Reader, dryRun bool) (*types.ImportResult, error) {
+	if err := s.checkImport(userID); err != nil {
+		return nil, err
+	}
+
+	format, records, rowErrors, err := importer.Parse(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.importRecords(userID, format, records, rowErrors, dryRun)
+}
+
+// ImportKDBX importa as entradas de um banco KeePass com as mesmas regras do
+// CSV. Entradas da lixeira do KeePass são ignoradas.
+func (s *ItemService) ImportKDBX(userID uint, r io.Reader, senha string, dryRun bool) (*types.ImportResult, error) {
+	if err := s.checkImport(userID); err != nil {
+		return nil, err
+	}
+
+	if senha == "" {
+		return nil, errors.New("senha do arquivo é obrigatória")
+	}
+
+	format, records, rowErrors, err := importer.ParseKDBX(r, senha)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.importRecords(userID, format, records, rowErrors, dryRun)
+}
+
+func (s *ItemService) checkImport(userID uint) error {
+	if userID == 0 {
+		return errors.New("usuário é obrigatório")
+	}
+
+	zeroKnowledge, err := s.VaultService.IsZeroKnowledge(userID)
+	if err != nil {
+		return err
+	}
+	if zeroKnowledge {
+		return errors.New("a importação não está disponível no modo zero-knowledge")
+	}
+
+	return nil
+}
+
+func (s *ItemService) importRecords(userID uint, format importer.Format, records []importer.Record, rowErrors []importer.RowError, dryRun bool) (*types.ImportResult, error) {
+	result := &types.ImportResult{
+		Formato:    string(format),
+		DryRun:     dryRun,
+		Total:      len(records) + len(rowErrors),
+		Duplicados: []types.ImportIssue{},
+		Erros:      make([]types.ImportIssue, 0, len(rowErrors)),
+		Avisos:     []types.ImportIssue{},
+	}
+	for _, rowErr := range rowErrors {
+		result.Erros = append(result.Erros, types.ImportIssue{Linha: rowErr.Linha, Motivo: rowErr.Motivo})
+	}
+
+	existing, err := s.ItemDAL.GetItemNamesByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make(map[string]int, len(existing)+len(records))
+	for _, name := range existing {
+		names[name] = 0
+	}
+
+	type pendingItem struct {
+		item  *types.Item
+		pasta []string
+		tags  []string
+	}
+
+	pending := make([]pendingItem, 0, len(records))
+	for _, record := range records {
+		if line, ok := names[record.Nome]; ok {
+			motivo := "já existe um item com este nome"
+			if line > 0 {
+				motivo = fmt.Sprintf("nome repetido na linha %d", line)
+			}
+			result.Duplicados = append(result.Duplicados, types.ImportIssue{Linha: record.Linha, Nome: record.Nome, Motivo: motivo})
+			continue
+		}
+		names[record.Nome] = record.Linha
+
+		pasta, err := splitFolderPath(record.Pasta)
+		if err != nil {
+			result.Erros = append(result.Erros, types.ImportIssue{Linha: record.Linha, Nome: record.Nome, Motivo: err.Error()})
+			continue
+		}
+
+		tags, err := normalizeTags(record.Tags)
+		if err != nil {
+			result.Erros = append(result.Erros, types.ImportIssue{Linha: record.Linha, Nome: record.Nome, Motivo: err.Error()})
+			continue
+		}
+
+		content := importContent(&record)
+		if err := validateItemContent(content); err != nil {
+			result.Erros = append(result.Erros, types.ImportIssue{Linha: record.Linha, Nome: record.Nome, Motivo: err.Error()})
+			continue
+		}
+
+		if record.Aviso != "" {
+			result.Avisos = append(result.Avisos, types.ImportIssue{Linha: record.Linha, Nome: record.Nome, Motivo: record.Aviso})
+		}
+
+		if dryRun {
+			result.Importados++
+			continue
+		}
+
+		item, err := s.newTypedItem(userID, record.Nome, content)
+		if err != nil {
+			return nil, err
+		}
+		pending = append(pending, pendingItem{item: item, pasta: pasta, tags: tags})
+	}
+
+	if dryRun || len(pending) == 0 {
+		return result, nil
+	}
+
+	// As pastas e tags que faltam são criadas na mesma transação dos itens.
+	err = s.ItemDAL.Transaction(func(tx *dal.ItemDAL) error {
+		folderDAL := dal.NewFolderDAL(tx.DB)
+		tagDAL := dal.NewTagDAL(tx.DB)
+		for _, p := range pending {
+			pastaID, err := folderDAL.EnsurePath(userID, p.pasta)
+			if err != nil {
+				return err
+			}
+			p.item.PastaID = pastaID
+
+			if p.item.Tags, err = tagDAL.FindOrCreateTags(userID, p.tags); err != nil {
+				return err
+			}
+
+			if err := tx.CreateItem(p.item); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result.Importados = len(pending)
+	return result, nil
+}
+
+// importContent monta o conteúdo de um item importado. Os campos que a origem
+// guardava protegidos viram campos personalizados ocultos.
+func importContent(record *importer.Record) *itemContent {
+	content := &itemContent{
+		Tipo:    types.ItemTypeLogin,
+		Usuario: record.Usuario,
+		URL:     record.URL,
+		Senha:   record.Senha,
+		Notas:   record.Notas,
+	}
+	if record.Nota {
+		content.Tipo = types.ItemTypeNote
+	} else {
+		content.Dados.TOTP = record.TOTP
+	}
+
+	for _, campo := range record.Campos {
+		tipo := types.CustomFieldText
+		if campo.Oculto {
+			tipo = types.CustomFieldHidden
+		}
+		content.Dados.Campos = append(content.Dados.Campos, types.CustomField{Nome: campo.Nome, Tipo: tipo, Valor: campo.Valor})
+	}
+	return content
+}
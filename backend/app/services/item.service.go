@@ -1,75 +1,829 @@
 package services
 
 import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
 	"errors"
 	"strings"
+	"time"
+	"unicode/utf8"
 
 	"github.com/Vicente/Password-Mobile-App/backend/app/dal"
+	"github.com/Vicente/Password-Mobile-App/backend/app/strength"
 	"github.com/Vicente/Password-Mobile-App/backend/app/types"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultHistoryLimit = 10
+	maxItemPageSize     = 200
+	maxItemSearchLength = 100
 )
 
 type ItemService struct {
-	ItemDAL *dal.ItemDAL
+	ItemDAL       *dal.ItemDAL
+	FolderDAL     *dal.FolderDAL
+	TagDAL        *dal.TagDAL
+	CryptoService *CryptoService
+	VaultService  *VaultService
+	BreachService *BreachService
+	HistoryLimit  int
+	// HealthMaxAgeDays é o padrão do relatório de saúde para considerar uma
+	// senha antiga.
+	HealthMaxAgeDays int
+	// MinPasswordScore é a força abaixo da qual o relatório de saúde aponta a
+	// senha do item como fraca, a mesma exigida da senha da conta.
+	MinPasswordScore int
 }
 
-func NewItemService(itemDAL *dal.ItemDAL) *ItemService {
+func NewItemService(itemDAL *dal.ItemDAL, folderDAL *dal.FolderDAL, tagDAL *dal.TagDAL, cryptoService *CryptoService, vaultService *VaultService, breachService *BreachService) *ItemService {
 	return &ItemService{
-		ItemDAL: itemDAL,
+		ItemDAL:          itemDAL,
+		FolderDAL:        folderDAL,
+		TagDAL:           tagDAL,
+		CryptoService:    cryptoService,
+		VaultService:     vaultService,
+		BreachService:    breachService,
+		HistoryLimit:     defaultHistoryLimit,
+		HealthMaxAgeDays: defaultHealthMaxAgeDays,
+		MinPasswordScore: defaultMinPasswordScore,
 	}
 }
 
 func (s *ItemService) CreateItem(req *types.CreateItemRequest) (*types.ItemResponse, error) {
+	zeroKnowledge, err := s.VaultService.IsZeroKnowledge(req.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	if zeroKnowledge {
+		return s.createBlobItem(req)
+	}
+
+	if strings.TrimSpace(req.Blob) != "" {
+		return nil, errors.New("blob cifrado só é aceito no modo zero-knowledge")
+	}
+
+	tipo, err := parseItemType(req.Tipo)
+	if err != nil {
+		return nil, err
+	}
+
 	nome := strings.TrimSpace(req.Nome)
 	if nome == "" {
 		return nil, errors.New("nome é obrigatório")
 	}
 
 	senha := strings.TrimSpace(req.Senha)
+	if req.Generate != nil {
+		if senha != "" {
+			return nil, errors.New("envie senha ou generate, não ambos")
+		}
+		generated, err := GeneratePassword(req.Generate)
+		if err != nil {
+			return nil, err
+		}
+		senha = generated.Senha
+	}
+
+	if req.UserID == 0 {
+		return nil, errors.New("usuário é obrigatório")
+	}
+
+	content := &itemContent{
+		Tipo:    tipo,
+		Usuario: strings.TrimSpace(req.Usuario),
+		URL:     strings.TrimSpace(req.URL),
+		Senha:   senha,
+		Notas:   strings.TrimSpace(req.Notas),
+		Dados: types.ItemData{
+			URLs:       req.URLs,
+			TOTP:       strings.TrimSpace(req.TOTP),
+			Cartao:     req.Cartao,
+			Identidade: req.Identidade,
+			WiFi:       req.WiFi,
+			Campos:     req.Campos,
+		},
+	}
+	if err := validateItemContent(content); err != nil {
+		return nil, err
+	}
+
+	item, err := s.newTypedItem(req.UserID, nome, content)
+	if err != nil {
+		return nil, err
+	}
+
+	if item.PastaID, err = s.resolveFolder(req.UserID, req.PastaID); err != nil {
+		return nil, err
+	}
+
+	if item.Tags, err = s.resolveTags(req.UserID, req.Tags); err != nil {
+		return nil, err
+	}
+
+	if err := s.ItemDAL.CreateItem(item); err != nil {
+		return nil, err
+	}
+
+	return s.toResponse(item)
+}
+
+// newTypedItem monta um item de qualquer tipo a partir do conteúdo já
+// validado.
+func (s *ItemService) newTypedItem(userID uint, nome string, content *itemContent) (*types.Item, error) {
+	// O ID entra no AAD dos campos cifrados e é reservado antes da gravação.
+	itemID, err := s.ItemDAL.NextItemID()
+	if err != nil {
+		return nil, err
+	}
+
+	item, err := s.newItem(userID, itemID, nome, content.Usuario, content.URL, content.Senha, content.Notas)
+	if err != nil {
+		return nil, err
+	}
+
+	item.Tipo = content.Tipo
+	if item.DadosCifrados, err = s.encryptItemData(userID, itemID, &content.Dados); err != nil {
+		return nil, err
+	}
+	s.ratePassword(item, content.Senha)
+	return item, nil
+}
+
+// newItem monta um login; os demais tipos passam por newTypedItem.
+func (s *ItemService) newItem(userID, itemID uint, nome, usuario, url, senha, notas string) (*types.Item, error) {
+	senhaCifrada, senhaHMAC, err := s.encryptPassword(userID, itemID, senha)
+	if err != nil {
+		return nil, err
+	}
+
+	notas = strings.TrimSpace(notas)
+	notasCifradas, err := s.encryptNotes(userID, itemID, notas)
+	if err != nil {
+		return nil, err
+	}
+
+	item := &types.Item{
+		Tipo:          types.ItemTypeLogin,
+		Nome:          nome,
+		Usuario:       strings.TrimSpace(usuario),
+		URL:           strings.TrimSpace(url),
+		Senha:         senha,
+		SenhaCifrada:  senhaCifrada,
+		SenhaHMAC:     senhaHMAC,
+		Notas:         notas,
+		NotasCifradas: notasCifradas,
+		UserID:        userID,
+	}
+	item.ID = itemID
+	return item, nil
+}
+
+// encryptPassword cifra a senha e calcula seu HMAC. Itens sem senha, como
+// notas e identidades, ficam com os dois vazios.
+func (s *ItemService) encryptPassword(userID, itemID uint, senha string) ([]byte, []byte, error) {
 	if senha == "" {
-		return nil, errors.New("senha é obrigatória")
+		return nil, nil, nil
+	}
+
+	senhaCifrada, err := s.CryptoService.EncryptField(userID, itemID, FieldSenha, senha)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	senhaHMAC, err := s.CryptoService.PasswordMAC(userID, senha)
+	if err != nil {
+		return nil, nil, err
+	}
+	return senhaCifrada, senhaHMAC, nil
+}
+
+func (s *ItemService) encryptNotes(userID, itemID uint, notas string) ([]byte, error) {
+	if notas == "" {
+		return nil, nil
+	}
+	return s.CryptoService.EncryptField(userID, itemID, FieldNotas, notas)
+}
+
+func (s *ItemService) createBlobItem(req *types.CreateItemRequest) (*types.ItemResponse, error) {
+	if strings.TrimSpace(req.Tipo+req.Nome+req.Usuario+req.URL+req.Senha+req.TOTP+req.Notas) != "" || len(req.URLs) > 0 ||
+		req.Cartao != nil || req.Identidade != nil || req.WiFi != nil || len(req.Campos) > 0 || req.Generate != nil || req.PastaID != nil || len(req.Tags) > 0 {
+		return nil, errors.New("no modo zero-knowledge envie apenas o blob cifrado")
 	}
 
 	if req.UserID == 0 {
 		return nil, errors.New("usuário é obrigatório")
 	}
 
+	blob, err := DecodeBlob(req.Blob)
+	if err != nil {
+		return nil, err
+	}
+
 	item := &types.Item{
-		Nome:   nome,
-		Senha:  senha,
+		Blob:   blob,
 		UserID: req.UserID,
 	}
 
-	if err := s.ItemDAL.CreateItem(item); err != nil {
+	if err := s.ItemDAL.CreateBlobItem(item); err != nil {
 		return nil, err
 	}
 
-	return &types.ItemResponse{
-		ID:     item.ID,
-		Nome:   item.Nome,
-		Senha:  item.Senha,
-		UserID: item.UserID,
-	}, nil
+	return s.toResponse(item)
 }
 
-func (s *ItemService) GetItemsByUser(userID uint) ([]types.ItemResponse, error) {
-	items, err := s.ItemDAL.GetItemsByUserID(userID)
+// GetItemsByUser lista uma página dos itens do usuário. Sem limite vêm todos
+// os itens do filtro; com limite, Cursor da página aponta para a próxima e
+// fica vazio na última.
+func (s *ItemService) GetItemsByUser(userID uint, filter *types.ItemFilter) (*types.ItemPage, error) {
+	if err := validateItemFilter(filter); err != nil {
+		return nil, err
+	}
+
+	// Um item a mais indica se existe próxima página.
+	limite := filter.Limite
+	if limite > 0 {
+		filter.Limite++
+	}
+
+	items, total, err := s.ItemDAL.ListItems(userID, filter)
 	if err != nil {
 		return nil, err
 	}
 
-	var response []types.ItemResponse
-	for _, item := range items {
-		response = append(response, types.ItemResponse{
-			ID:     item.ID,
-			Nome:   item.Nome,
-			Senha:  item.Senha,
-			UserID: item.UserID,
-		})
+	page := &types.ItemPage{Itens: make([]types.ItemResponse, 0, len(items)), Total: total}
+	if limite > 0 && len(items) > limite {
+		items = items[:limite]
+		if page.Cursor, err = encodeItemCursor(filter, &items[limite-1]); err != nil {
+			return nil, err
+		}
 	}
 
+	for i := range items {
+		itemResponse, err := s.toResponse(&items[i])
+		if err != nil {
+			return nil, err
+		}
+		maskListResponse(itemResponse)
+		page.Itens = append(page.Itens, *itemResponse)
+	}
+
+	return page, nil
+}
+
+// GetItem devolve um item com todos os campos, inclusive os campos
+// personalizados ocultos, que a listagem mascara.
+func (s *ItemService) GetItem(itemID uint, userID uint) (*types.ItemResponse, error) {
+	if itemID == 0 {
+		return nil, errors.New("ID do item é obrigatório")
+	}
+
+	if userID == 0 {
+		return nil, errors.New("usuário é obrigatório")
+	}
+
+	item, err := s.getItem(itemID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := s.toResponse(item)
+	if err != nil {
+		return nil, err
+	}
+	// Só aqui a força é reavaliada, para trazer aviso e sugestões.
+	if response.Forca != nil {
+		response.Forca = passwordStrength(response.Senha, item.Nome, item.Usuario)
+	}
+	return response, nil
+}
+
+func validateItemFilter(filter *types.ItemFilter) error {
+	if filter.Ordem == "" {
+		filter.Ordem = types.ItemSortName
+	}
+	if filter.Ordem != types.ItemSortName && filter.Ordem != types.ItemSortCreated && filter.Ordem != types.ItemSortUpdated {
+		return errors.New("ordem inválida, use nome, criado ou atualizado")
+	}
+
+	if filter.Tipo != "" {
+		tipo, err := parseItemType(filter.Tipo)
+		if err != nil {
+			return err
+		}
+		filter.Tipo = tipo
+	}
+
+	if filter.Limite < 0 || filter.Limite > maxItemPageSize {
+		return errors.New("limite deve estar entre 1 e 200")
+	}
+
+	filter.Busca = strings.TrimSpace(filter.Busca)
+	if utf8.RuneCountInString(filter.Busca) > maxItemSearchLength {
+		return errors.New("a busca deve ter no máximo 100 caracteres")
+	}
+
+	// As notas são cifradas com a chave de cada usuário; buscá-las exigiria
+	// decifrar o cofre inteiro a cada página, então o pedido é recusado em vez
+	// de ignorado.
+	for _, campo := range filter.Campos {
+		switch campo {
+		case types.ItemSearchName, types.ItemSearchURL:
+		case "notas":
+			return errors.New("a busca não inclui as notas, que são guardadas criptografadas")
+		default:
+			return errors.New("campo de busca inválido, use nome ou url")
+		}
+	}
+
+	if filter.Cursor == "" {
+		return nil
+	}
+	if filter.Limite == 0 {
+		return errors.New("cursor exige limite")
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(filter.Cursor)
+	if err != nil {
+		return errors.New("cursor inválido")
+	}
+	var cursor types.ItemCursor
+	if err := json.Unmarshal(data, &cursor); err != nil || cursor.ID == 0 {
+		return errors.New("cursor inválido")
+	}
+	// O cursor só vale para a ordem em que foi gerado.
+	if cursor.Ordem != filter.Ordem || cursor.Decrescente != filter.Decrescente {
+		return errors.New("cursor inválido")
+	}
+	filter.Apos = &cursor
+	return nil
+}
+
+func encodeItemCursor(filter *types.ItemFilter, item *types.Item) (string, error) {
+	cursor := types.ItemCursor{Ordem: filter.Ordem, Decrescente: filter.Decrescente, ID: item.ID}
+	switch filter.Ordem {
+	case types.ItemSortName:
+		cursor.Nome = item.Nome
+	case types.ItemSortCreated:
+		cursor.Data = item.CreatedAt
+	case types.ItemSortUpdated:
+		cursor.Data = item.UpdatedAt
+	}
+
+	data, err := json.Marshal(cursor)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+func (s *ItemService) toResponse(item *types.Item) (*types.ItemResponse, error) {
+	if len(item.Blob) > 0 {
+		return &types.ItemResponse{
+			ID:      item.ID,
+			Blob:    EncodeBlob(item.Blob),
+			Revisao: item.Revisao,
+			UserID:  item.UserID,
+		}, nil
+	}
+
+	content, err := s.itemContent(item)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &types.ItemResponse{
+		ID:         item.ID,
+		Tipo:       content.Tipo,
+		Nome:       item.Nome,
+		Usuario:    content.Usuario,
+		URL:        content.URL,
+		URLs:       content.Dados.URLs,
+		Senha:      content.Senha,
+		TOTP:       content.Dados.TOTP,
+		TemTOTP:    content.Dados.TOTP != "",
+		Notas:      content.Notas,
+		Cartao:     content.Dados.Cartao,
+		Identidade: content.Dados.Identidade,
+		WiFi:       content.Dados.WiFi,
+		Campos:     content.Dados.Campos,
+		PastaID:    item.PastaID,
+		Tags:       tagNames(item.Tags),
+		Revisao:    item.Revisao,
+		UserID:     item.UserID,
+	}
+	if item.ForcaSenha != nil {
+		response.Forca = &types.PasswordStrength{Pontuacao: *item.ForcaSenha}
+	}
+	response.Vazada = item.SenhaVazada
 	return response, nil
 }
 
+// passwordStrength avalia a senha de um item; o nome e o usuário do item são
+// as primeiras coisas que um atacante tentaria.
+func passwordStrength(senha string, userInputs ...string) *types.PasswordStrength {
+	result := strength.Estimate(senha, userInputs...)
+	return &types.PasswordStrength{
+		Pontuacao: result.Pontuacao,
+		Aviso:     result.Aviso,
+		Sugestoes: result.Sugestoes,
+	}
+}
+
+// ratePassword guarda no item a força da senha e se ela está vazada, para
+// que a listagem não precise avaliar cada senha. Sem senha de conta os dois
+// ficam vazios.
+func (s *ItemService) ratePassword(item *types.Item, senha string) {
+	item.ForcaSenha, item.SenhaVazada = nil, nil
+	if senha == "" || len(item.Blob) > 0 || !hasAccountPassword(itemType(item)) {
+		return
+	}
+
+	forca := strength.Estimate(senha, item.Nome, item.Usuario).Pontuacao
+	item.ForcaSenha = &forca
+	item.SenhaVazada = s.BreachService.Check(senha)
+}
+
+func (s *ItemService) UpdateItem(req *types.UpdateItemRequest) (*types.ItemResponse, error) {
+	if req.ID == 0 {
+		return nil, errors.New("ID do item é obrigatório")
+	}
+
+	if req.UserID == 0 {
+		return nil, errors.New("usuário é obrigatório")
+	}
+
+	if req.Revisao == 0 {
+		return nil, errors.New("revisão do item é obrigatória")
+	}
+
+	item, err := s.getItem(req.ID, req.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	previous := *item
+
+	if len(item.Blob) > 0 {
+		if err := applyBlobUpdate(item, req); err != nil {
+			return nil, err
+		}
+	} else if err := s.applyUpdate(item, req); err != nil {
+		return nil, err
+	}
+
+	if err := s.saveWithHistory(item, &previous, req.Revisao); err != nil {
+		return nil, err
+	}
+
+	return s.toResponse(item)
+}
+
+func (s *ItemService) GetItemHistory(itemID uint, userID uint) ([]types.ItemHistoryResponse, error) {
+	if _, err := s.getItem(itemID, userID); err != nil {
+		return nil, err
+	}
+
+	history, err := s.ItemDAL.GetHistoryByItemID(itemID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]types.ItemHistoryResponse, 0, len(history))
+	for _, entry := range history {
+		historyResponse := types.ItemHistoryResponse{
+			Versao:   entry.Versao,
+			CriadoEm: entry.CreatedAt,
+		}
+
+		if len(entry.Blob) > 0 {
+			historyResponse.Blob = EncodeBlob(entry.Blob)
+		} else {
+			senha, err := s.CryptoService.DecryptField(userID, itemID, FieldSenha, entry.SenhaCifrada)
+			if err != nil {
+				return nil, err
+			}
+			historyResponse.Senha = senha
+		}
+
+		response = append(response, historyResponse)
+	}
+
+	return response, nil
+}
+
+func (s *ItemService) RestoreItemVersion(itemID uint, userID uint, versao uint, revisao uint) (*types.ItemResponse, error) {
+	if revisao == 0 {
+		return nil, errors.New("revisão do item é obrigatória")
+	}
+
+	item, err := s.getItem(itemID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	entry, err := s.ItemDAL.GetHistoryVersion(itemID, userID, versao)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("versão não encontrada no histórico do item")
+		}
+		return nil, err
+	}
+
+	// Um item que mudou para um tipo sem senha não pode receber uma de volta.
+	if len(entry.SenhaCifrada) > 0 && !hasPasswordField(itemType(item)) {
+		return nil, errors.New("itens deste tipo não têm senha para restaurar")
+	}
+
+	previous := *item
+	item.SenhaCifrada = entry.SenhaCifrada
+	item.Blob = entry.Blob
+
+	var senha string
+	if len(entry.SenhaCifrada) > 0 {
+		if senha, err = s.CryptoService.DecryptField(userID, itemID, FieldSenha, entry.SenhaCifrada); err != nil {
+			return nil, err
+		}
+		if item.SenhaHMAC, err = s.CryptoService.PasswordMAC(userID, senha); err != nil {
+			return nil, err
+		}
+	}
+	s.ratePassword(item, senha)
+
+	if err := s.saveWithHistory(item, &previous, revisao); err != nil {
+		return nil, err
+	}
+
+	return s.toResponse(item)
+}
+
+func (s *ItemService) getItem(itemID uint, userID uint) (*types.Item, error) {
+	item, err := s.ItemDAL.GetItemByID(itemID, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("item não encontrado ou você não tem acesso a ele")
+		}
+		return nil, err
+	}
+	return item, nil
+}
+
+func (s *ItemService) saveWithHistory(item *types.Item, previous *types.Item, revisao uint) error {
+	changed, err := s.secretChanged(previous, item)
+	if err != nil {
+		return err
+	}
+
+	return s.ItemDAL.Transaction(func(tx *dal.ItemDAL) error {
+		if err := tx.UpdateItem(item, revisao); err != nil {
+			return err
+		}
+
+		if err := tx.ReplaceItemTags(item, item.Tags); err != nil {
+			return err
+		}
+
+		if !changed || s.HistoryLimit <= 0 {
+			return nil
+		}
+
+		history := &types.ItemHistory{
+			ItemID:       previous.ID,
+			UserID:       previous.UserID,
+			Versao:       previous.Revisao,
+			SenhaCifrada: previous.SenhaCifrada,
+			Blob:         previous.Blob,
+		}
+		if err := tx.CreateHistory(history); err != nil {
+			return err
+		}
+
+		return tx.PruneHistory(item.ID, s.HistoryLimit)
+	})
+}
+
+func (s *ItemService) secretChanged(previous *types.Item, item *types.Item) (bool, error) {
+	if len(previous.Blob) > 0 || len(item.Blob) > 0 {
+		return !bytes.Equal(previous.Blob, item.Blob), nil
+	}
+
+	// Sem senha anterior não há o que guardar no histórico.
+	if len(previous.SenhaCifrada) == 0 || bytes.Equal(previous.SenhaCifrada, item.SenhaCifrada) {
+		return false, nil
+	}
+	if len(item.SenhaCifrada) == 0 {
+		return true, nil
+	}
+
+	previousSenha, err := s.CryptoService.DecryptField(previous.UserID, previous.ID, FieldSenha, previous.SenhaCifrada)
+	if err != nil {
+		return false, err
+	}
+
+	senha, err := s.CryptoService.DecryptField(item.UserID, item.ID, FieldSenha, item.SenhaCifrada)
+	if err != nil {
+		return false, err
+	}
+
+	return previousSenha != senha, nil
+}
+
+func (s *ItemService) applyUpdate(item *types.Item, req *types.UpdateItemRequest) error {
+	if req.Blob != nil {
+		return errors.New("blob cifrado só é aceito no modo zero-knowledge")
+	}
+
+	if !req.Parcial && req.Nome == nil {
+		return errors.New("nome é obrigatório")
+	}
+
+	if req.Nome != nil {
+		nome := strings.TrimSpace(*req.Nome)
+		if nome == "" {
+			return errors.New("nome é obrigatório")
+		}
+		item.Nome = nome
+	}
+
+	content, err := s.itemContent(item)
+	if err != nil {
+		return err
+	}
+	previousSenha, previousNotas := content.Senha, content.Notas
+
+	// No PUT os campos ausentes são limpos, inclusive o tipo, que volta a ser
+	// login. No PATCH, trocar o tipo descarta os campos do tipo anterior.
+	if req.Tipo != nil || !req.Parcial {
+		tipo, err := parseItemType(valueOf(req.Tipo))
+		if err != nil {
+			return err
+		}
+		if tipo != content.Tipo {
+			content.Tipo = tipo
+			content.keepTypeFields()
+		}
+	}
+
+	if req.Senha != nil || !req.Parcial {
+		content.Senha = strings.TrimSpace(valueOf(req.Senha))
+	}
+
+	if req.Usuario != nil || !req.Parcial {
+		content.Usuario = strings.TrimSpace(valueOf(req.Usuario))
+	}
+
+	if req.URL != nil || !req.Parcial {
+		content.URL = strings.TrimSpace(valueOf(req.URL))
+	}
+
+	if req.URLs != nil || !req.Parcial {
+		content.Dados.URLs = nil
+		if req.URLs != nil {
+			content.Dados.URLs = *req.URLs
+		}
+	}
+
+	// O segredo não vem na listagem, então um totp vazio ou ausente mantém o
+	// guardado; só removerTotp o apaga.
+	if totp := strings.TrimSpace(valueOf(req.TOTP)); req.RemoverTOTP {
+		if totp != "" {
+			return errors.New("envie totp ou removerTotp, não ambos")
+		}
+		content.Dados.TOTP = ""
+	} else if totp != "" {
+		content.Dados.TOTP = totp
+	}
+
+	if req.Notas != nil || !req.Parcial {
+		content.Notas = strings.TrimSpace(valueOf(req.Notas))
+	}
+
+	if req.Cartao != nil || !req.Parcial {
+		content.Dados.Cartao = req.Cartao
+	}
+
+	if req.Identidade != nil || !req.Parcial {
+		content.Dados.Identidade = req.Identidade
+	}
+
+	if req.WiFi != nil || !req.Parcial {
+		content.Dados.WiFi = req.WiFi
+	}
+
+	if req.Campos != nil || !req.Parcial {
+		stored := content.Dados.Campos
+		content.Dados.Campos = nil
+		if req.Campos != nil {
+			content.Dados.Campos = unmaskCustomFields(*req.Campos, stored)
+		}
+	}
+
+	if err := validateItemContent(content); err != nil {
+		return err
+	}
+
+	item.Tipo = content.Tipo
+	item.Usuario = content.Usuario
+	item.URL = content.URL
+
+	if content.Senha != previousSenha {
+		if item.SenhaCifrada, item.SenhaHMAC, err = s.encryptPassword(item.UserID, item.ID, content.Senha); err != nil {
+			return err
+		}
+		item.Senha = content.Senha
+	}
+
+	if content.Notas != previousNotas {
+		if item.NotasCifradas, err = s.encryptNotes(item.UserID, item.ID, content.Notas); err != nil {
+			return err
+		}
+	}
+
+	// Nome, usuário e tipo também entram na avaliação da senha.
+	s.ratePassword(item, content.Senha)
+
+	if item.DadosCifrados, err = s.encryptItemData(item.UserID, item.ID, &content.Dados); err != nil {
+		return err
+	}
+
+	if req.PastaID != nil || !req.Parcial {
+		pastaID, err := s.resolveFolder(item.UserID, req.PastaID)
+		if err != nil {
+			return err
+		}
+		item.PastaID = pastaID
+	}
+
+	if req.Tags != nil || !req.Parcial {
+		var names []string
+		if req.Tags != nil {
+			names = *req.Tags
+		}
+		tags, err := s.resolveTags(item.UserID, names)
+		if err != nil {
+			return err
+		}
+		item.Tags = tags
+	}
+
+	return nil
+}
+
+// resolveFolder confere se a pasta é do usuário; nil ou 0 deixa o item fora
+// de pastas.
+func (s *ItemService) resolveFolder(userID uint, pastaID *uint) (*uint, error) {
+	if pastaID == nil || *pastaID == 0 {
+		return nil, nil
+	}
+
+	if _, err := s.FolderDAL.GetFolderByID(*pastaID, userID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("pasta não encontrada")
+		}
+		return nil, err
+	}
+	return pastaID, nil
+}
+
+// resolveTags devolve as tags do item pelo nome, criando as novas.
+func (s *ItemService) resolveTags(userID uint, names []string) ([]types.Tag, error) {
+	names, err := normalizeTags(names)
+	if err != nil {
+		return nil, err
+	}
+	return s.TagDAL.FindOrCreateTags(userID, names)
+}
+
+func valueOf(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
+
+func applyBlobUpdate(item *types.Item, req *types.UpdateItemRequest) error {
+	if req.Tipo != nil || req.Nome != nil || req.Usuario != nil || req.URL != nil || req.URLs != nil || req.Senha != nil || req.TOTP != nil || req.RemoverTOTP ||
+		req.Notas != nil || req.Cartao != nil || req.Identidade != nil || req.WiFi != nil || req.Campos != nil || req.PastaID != nil || req.Tags != nil {
+		return errors.New("no modo zero-knowledge envie apenas o blob cifrado")
+	}
+
+	if req.Blob == nil {
+		if req.Parcial {
+			return nil
+		}
+		return errors.New("blob cifrado é obrigatório")
+	}
+
+	blob, err := DecodeBlob(*req.Blob)
+	if err != nil {
+		return err
+	}
+	item.Blob = blob
+	return nil
+}
+
 func (s *ItemService) DeleteItem(itemID uint, userID uint) error {
 	if itemID == 0 {
 		return errors.New("ID do item é obrigatório")
@@ -81,3 +835,199 @@ func (s *ItemService) DeleteItem(itemID uint, userID uint) error {
 
 	return s.ItemDAL.DeleteItem(itemID, userID)
 }
+
+func (s *ItemService) GetTrash(userID uint) ([]types.TrashItemResponse, error) {
+	items, err := s.ItemDAL.GetDeletedItemsByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]types.TrashItemResponse, 0, len(items))
+	for i := range items {
+		itemResponse, err := s.toResponse(&items[i])
+		if err != nil {
+			return nil, err
+		}
+		maskListResponse(itemResponse)
+
+		response = append(response, types.TrashItemResponse{
+			ItemResponse: *itemResponse,
+			ExcluidoEm:   items[i].DeletedAt.Time,
+		})
+	}
+
+	return response, nil
+}
+
+func (s *ItemService) RestoreFromTrash(itemID uint, userID uint) (*types.ItemResponse, error) {
+	item, err := s.ItemDAL.GetDeletedItemByID(itemID, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("item não encontrado na lixeira")
+		}
+		return nil, err
+	}
+
+	if err := s.ItemDAL.RestoreItem(item); err != nil {
+		return nil, err
+	}
+
+	return s.toResponse(item)
+}
+
+func (s *ItemService) DeleteFromTrash(itemID uint, userID uint) error {
+	item, err := s.ItemDAL.GetDeletedItemByID(itemID, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("item não encontrado na lixeira")
+		}
+		return err
+	}
+
+	return s.ItemDAL.PurgeItems([]uint{item.ID})
+}
+
+func (s *ItemService) PurgeTrash(retention time.Duration) (int, error) {
+	ids, err := s.ItemDAL.GetItemIDsDeletedBefore(time.Now().Add(-retention))
+	if err != nil {
+		return 0, err
+	}
+
+	if err := s.ItemDAL.PurgeItems(ids); err != nil {
+		return 0, err
+	}
+
+	return len(ids), nil
+}
+
+func (s *ItemService) EncryptLegacyItems() (int, error) {
+	items, err := s.ItemDAL.GetLegacyPlaintextItems()
+	if err != nil {
+		return 0, err
+	}
+
+	for i, item := range items {
+		senhaCifrada, err := s.CryptoService.EncryptField(item.UserID, item.ID, FieldSenha, item.Senha)
+		if err != nil {
+			return i, err
+		}
+
+		if err := s.ItemDAL.ReplaceLegacyPassword(item.ID, senhaCifrada); err != nil {
+			return i, err
+		}
+	}
+
+	return len(items), nil
+}
+
+// BindItemFields cifra de novo, com o item e o campo no AAD, os campos de
+// itens e do histórico gravados no formato em que o AAD só identificava o
+// usuário. Roda antes das demais migrações, que já leem o formato novo.
+func (s *ItemService) BindItemFields() (int, error) {
+	items, err := s.ItemDAL.GetItemsWithUnboundFields(cipherVersion)
+	if err != nil {
+		return 0, err
+	}
+
+	for i := range items {
+		item := &items[i]
+		fields := []struct {
+			name  string
+			value *[]byte
+		}{
+			{FieldSenha, &item.SenhaCifrada},
+			{FieldNotas, &item.NotasCifradas},
+			{FieldDados, &item.DadosCifrados},
+		}
+		for _, field := range fields {
+			if *field.value, err = s.bindField(item.UserID, item.ID, field.name, *field.value); err != nil {
+				return i, err
+			}
+		}
+
+		if err := s.ItemDAL.SetItemCiphertexts(item); err != nil {
+			return i, err
+		}
+	}
+
+	history, err := s.ItemDAL.GetHistoryWithUnboundPassword(cipherVersion)
+	if err != nil {
+		return len(items), err
+	}
+
+	for _, entry := range history {
+		senhaCifrada, err := s.bindField(entry.UserID, entry.ItemID, FieldSenha, entry.SenhaCifrada)
+		if err != nil {
+			return len(items), err
+		}
+		if err := s.ItemDAL.SetHistoryPassword(entry.ID, senhaCifrada); err != nil {
+			return len(items), err
+		}
+	}
+
+	return len(items) + len(history), nil
+}
+
+func (s *ItemService) bindField(userID, itemID uint, field string, ciphertext []byte) ([]byte, error) {
+	if len(ciphertext) == 0 || IsFieldBound(ciphertext) {
+		return ciphertext, nil
+	}
+
+	plaintext, err := s.CryptoService.DecryptString(userID, ciphertext)
+	if err != nil {
+		return nil, err
+	}
+	return s.CryptoService.EncryptField(userID, itemID, field, plaintext)
+}
+
+// BackfillPasswordRatings avalia as senhas de itens gravados antes da força
+// e do vazamento serem guardados com o item, e as que ainda não foram
+// conferidas contra o conjunto de senhas vazadas, agora configurado.
+func (s *ItemService) BackfillPasswordRatings() (int, error) {
+	items, err := s.ItemDAL.GetItemsWithoutPasswordRating([]string{types.ItemTypeLogin, types.ItemTypeWiFi}, s.BreachService.Enabled())
+	if err != nil {
+		return 0, err
+	}
+
+	for i := range items {
+		item := &items[i]
+		senha, err := s.CryptoService.DecryptField(item.UserID, item.ID, FieldSenha, item.SenhaCifrada)
+		if err != nil {
+			return i, err
+		}
+
+		s.ratePassword(item, senha)
+		if err := s.ItemDAL.SetPasswordRating(item.ID, item.ForcaSenha, item.SenhaVazada); err != nil {
+			return i, err
+		}
+	}
+
+	return len(items), nil
+}
+
+// BackfillPasswordMACs calcula o HMAC das senhas de itens gravados antes da
+// coluna existir, inclusive os da lixeira.
+func (s *ItemService) BackfillPasswordMACs() (int, error) {
+	items, err := s.ItemDAL.GetItemsWithoutPasswordMAC()
+	if err != nil {
+		return 0, err
+	}
+
+	for i, item := range items {
+		senha, err := s.CryptoService.DecryptField(item.UserID, item.ID, FieldSenha, item.SenhaCifrada)
+		if err != nil {
+			return i, err
+		}
+
+		senhaHMAC, err := s.CryptoService.PasswordMAC(item.UserID, senha)
+		if err != nil {
+			return i, err
+		}
+
+		if err := s.ItemDAL.SetPasswordMAC(item.ID, senhaHMAC); err != nil {
+			return i, err
+		}
+	}
+
+	return len(items), nil
+}
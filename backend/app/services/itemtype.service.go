@@ -0,0 +1,558 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/mail"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/Vicente/Password-Mobile-App/backend/app/otp"
+	"github.com/Vicente/Password-Mobile-App/backend/app/types"
+)
+
+const (
+	maxItemURLs         = 20
+	maxCustomFields     = 50
+	maxCustomFieldName  = 100
+	maxCustomFieldValue = 5000
+)
+
+// itemTypeFields lista, por tipo, os campos além de nome e notas que o item
+// aceita.
+var itemTypeFields = map[string][]string{
+	types.ItemTypeLogin:    {"usuario", "url", "urls", "senha", "totp"},
+	types.ItemTypeCard:     {"cartao", "senha"},
+	types.ItemTypeIdentity: {"identidade"},
+	types.ItemTypeNote:     {},
+	types.ItemTypeWiFi:     {"wifi", "senha"},
+}
+
+// hasPasswordField informa se o tipo de item guarda uma senha.
+func hasPasswordField(tipo string) bool {
+	for _, field := range itemTypeFields[tipo] {
+		if field == "senha" {
+			return true
+		}
+	}
+	return false
+}
+
+// itemContent é o conteúdo decifrado de um item não zero-knowledge, na forma
+// em que é validado antes de ser gravado.
+type itemContent struct {
+	Tipo    string
+	Usuario string
+	URL     string
+	Senha   string
+	Notas   string
+	Dados   types.ItemData
+}
+
+func parseItemType(tipo string) (string, error) {
+	tipo = strings.ToLower(strings.TrimSpace(tipo))
+	if tipo == "" {
+		return types.ItemTypeLogin, nil
+	}
+	if _, ok := itemTypeFields[tipo]; !ok {
+		return "", errors.New("tipo de item inválido, use login, cartao, identidade, nota ou wifi")
+	}
+	return tipo, nil
+}
+
+// itemType trata como login os itens gravados antes dos tipos existirem.
+func itemType(item *types.Item) string {
+	if item.Tipo == "" {
+		return types.ItemTypeLogin
+	}
+	return item.Tipo
+}
+
+// hasAccountPassword indica se a senha do item é de uma conta ou rede, e por
+// isso entra na avaliação de força, de vazamentos e no relatório de saúde. A
+// senha de um cartão é um PIN e fica de fora.
+func hasAccountPassword(tipo string) bool {
+	return tipo == types.ItemTypeLogin || tipo == types.ItemTypeWiFi
+}
+
+// set indica quais campos do conteúdo estão preenchidos, pelo nome usado na
+// API.
+func (c *itemContent) set() map[string]bool {
+	return map[string]bool{
+		"usuario":    c.Usuario != "",
+		"url":        c.URL != "",
+		"urls":       len(c.Dados.URLs) > 0,
+		"senha":      c.Senha != "",
+		"totp":       c.Dados.TOTP != "",
+		"cartao":     c.Dados.Cartao != nil,
+		"identidade": c.Dados.Identidade != nil,
+		"wifi":       c.Dados.WiFi != nil,
+	}
+}
+
+// keepTypeFields descarta os campos que não pertencem ao tipo atual; é usado
+// quando um PATCH troca o tipo do item.
+func (c *itemContent) keepTypeFields() {
+	allowed := make(map[string]bool)
+	for _, field := range itemTypeFields[c.Tipo] {
+		allowed[field] = true
+	}
+
+	if !allowed["usuario"] {
+		c.Usuario = ""
+	}
+	if !allowed["url"] {
+		c.URL = ""
+	}
+	if !allowed["urls"] {
+		c.Dados.URLs = nil
+	}
+	if !allowed["senha"] {
+		c.Senha = ""
+	}
+	if !allowed["totp"] {
+		c.Dados.TOTP = ""
+	}
+	if !allowed["cartao"] {
+		c.Dados.Cartao = nil
+	}
+	if !allowed["identidade"] {
+		c.Dados.Identidade = nil
+	}
+	if !allowed["wifi"] {
+		c.Dados.WiFi = nil
+	}
+}
+
+// validateItemContent confere o conteúdo conforme o tipo do item e normaliza
+// os campos (número do cartão só com dígitos, CPF formatado e assim por
+// diante).
+func validateItemContent(c *itemContent) error {
+	allowed := make(map[string]bool)
+	for _, field := range itemTypeFields[c.Tipo] {
+		allowed[field] = true
+	}
+	set := c.set()
+	for _, field := range []string{"usuario", "url", "urls", "senha", "totp", "cartao", "identidade", "wifi"} {
+		if set[field] && !allowed[field] {
+			return fmt.Errorf("o campo %s não se aplica a itens do tipo %s", field, c.Tipo)
+		}
+	}
+
+	campos, err := validateCustomFields(c.Dados.Campos)
+	if err != nil {
+		return err
+	}
+	c.Dados.Campos = campos
+
+	switch c.Tipo {
+	case types.ItemTypeLogin:
+		if c.Senha == "" {
+			return errors.New("senha é obrigatória")
+		}
+		urls, err := normalizeURLs(c.Dados.URLs)
+		if err != nil {
+			return err
+		}
+		c.Dados.URLs = urls
+		if c.Dados.TOTP != "" {
+			if c.Dados.TOTP, err = validateTOTPSeed(c.Dados.TOTP); err != nil {
+				return err
+			}
+		}
+	case types.ItemTypeCard:
+		if c.Dados.Cartao == nil {
+			return errors.New("dados do cartão são obrigatórios")
+		}
+		return validateCard(c.Dados.Cartao)
+	case types.ItemTypeIdentity:
+		if c.Dados.Identidade == nil {
+			return errors.New("dados da identidade são obrigatórios")
+		}
+		return validateIdentity(c.Dados.Identidade)
+	case types.ItemTypeNote:
+		if c.Notas == "" {
+			return errors.New("o conteúdo da nota é obrigatório")
+		}
+	case types.ItemTypeWiFi:
+		if c.Dados.WiFi == nil {
+			return errors.New("dados da rede são obrigatórios")
+		}
+		return validateWiFi(c.Dados.WiFi, c.Senha)
+	}
+
+	return nil
+}
+
+func normalizeURLs(urls []string) ([]string, error) {
+	seen := make(map[string]bool, len(urls))
+	var normalized []string
+	for _, value := range urls {
+		value = strings.TrimSpace(value)
+		if value == "" || seen[value] {
+			continue
+		}
+		seen[value] = true
+		normalized = append(normalized, value)
+	}
+
+	if len(normalized) > maxItemURLs {
+		return nil, errors.New("um login pode ter no máximo 20 URLs")
+	}
+	return normalized, nil
+}
+
+// validateCustomFields confere os campos personalizados e normaliza os
+// valores: booleanos viram "true" ou "false" e datas ficam em AAAA-MM-DD. Os
+// nomes não se repetem no item, o que permite levá-los para o KeePass.
+func validateCustomFields(campos []types.CustomField) ([]types.CustomField, error) {
+	if len(campos) > maxCustomFields {
+		return nil, errors.New("um item pode ter no máximo 50 campos personalizados")
+	}
+
+	seen := make(map[string]bool, len(campos))
+	normalized := make([]types.CustomField, 0, len(campos))
+	for _, campo := range campos {
+		nome := strings.TrimSpace(campo.Nome)
+		if nome == "" {
+			return nil, errors.New("nome do campo personalizado é obrigatório")
+		}
+		if utf8.RuneCountInString(nome) > maxCustomFieldName {
+			return nil, errors.New("o nome do campo personalizado deve ter no máximo 100 caracteres")
+		}
+		if seen[nome] {
+			return nil, fmt.Errorf("campo personalizado %q repetido", nome)
+		}
+		seen[nome] = true
+
+		// Um campo mascarado sem valor veio da listagem; gravá-lo apagaria o
+		// valor que o cliente nunca viu.
+		if campo.Mascarado && campo.Valor == "" {
+			return nil, fmt.Errorf("o campo %q veio mascarado da listagem, consulte o item para editá-lo", nome)
+		}
+
+		tipo := strings.ToLower(strings.TrimSpace(campo.Tipo))
+		valor := campo.Valor
+		if utf8.RuneCountInString(valor) > maxCustomFieldValue {
+			return nil, fmt.Errorf("o campo %q deve ter no máximo 5000 caracteres", nome)
+		}
+
+		switch tipo {
+		case "":
+			tipo = types.CustomFieldText
+		case types.CustomFieldText, types.CustomFieldHidden:
+		case types.CustomFieldBoolean:
+			valor = strings.TrimSpace(valor)
+			if valor == "" {
+				valor = "false"
+			}
+			value, err := strconv.ParseBool(valor)
+			if err != nil {
+				return nil, fmt.Errorf("o campo %q deve ser true ou false", nome)
+			}
+			valor = strconv.FormatBool(value)
+		case types.CustomFieldURL:
+			valor = strings.TrimSpace(valor)
+			if valor != "" {
+				parsed, err := url.Parse(valor)
+				if err != nil || parsed.Scheme == "" || (parsed.Host == "" && parsed.Opaque == "") {
+					return nil, fmt.Errorf("o campo %q deve ser uma URL", nome)
+				}
+			}
+		case types.CustomFieldDate:
+			valor = strings.TrimSpace(valor)
+			if valor != "" {
+				if _, err := time.Parse("2006-01-02", valor); err != nil {
+					return nil, fmt.Errorf("o campo %q deve ser uma data no formato AAAA-MM-DD", nome)
+				}
+			}
+		default:
+			return nil, errors.New("tipo de campo inválido, use texto, oculto, booleano, url ou data")
+		}
+
+		normalized = append(normalized, types.CustomField{Nome: nome, Tipo: tipo, Valor: valor})
+	}
+
+	if len(normalized) == 0 {
+		return nil, nil
+	}
+	return normalized, nil
+}
+
+// unmaskCustomFields devolve aos campos reenviados mascarados e sem valor o
+// valor guardado no campo oculto de mesmo nome. Os que não têm um campo
+// oculto correspondente seguem mascarados e são recusados na validação.
+func unmaskCustomFields(campos []types.CustomField, stored []types.CustomField) []types.CustomField {
+	values := make(map[string]string, len(stored))
+	for _, campo := range stored {
+		if campo.Tipo == types.CustomFieldHidden {
+			values[campo.Nome] = campo.Valor
+		}
+	}
+
+	unmasked := make([]types.CustomField, len(campos))
+	for i, campo := range campos {
+		if campo.Mascarado && campo.Valor == "" {
+			if valor, ok := values[strings.TrimSpace(campo.Nome)]; ok {
+				campo.Valor = valor
+				campo.Mascarado = false
+			}
+		}
+		unmasked[i] = campo
+	}
+	return unmasked
+}
+
+// maskListResponse prepara um item para a listagem: tira o segredo TOTP e os
+// valores dos campos ocultos, que só vêm na consulta do item.
+func maskListResponse(response *types.ItemResponse) {
+	response.TOTP = ""
+	if len(response.Campos) == 0 {
+		return
+	}
+
+	campos := make([]types.CustomField, len(response.Campos))
+	for i, campo := range response.Campos {
+		if campo.Tipo == types.CustomFieldHidden {
+			campo.Valor = ""
+			campo.Mascarado = true
+		}
+		campos[i] = campo
+	}
+	response.Campos = campos
+}
+
+// validateTOTPSeed aceita um segredo em base32, uma URI otpauth:// (TOTP ou
+// HOTP) ou uma URI steam://, e o guarda como foi enviado.
+func validateTOTPSeed(seed string) (string, error) {
+	seed = strings.TrimSpace(seed)
+	if _, err := otp.Parse(seed); err != nil {
+		return "", fmt.Errorf("segredo TOTP inválido: %s", strings.TrimPrefix(err.Error(), "otp: "))
+	}
+	return seed, nil
+}
+
+func validateCard(card *types.CardData) error {
+	card.Titular = strings.TrimSpace(card.Titular)
+
+	numero := strings.NewReplacer(" ", "", "-", "", ".", "").Replace(card.Numero)
+	if len(numero) < 12 || len(numero) > 19 || !onlyDigits(numero) || !luhnValid(numero) {
+		return errors.New("número do cartão inválido")
+	}
+	card.Numero = numero
+
+	if validade := strings.TrimSpace(card.Validade); validade != "" {
+		normalized, err := normalizeCardExpiry(validade)
+		if err != nil {
+			return err
+		}
+		card.Validade = normalized
+	}
+
+	card.CVV = strings.TrimSpace(card.CVV)
+	if card.CVV != "" && (len(card.CVV) < 3 || len(card.CVV) > 4 || !onlyDigits(card.CVV)) {
+		return errors.New("CVV inválido")
+	}
+
+	return nil
+}
+
+// luhnValid confere o dígito verificador do número do cartão (ISO/IEC 7812).
+func luhnValid(numero string) bool {
+	sum := 0
+	double := false
+	for i := len(numero) - 1; i >= 0; i-- {
+		digit := int(numero[i] - '0')
+		if double {
+			digit *= 2
+			if digit > 9 {
+				digit -= 9
+			}
+		}
+		sum += digit
+		double = !double
+	}
+	return sum%10 == 0
+}
+
+// normalizeCardExpiry aceita MM/AA ou MM/AAAA e devolve MM/AAAA.
+func normalizeCardExpiry(validade string) (string, error) {
+	invalid := errors.New("validade do cartão inválida, use MM/AA ou MM/AAAA")
+
+	month, year, ok := strings.Cut(validade, "/")
+	if !ok || len(month) != 2 || !onlyDigits(month) || !onlyDigits(year) {
+		return "", invalid
+	}
+	if month < "01" || month > "12" {
+		return "", invalid
+	}
+
+	switch len(year) {
+	case 2:
+		year = "20" + year
+	case 4:
+	default:
+		return "", invalid
+	}
+	return month + "/" + year, nil
+}
+
+func validateIdentity(identity *types.IdentityData) error {
+	identity.NomeCompleto = strings.TrimSpace(identity.NomeCompleto)
+	identity.RG = strings.TrimSpace(identity.RG)
+	identity.Telefone = strings.TrimSpace(identity.Telefone)
+	identity.Endereco = strings.TrimSpace(identity.Endereco)
+
+	if cpf := strings.TrimSpace(identity.CPF); cpf != "" {
+		normalized, err := normalizeCPF(cpf)
+		if err != nil {
+			return err
+		}
+		identity.CPF = normalized
+	}
+
+	if nascimento := strings.TrimSpace(identity.DataNascimento); nascimento != "" {
+		date, err := time.Parse("2006-01-02", nascimento)
+		if err != nil || date.After(time.Now()) {
+			return errors.New("data de nascimento inválida, use AAAA-MM-DD")
+		}
+		identity.DataNascimento = nascimento
+	}
+
+	if email := strings.TrimSpace(identity.Email); email != "" {
+		address, err := mail.ParseAddress(email)
+		if err != nil || address.Address != email {
+			return errors.New("email inválido")
+		}
+		identity.Email = email
+	}
+
+	if *identity == (types.IdentityData{}) {
+		return errors.New("informe ao menos um dado da identidade")
+	}
+	return nil
+}
+
+// normalizeCPF confere os dígitos verificadores do CPF e devolve o número no
+// formato 000.000.000-00.
+func normalizeCPF(cpf string) (string, error) {
+	digits := strings.NewReplacer(".", "", "-", "", " ", "").Replace(cpf)
+	if len(digits) != 11 || !onlyDigits(digits) || strings.Count(digits, digits[:1]) == 11 {
+		return "", errors.New("CPF inválido")
+	}
+
+	for _, size := range []int{9, 10} {
+		sum := 0
+		for i := 0; i < size; i++ {
+			sum += int(digits[i]-'0') * (size + 1 - i)
+		}
+		check := sum * 10 % 11 % 10
+		if check != int(digits[size]-'0') {
+			return "", errors.New("CPF inválido")
+		}
+	}
+
+	return digits[0:3] + "." + digits[3:6] + "." + digits[6:9] + "-" + digits[9:], nil
+}
+
+func validateWiFi(wifi *types.WiFiData, senha string) error {
+	wifi.SSID = strings.TrimSpace(wifi.SSID)
+	if wifi.SSID == "" {
+		return errors.New("SSID é obrigatório")
+	}
+	if len(wifi.SSID) > 32 {
+		return errors.New("o SSID deve ter no máximo 32 bytes")
+	}
+
+	wifi.Seguranca = strings.ToLower(strings.TrimSpace(wifi.Seguranca))
+	switch wifi.Seguranca {
+	case "":
+		wifi.Seguranca = types.WiFiSecurityWPA2
+	case types.WiFiSecurityWPA, types.WiFiSecurityWPA2, types.WiFiSecurityWPA3, types.WiFiSecurityWEP, types.WiFiSecurityOpen:
+	default:
+		return errors.New("segurança da rede inválida, use wpa, wpa2, wpa3, wep ou aberta")
+	}
+
+	if wifi.Seguranca == types.WiFiSecurityOpen {
+		if senha != "" {
+			return errors.New("redes abertas não têm senha")
+		}
+		return nil
+	}
+
+	if senha == "" {
+		return errors.New("senha da rede é obrigatória")
+	}
+	if wifi.Seguranca != types.WiFiSecurityWEP {
+		if length := utf8.RuneCountInString(senha); length < 8 || length > 63 {
+			return errors.New("a senha de redes WPA deve ter de 8 a 63 caracteres")
+		}
+	}
+	return nil
+}
+
+func onlyDigits(value string) bool {
+	for _, r := range value {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return value != ""
+}
+
+func (s *ItemService) encryptItemData(userID, itemID uint, dados *types.ItemData) ([]byte, error) {
+	if dados.TOTP == "" && len(dados.URLs) == 0 && dados.Cartao == nil && dados.Identidade == nil && dados.WiFi == nil && len(dados.Campos) == 0 {
+		return nil, nil
+	}
+
+	data, err := json.Marshal(dados)
+	if err != nil {
+		return nil, err
+	}
+	return s.CryptoService.EncryptField(userID, itemID, FieldDados, string(data))
+}
+
+func (s *ItemService) decryptItemData(item *types.Item) (types.ItemData, error) {
+	var dados types.ItemData
+	if len(item.DadosCifrados) == 0 {
+		return dados, nil
+	}
+
+	data, err := s.CryptoService.DecryptField(item.UserID, item.ID, FieldDados, item.DadosCifrados)
+	if err != nil {
+		return dados, err
+	}
+	if err := json.Unmarshal([]byte(data), &dados); err != nil {
+		return dados, err
+	}
+	return dados, nil
+}
+
+// itemContent decifra o conteúdo atual de um item.
+func (s *ItemService) itemContent(item *types.Item) (*itemContent, error) {
+	content := &itemContent{
+		Tipo:    itemType(item),
+		Usuario: item.Usuario,
+		URL:     item.URL,
+	}
+
+	var err error
+	if len(item.SenhaCifrada) > 0 {
+		if content.Senha, err = s.CryptoService.DecryptField(item.UserID, item.ID, FieldSenha, item.SenhaCifrada); err != nil {
+			return nil, err
+		}
+	}
+	if len(item.NotasCifradas) > 0 {
+		if content.Notas, err = s.CryptoService.DecryptField(item.UserID, item.ID, FieldNotas, item.NotasCifradas); err != nil {
+			return nil, err
+		}
+	}
+	if content.Dados, err = s.decryptItemData(item); err != nil {
+		return nil, err
+	}
+	return content, nil
+}
@@ -0,0 +1,48 @@
+package services
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Vicente/Password-Mobile-App/backend/app/types"
+)
+
+func TestUnmaskCustomFields(t *testing.T) {
+	stored := []types.CustomField{
+		{Nome: "PIN", Tipo: types.CustomFieldHidden, Valor: "1234"},
+		{Nome: "Conta", Tipo: types.CustomFieldText, Valor: "5678"},
+	}
+
+	// A lista veio da listagem, com o PIN mascarado, e o cliente só mudou a
+	// conta.
+	campos := unmaskCustomFields([]types.CustomField{
+		{Nome: " PIN ", Tipo: types.CustomFieldHidden, Mascarado: true},
+		{Nome: "Conta", Tipo: types.CustomFieldText, Valor: "9999"},
+	}, stored)
+
+	validated, err := validateCustomFields(campos)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []types.CustomField{
+		{Nome: "PIN", Tipo: types.CustomFieldHidden, Valor: "1234"},
+		{Nome: "Conta", Tipo: types.CustomFieldText, Valor: "9999"},
+	}
+	if !reflect.DeepEqual(validated, want) {
+		t.Fatalf("campos %+v, esperado %+v", validated, want)
+	}
+
+	// Um valor novo substitui o guardado mesmo com a marca.
+	campos = unmaskCustomFields([]types.CustomField{{Nome: "PIN", Tipo: types.CustomFieldHidden, Valor: "4321", Mascarado: true}}, stored)
+	if validated, err := validateCustomFields(campos); err != nil || validated[0].Valor != "4321" {
+		t.Fatalf("valor novo: %+v, %v", validated, err)
+	}
+
+	// Sem campo oculto de mesmo nome não há o que manter.
+	for _, nome := range []string{"Senha do banco", "Conta"} {
+		campos = unmaskCustomFields([]types.CustomField{{Nome: nome, Tipo: types.CustomFieldHidden, Mascarado: true}}, stored)
+		if _, err := validateCustomFields(campos); err == nil {
+			t.Errorf("%s: campo mascarado sem valor guardado aceito", nome)
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package services
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/Vicente/Password-Mobile-App/backend/app/kdbx"
+	"github.com/Vicente/Password-Mobile-App/backend/app/otp"
+	"github.com/Vicente/Password-Mobile-App/backend/app/types"
+)
+
+const kdbxDatabaseName = "Password Mobile App"
+
+// ExportKDBX gera um banco KeePass (KDBX 4) com os itens ativos do usuário,
+// protegido pela senha escolhida na requisição.
+func (s *ItemService) ExportKDBX(req *types.KDBXExportRequest) ([]byte, error) {
+	if req.UserID == 0 {
+		return nil, errors.New("usuário é obrigatório")
+	}
+
+	zeroKnowledge, err := s.VaultService.IsZeroKnowledge(req.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if zeroKnowledge {
+		return nil, errors.New("a exportação KDBX não está disponível no modo zero-knowledge")
+	}
+
+	senha, err := validateNewPassword(req.Senha, req.ConfirmacaoSenha)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := kdbx.DefaultOptions()
+	if cifra := strings.ToLower(strings.TrimSpace(req.Cifra)); cifra != "" {
+		opts.Cifra = kdbx.Cipher(cifra)
+	}
+	if kdf := strings.ToLower(strings.TrimSpace(req.KDF)); kdf != "" {
+		opts.KDF = kdbx.KDF(kdf)
+		if opts.KDF == kdbx.AESKDF {
+			opts.Iteracoes = kdbx.DefaultAESRounds
+		}
+	}
+
+	items, err := s.ItemDAL.GetItemsByUserID(req.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	folders, err := s.FolderDAL.GetFoldersByUserID(req.UserID)
+	if err != nil {
+		return nil, err
+	}
+	paths := folderPaths(folders)
+
+	db := &kdbx.Database{Nome: kdbxDatabaseName, Entradas: make([]kdbx.Entry, 0, len(items))}
+	for i := range items {
+		item, err := s.toResponse(&items[i])
+		if err != nil {
+			return nil, err
+		}
+		var grupo string
+		if item.PastaID != nil {
+			grupo = paths[*item.PastaID]
+		}
+		db.Entradas = append(db.Entradas, kdbx.Entry{
+			Grupo:        grupo,
+			Titulo:       item.Nome,
+			Usuario:      item.Usuario,
+			Senha:        item.Senha,
+			URL:          item.URL,
+			Notas:        item.Notas,
+			Tags:         item.Tags,
+			Campos:       kdbxFields(item),
+			CriadoEm:     items[i].CreatedAt,
+			AtualizadoEm: items[i].UpdatedAt,
+		})
+	}
+
+	var buf bytes.Buffer
+	if err := kdbx.Write(&buf, db, senha, opts); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// kdbxFields leva os campos próprios de cada tipo de item e os campos
+// personalizados para campos extras da entrada, já que o KeePass só conhece
+// usuário, senha, URL e notas. O TOTP usa os nomes reconhecidos pelo KeePassXC
+// (URI) e pelo KeePass (base32). Como os nomes não podem se repetir na
+// entrada, um campo personalizado com o nome de outro campo ganha um número.
+func kdbxFields(item *types.ItemResponse) []kdbx.Field {
+	var fields []kdbx.Field
+	used := map[string]bool{"Title": true, "UserName": true, "Password": true, "URL": true, "Notes": true}
+	add := func(nome, valor string, protegido bool) {
+		if valor == "" {
+			return
+		}
+		base := nome
+		for i := 2; used[nome]; i++ {
+			nome = fmt.Sprintf("%s (%d)", base, i)
+		}
+		used[nome] = true
+		fields = append(fields, kdbx.Field{Nome: nome, Valor: valor, Protegido: protegido})
+	}
+
+	for i, u := range item.URLs {
+		add(fmt.Sprintf("URL %d", i+2), u, false)
+	}
+	switch lower := strings.ToLower(item.TOTP); {
+	case strings.HasPrefix(lower, "otpauth://"):
+		add("otp", item.TOTP, true)
+	case strings.HasPrefix(lower, "steam://"):
+		if key, err := otp.Parse(item.TOTP); err == nil {
+			add("otp", key.URI(), true)
+		}
+	default:
+		add("TimeOtp-Secret-Base32", item.TOTP, true)
+	}
+
+	if card := item.Cartao; card != nil {
+		add("Titular", card.Titular, false)
+		add("Número do cartão", card.Numero, true)
+		add("Validade", card.Validade, false)
+		add("CVV", card.CVV, true)
+	}
+
+	if identity := item.Identidade; identity != nil {
+		add("Nome completo", identity.NomeCompleto, false)
+		add("CPF", identity.CPF, true)
+		add("RG", identity.RG, true)
+		add("Data de nascimento", identity.DataNascimento, false)
+		add("Email", identity.Email, false)
+		add("Telefone", identity.Telefone, false)
+		add("Endereço", identity.Endereco, false)
+	}
+
+	if wifi := item.WiFi; wifi != nil {
+		add("SSID", wifi.SSID, false)
+		add("Segurança", wifi.Seguranca, false)
+		if wifi.Oculta {
+			add("Rede oculta", "sim", false)
+		}
+	}
+
+	for _, campo := range item.Campos {
+		add(campo.Nome, campo.Valor, campo.Tipo == types.CustomFieldHidden)
+	}
+
+	return fields
+}
@@ -0,0 +1,402 @@
+package services
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Vicente/Password-Mobile-App/backend/app/dal"
+	"github.com/Vicente/Password-Mobile-App/backend/app/otp"
+	"github.com/Vicente/Password-Mobile-App/backend/app/ratelimit"
+	"github.com/Vicente/Password-Mobile-App/backend/app/types"
+	"github.com/golang-jwt/jwt/v4"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+const (
+	mfaIssuer          = "Password Mobile App"
+	mfaSecretSize      = 20
+	mfaSkew            = 1
+	mfaTokenType       = "mfa"
+	mfaTokenTTL        = 5 * time.Minute
+	recoveryCodeCount  = 10
+	recoveryCodeLength = 10
+	recoveryAlphabet   = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+)
+
+var mfaFailurePolicy = ratelimit.FailurePolicy{
+	Window:       15 * time.Minute,
+	FreeAttempts: 3,
+	BaseDelay:    2 * time.Second,
+	MaxDelay:     time.Minute,
+	LockAfter:    8,
+	LockDuration: 30 * time.Minute,
+}
+
+type MFAService struct {
+	MFADAL         *dal.MFADAL
+	AuthDAL        *dal.AuthDAL
+	CryptoService  *CryptoService
+	SessionService *SessionService
+	Limiter        *ratelimit.Limiter
+	Now            func() time.Time
+}
+
+func NewMFAService(mfaDAL *dal.MFADAL, authDAL *dal.AuthDAL, cryptoService *CryptoService, sessionService *SessionService, limiter *ratelimit.Limiter) *MFAService {
+	return &MFAService{
+		MFADAL:         mfaDAL,
+		AuthDAL:        authDAL,
+		CryptoService:  cryptoService,
+		SessionService: sessionService,
+		Limiter:        limiter,
+		Now:            time.Now,
+	}
+}
+
+func (s *MFAService) IsEnabled(userID uint) (bool, error) {
+	config, err := s.MFADAL.GetConfigByUserID(userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	return config.Ativo, nil
+}
+
+func (s *MFAService) Status(userID uint) (*types.MFAStatusResponse, error) {
+	enabled, err := s.IsEnabled(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &types.MFAStatusResponse{Ativo: enabled}
+	if enabled {
+		if response.CodigosRestantes, err = s.MFADAL.CountUnusedRecoveryCodes(userID); err != nil {
+			return nil, err
+		}
+	}
+
+	return response, nil
+}
+
+func (s *MFAService) Setup(userID uint) (*types.MFASetupResponse, error) {
+	user, err := s.AuthDAL.GetUserByID(userID)
+	if err != nil {
+		return nil, errors.New("usuário não encontrado")
+	}
+
+	config, err := s.MFADAL.GetConfigByUserID(userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		config = &types.MFAConfig{UserID: userID}
+	} else if err != nil {
+		return nil, err
+	}
+
+	if config.Ativo {
+		return nil, errors.New("a autenticação em dois fatores já está ativa")
+	}
+
+	secret, err := otp.GenerateSecret(mfaSecretSize)
+	if err != nil {
+		return nil, errors.New("erro ao gerar segredo")
+	}
+
+	config.SegredoCifrado, err = s.CryptoService.Encrypt(userID, secret)
+	if err != nil {
+		return nil, err
+	}
+	config.UltimoPasso = 0
+
+	if err := s.MFADAL.SaveConfig(config); err != nil {
+		return nil, err
+	}
+
+	key := s.key(secret, user.Email)
+	return &types.MFASetupResponse{
+		Segredo: otp.EncodeSecret(secret),
+		URI:     key.URI(),
+	}, nil
+}
+
+func (s *MFAService) Confirm(req *types.MFACodeRequest) (*types.RecoveryCodesResponse, error) {
+	config, err := s.MFADAL.GetConfigByUserID(req.UserID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errors.New("configure a autenticação em dois fatores antes de confirmar")
+	} else if err != nil {
+		return nil, err
+	}
+
+	if config.Ativo {
+		return nil, errors.New("a autenticação em dois fatores já está ativa")
+	}
+
+	step, err := s.checkCode(config, req.Codigo)
+	if err != nil {
+		return nil, err
+	}
+
+	codes, hashed, err := s.newRecoveryCodes(req.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	config.Ativo = true
+	config.UltimoPasso = step
+
+	if err := s.MFADAL.ActivateConfig(config, hashed); err != nil {
+		return nil, err
+	}
+
+	return &types.RecoveryCodesResponse{CodigosRecuperacao: codes}, nil
+}
+
+func (s *MFAService) Verify(req *types.MFAVerifyRequest) (*types.AuthResponse, error) {
+	userID, err := s.parseMFAToken(req.MFAToken)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.verifySecondFactor(userID, req.Codigo, req.CodigoRecuperacao); err != nil {
+		return nil, err
+	}
+
+	user, err := s.AuthDAL.GetUserByID(userID)
+	if err != nil {
+		return nil, errors.New("usuário não encontrado")
+	}
+
+	info := req.Sessao
+	info.Dispositivo = req.Dispositivo
+
+	response, err := s.SessionService.CreateSession(user, info)
+	if err != nil {
+		return nil, err
+	}
+
+	user.Senha = ""
+	response.User = *user
+
+	return response, nil
+}
+
+func (s *MFAService) Disable(req *types.MFADisableRequest) error {
+	user, err := s.AuthDAL.GetUserByID(req.UserID)
+	if err != nil {
+		return errors.New("usuário não encontrado")
+	}
+
+	// A senha passa pelo mesmo limite do segundo fator; sem ele a rota
+	// serviria para testar senhas sem atraso.
+	err = s.limitSecondFactor(req.UserID, func() error {
+		if err := bcrypt.CompareHashAndPassword([]byte(user.SenhaHash), []byte(req.Senha)); err != nil {
+			return errors.New("senha incorreta")
+		}
+		return s.checkSecondFactor(req.UserID, req.Codigo, req.CodigoRecuperacao)
+	})
+	if err != nil {
+		return err
+	}
+
+	return s.MFADAL.DeleteConfig(req.UserID)
+}
+
+func (s *MFAService) RegenerateRecoveryCodes(req *types.MFACodeRequest) (*types.RecoveryCodesResponse, error) {
+	if err := s.verifySecondFactor(req.UserID, req.Codigo, ""); err != nil {
+		return nil, err
+	}
+
+	codes, hashed, err := s.newRecoveryCodes(req.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.MFADAL.ReplaceRecoveryCodes(req.UserID, hashed); err != nil {
+		return nil, err
+	}
+
+	return &types.RecoveryCodesResponse{CodigosRecuperacao: codes}, nil
+}
+
+func (s *MFAService) GenerateMFAToken(user *types.User) (string, error) {
+	claims := jwt.MapClaims{
+		"id":  user.ID,
+		"typ": mfaTokenType,
+		"exp": s.Now().Add(mfaTokenTTL).Unix(),
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+
+	return token.SignedString(s.SessionService.JWTSecret)
+}
+
+func (s *MFAService) parseMFAToken(tokenString string) (uint, error) {
+	parser := jwt.NewParser(jwt.WithoutClaimsValidation())
+	token, err := parser.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, errors.New("método de assinatura inválido")
+		}
+		return s.SessionService.JWTSecret, nil
+	})
+	if err != nil || !token.Valid {
+		return 0, errors.New("token de verificação inválido")
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return 0, errors.New("token de verificação inválido")
+	}
+
+	if typ, _ := claims["typ"].(string); typ != mfaTokenType {
+		return 0, errors.New("token de verificação inválido")
+	}
+
+	if !claims.VerifyExpiresAt(s.Now().Unix(), true) {
+		return 0, errors.New("token de verificação expirado, faça login novamente")
+	}
+
+	userID, ok := claims["id"].(float64)
+	if !ok {
+		return 0, errors.New("token de verificação inválido")
+	}
+
+	return uint(userID), nil
+}
+
+func (s *MFAService) verifySecondFactor(userID uint, codigo string, codigoRecuperacao string) error {
+	return s.limitSecondFactor(userID, func() error {
+		return s.checkSecondFactor(userID, codigo, codigoRecuperacao)
+	})
+}
+
+// limitSecondFactor executa check sob mfaFailurePolicy: cada erro conta como
+// uma tentativa malsucedida do usuário.
+func (s *MFAService) limitSecondFactor(userID uint, check func() error) error {
+	limitKey := fmt.Sprintf("mfa:%d", userID)
+	if err := s.Limiter.ReserveFailure(limitKey, mfaFailurePolicy); err != nil {
+		return err
+	}
+
+	if err := check(); err != nil {
+		if recordErr := s.Limiter.ConfirmFailure(limitKey, mfaFailurePolicy); recordErr != nil {
+			return recordErr
+		}
+		return err
+	}
+
+	return s.Limiter.ResetFailures(limitKey)
+}
+
+func (s *MFAService) checkSecondFactor(userID uint, codigo string, codigoRecuperacao string) error {
+	config, err := s.MFADAL.GetConfigByUserID(userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && !config.Ativo) {
+		return errors.New("a autenticação em dois fatores não está ativa")
+	} else if err != nil {
+		return err
+	}
+
+	if strings.TrimSpace(codigoRecuperacao) != "" {
+		used, err := s.MFADAL.UseRecoveryCode(userID, hashRecoveryCode(userID, codigoRecuperacao))
+		if err != nil {
+			return err
+		}
+		if !used {
+			return errors.New("código de recuperação inválido")
+		}
+		return nil
+	}
+
+	step, err := s.checkCode(config, codigo)
+	if err != nil {
+		return err
+	}
+
+	accepted, err := s.MFADAL.UpdateLastStep(userID, step)
+	if err != nil {
+		return err
+	}
+	if !accepted {
+		return errors.New("código já utilizado, aguarde o próximo")
+	}
+
+	return nil
+}
+
+func (s *MFAService) checkCode(config *types.MFAConfig, codigo string) (int64, error) {
+	if strings.TrimSpace(codigo) == "" {
+		return 0, errors.New("código de verificação é obrigatório")
+	}
+
+	secret, err := s.CryptoService.Decrypt(config.UserID, config.SegredoCifrado)
+	if err != nil {
+		return 0, err
+	}
+
+	step, ok := otp.Validate(s.key(secret, ""), codigo, s.Now(), mfaSkew)
+	if !ok {
+		return 0, errors.New("código de verificação inválido")
+	}
+
+	if step <= config.UltimoPasso {
+		return 0, errors.New("código já utilizado, aguarde o próximo")
+	}
+
+	return step, nil
+}
+
+func (s *MFAService) key(secret []byte, account string) otp.Key {
+	return otp.Key{
+		Secret:    secret,
+		Algorithm: otp.SHA1,
+		Digits:    otp.DefaultDigits,
+		Period:    otp.DefaultPeriod,
+		Issuer:    mfaIssuer,
+		Account:   account,
+	}
+}
+
+func (s *MFAService) newRecoveryCodes(userID uint) ([]string, []types.RecoveryCode, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	hashed := make([]types.RecoveryCode, 0, recoveryCodeCount)
+
+	for i := 0; i < recoveryCodeCount; i++ {
+		code, err := randomString(recoveryAlphabet, recoveryCodeLength)
+		if err != nil {
+			return nil, nil, errors.New("erro ao gerar códigos de recuperação")
+		}
+
+		formatted := code[:recoveryCodeLength/2] + "-" + code[recoveryCodeLength/2:]
+		codes = append(codes, formatted)
+		hashed = append(hashed, types.RecoveryCode{
+			UserID:     userID,
+			CodigoHash: hashRecoveryCode(userID, formatted),
+		})
+	}
+
+	return codes, hashed, nil
+}
+
+func hashRecoveryCode(userID uint, code string) string {
+	normalized := strings.ToUpper(strings.NewReplacer("-", "", " ", "").Replace(strings.TrimSpace(code)))
+	return hashToken(fmt.Sprintf("%d:%s", userID, normalized))
+}
+
+func randomString(alphabet string, length int) (string, error) {
+	limit := 256 - 256%len(alphabet)
+	out := make([]byte, 0, length)
+	buf := make([]byte, length)
+
+	for len(out) < length {
+		if _, err := rand.Read(buf); err != nil {
+			return "", err
+		}
+		for _, b := range buf {
+			if int(b) < limit && len(out) < length {
+				out = append(out, alphabet[int(b)%len(alphabet)])
+			}
+		}
+	}
+	return string(out), nil
+}
@@ -0,0 +1,166 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Vicente/Password-Mobile-App/backend/app/otp"
+	"github.com/Vicente/Password-Mobile-App/backend/app/ratelimit"
+	"github.com/Vicente/Password-Mobile-App/backend/app/types"
+)
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+// Início exato de um passo de 30 segundos.
+var mfaTestStart = time.Unix(1700000010, 0)
+
+func newTestMFAService(t *testing.T, clock *fakeClock) *MFAService {
+	t.Helper()
+
+	masterKey, err := GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	dataKey, err := GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A chave de dados já em cache dispensa o banco.
+	cryptoService := NewCryptoService(nil, masterKey)
+	cryptoService.dataKeys[1] = dataKey
+
+	service := NewMFAService(nil, nil, cryptoService, NewSessionService(nil, nil, "segredo-de-teste"), nil)
+	service.Now = clock.Now
+	return service
+}
+
+func TestMFATokenExpiry(t *testing.T) {
+	clock := &fakeClock{now: mfaTestStart}
+	service := newTestMFAService(t, clock)
+
+	user := &types.User{}
+	user.ID = 7
+
+	token, err := service.GenerateMFAToken(user)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	clock.Advance(mfaTokenTTL - time.Second)
+	userID, err := service.parseMFAToken(token)
+	if err != nil {
+		t.Fatalf("token recusado antes de expirar: %v", err)
+	}
+	if userID != 7 {
+		t.Fatalf("userID = %d, esperado 7", userID)
+	}
+
+	clock.Advance(time.Second)
+	if _, err := service.parseMFAToken(token); err == nil || err.Error() != "token de verificação expirado, faça login novamente" {
+		t.Fatalf("token aceito no instante da expiração: %v", err)
+	}
+
+	clock.Advance(time.Hour)
+	if _, err := service.parseMFAToken(token); err == nil {
+		t.Fatal("token aceito depois de expirar")
+	}
+}
+
+func TestMFACheckCodeSkew(t *testing.T) {
+	clock := &fakeClock{now: mfaTestStart}
+	service := newTestMFAService(t, clock)
+
+	secret := []byte("12345678901234567890")
+	encrypted, err := service.CryptoService.Encrypt(1, secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := &types.MFAConfig{UserID: 1, SegredoCifrado: encrypted}
+
+	step := otp.TimeStep(mfaTestStart, otp.DefaultPeriod)
+	codigo, err := otp.HOTP(secret, uint64(step), otp.DefaultDigits, otp.SHA1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		advance time.Duration
+		valid   bool
+	}{
+		{"passo atual", 0, true},
+		{"fim do passo", 29 * time.Second, true},
+		{"passo seguinte", time.Second, true},
+		{"fim do passo seguinte", 29 * time.Second, true},
+		{"dois passos depois", time.Second, false},
+	}
+
+	for _, tt := range tests {
+		clock.Advance(tt.advance)
+		got, err := service.checkCode(config, codigo)
+		if tt.valid {
+			if err != nil {
+				t.Errorf("%s: código recusado: %v", tt.name, err)
+			} else if got != step {
+				t.Errorf("%s: passo %d, esperado %d", tt.name, got, step)
+			}
+		} else if err == nil {
+			t.Errorf("%s: código aceito fora da janela", tt.name)
+		}
+	}
+
+	// Um relógio atrasado em um passo ainda aceita o código seguinte.
+	clock.now = mfaTestStart.Add(-time.Second)
+	if _, err := service.checkCode(config, codigo); err != nil {
+		t.Errorf("código recusado um passo antes: %v", err)
+	}
+	clock.now = mfaTestStart.Add(-31 * time.Second)
+	if _, err := service.checkCode(config, codigo); err == nil {
+		t.Error("código aceito dois passos antes")
+	}
+
+	clock.now = mfaTestStart
+	config.UltimoPasso = step
+	if _, err := service.checkCode(config, codigo); err == nil || err.Error() != "código já utilizado, aguarde o próximo" {
+		t.Errorf("código reaproveitado: %v", err)
+	}
+}
+
+func TestMFAFailureLockExpires(t *testing.T) {
+	clock := &fakeClock{now: mfaTestStart}
+	limiter := ratelimit.NewLimiter(ratelimit.NewMemoryStore())
+	limiter.Now = clock.Now
+
+	const key = "mfa:1"
+	for i := 0; i < mfaFailurePolicy.LockAfter; i++ {
+		// Espera o atraso progressivo passar antes de cada nova tentativa.
+		clock.Advance(mfaFailurePolicy.MaxDelay)
+		if err := limiter.ReserveFailure(key, mfaFailurePolicy); err != nil {
+			t.Fatalf("tentativa %d bloqueada antes do limite: %v", i+1, err)
+		}
+		if err := limiter.ConfirmFailure(key, mfaFailurePolicy); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	clock.Advance(mfaFailurePolicy.LockDuration - time.Second)
+	if err := limiter.ReserveFailure(key, mfaFailurePolicy); err == nil {
+		t.Fatal("tentativa aceita durante o bloqueio")
+	}
+
+	clock.Advance(time.Second)
+	if err := limiter.ReserveFailure(key, mfaFailurePolicy); err != nil {
+		t.Fatalf("tentativa recusada depois do bloqueio: %v", err)
+	}
+}
@@ -0,0 +1,266 @@
+package services
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/Vicente/Password-Mobile-App/backend/app/dal"
+	"github.com/Vicente/Password-Mobile-App/backend/app/mailer"
+	"github.com/Vicente/Password-Mobile-App/backend/app/ratelimit"
+	"github.com/Vicente/Password-Mobile-App/backend/app/strength"
+	"github.com/Vicente/Password-Mobile-App/backend/app/types"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultResetTokenTTL    = time.Hour
+	minPasswordLength       = 6
+	defaultMinPasswordScore = 2
+)
+
+var (
+	forgotPasswordRateLimit = ratelimit.Rule{Limit: 3, Window: time.Hour}
+	// Cada tentativa conta, para que a senha atual não possa ser descoberta
+	// por força bruta com um token de acesso roubado. O limite é um só para
+	// todas as rotas que conferem a senha da conta.
+	accountPasswordRateLimit = ratelimit.Rule{Limit: 5, Window: 15 * time.Minute}
+)
+
+// allowPasswordCheck conta uma verificação da senha da conta do usuário.
+func allowPasswordCheck(limiter *ratelimit.Limiter, userID uint) error {
+	return limiter.Allow(fmt.Sprintf("password:%d", userID), accountPasswordRateLimit)
+}
+
+type PasswordService struct {
+	AuthDAL       *dal.AuthDAL
+	PasswordDAL   *dal.PasswordDAL
+	Mailer        mailer.Mailer
+	AuditService  *AuditService
+	Limiter       *ratelimit.Limiter
+	BreachService *BreachService
+	ResetTokenTTL time.Duration
+	ResetURL      string
+	// MinPasswordScore é a força mínima (0 a 4) exigida da senha da conta.
+	MinPasswordScore int
+}
+
+func NewPasswordService(authDAL *dal.AuthDAL, passwordDAL *dal.PasswordDAL, mail mailer.Mailer, auditService *AuditService, limiter *ratelimit.Limiter, breachService *BreachService) *PasswordService {
+	return &PasswordService{
+		AuthDAL:          authDAL,
+		PasswordDAL:      passwordDAL,
+		Mailer:           mail,
+		AuditService:     auditService,
+		Limiter:          limiter,
+		BreachService:    breachService,
+		ResetTokenTTL:    defaultResetTokenTTL,
+		MinPasswordScore: defaultMinPasswordScore,
+	}
+}
+
+func (s *PasswordService) ChangePassword(req *types.ChangePasswordRequest) error {
+	user, err := s.AuthDAL.GetUserByID(req.UserID)
+	if err != nil {
+		return errors.New("usuário não encontrado")
+	}
+
+	if err := allowPasswordCheck(s.Limiter, user.ID); err != nil {
+		return err
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.SenhaHash), []byte(req.SenhaAtual)); err != nil {
+		return errors.New("senha atual incorreta")
+	}
+
+	senha, err := validateAccountPassword(req.NovaSenha, req.ConfirmacaoSenha, s.MinPasswordScore, s.BreachService, user.Nome, user.Email)
+	if err != nil {
+		return err
+	}
+
+	if bcrypt.CompareHashAndPassword([]byte(user.SenhaHash), []byte(senha)) == nil {
+		return errors.New("a nova senha deve ser diferente da atual")
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(senha), bcrypt.DefaultCost)
+	if err != nil {
+		return errors.New("erro ao processar senha")
+	}
+
+	if err := s.PasswordDAL.UpdatePassword(user.ID, string(hashedPassword), req.SessionID); err != nil {
+		return err
+	}
+
+	s.AuditService.Record(user.ID, types.AuditPasswordChanged, req.Sessao, "")
+	return nil
+}
+
+// ForgotPassword não informa se o email existe: erros de envio e emails
+// desconhecidos são apenas registrados no log.
+func (s *PasswordService) ForgotPassword(req *types.ForgotPasswordRequest) error {
+	email := strings.TrimSpace(strings.ToLower(req.Email))
+	if email == "" {
+		return errors.New("email é obrigatório")
+	}
+
+	var limitErr *ratelimit.Error
+	if err := s.Limiter.Allow("forgot:"+email, forgotPasswordRateLimit); errors.As(err, &limitErr) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	user, err := s.AuthDAL.GetUserByEmail(email)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return errors.New("erro ao gerar link de redefinição")
+	}
+	token := base64.RawURLEncoding.EncodeToString(raw)
+
+	reset := &types.PasswordReset{
+		UserID:    user.ID,
+		TokenHash: hashToken(token),
+		ExpiraEm:  time.Now().Add(s.ResetTokenTTL),
+	}
+	if err := s.PasswordDAL.CreateReset(reset); err != nil {
+		return err
+	}
+
+	if err := s.Mailer.Send(s.resetMessage(user, token)); err != nil {
+		log.Printf("Falha ao enviar email de redefinição de senha: %v", err)
+	}
+
+	return nil
+}
+
+func (s *PasswordService) ResetPassword(req *types.ResetPasswordRequest) error {
+	token := strings.TrimSpace(req.Token)
+	if token == "" {
+		return errors.New("token é obrigatório")
+	}
+
+	reset, err := s.PasswordDAL.GetResetByHash(hashToken(token))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return errors.New("link de redefinição inválido")
+	} else if err != nil {
+		return err
+	}
+
+	if reset.UsadoEm != nil {
+		return errors.New("link de redefinição já utilizado")
+	}
+
+	if time.Now().After(reset.ExpiraEm) {
+		return errors.New("link de redefinição expirado")
+	}
+
+	var userInputs []string
+	if user, err := s.AuthDAL.GetUserByID(reset.UserID); err == nil {
+		userInputs = []string{user.Nome, user.Email}
+	}
+
+	senha, err := validateAccountPassword(req.NovaSenha, req.ConfirmacaoSenha, s.MinPasswordScore, s.BreachService, userInputs...)
+	if err != nil {
+		return err
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(senha), bcrypt.DefaultCost)
+	if err != nil {
+		return errors.New("erro ao processar senha")
+	}
+
+	if err := s.PasswordDAL.ResetPassword(reset, string(hashedPassword)); err != nil {
+		return err
+	}
+
+	s.AuditService.Record(reset.UserID, types.AuditPasswordReset, req.Sessao, "")
+
+	if user, err := s.AuthDAL.GetUserByID(reset.UserID); err == nil {
+		return s.Limiter.ResetFailures("login:" + user.Email)
+	}
+	return nil
+}
+
+func (s *PasswordService) resetMessage(user *types.User, token string) mailer.Message {
+	link := tokenLink(s.ResetURL, token)
+
+	body := fmt.Sprintf(`Olá, %s!
+
+Recebemos um pedido para redefinir a senha da sua conta.
+Use o link abaixo em até %d minutos:
+
+%s
+
+Se você não fez esse pedido, ignore este email: sua senha continua a mesma.
+`, user.Nome, int(s.ResetTokenTTL.Minutes()), link)
+
+	return mailer.Message{
+		To:      user.Email,
+		Subject: "Redefinição de senha",
+		Body:    body,
+	}
+}
+
+func tokenLink(base string, token string) string {
+	if base == "" {
+		return token
+	}
+
+	separator := "?"
+	if strings.Contains(base, "?") {
+		separator = "&"
+	}
+	return base + separator + "token=" + url.QueryEscape(token)
+}
+
+func validateNewPassword(senha string, confirmacao string) (string, error) {
+	senha = strings.TrimSpace(senha)
+	if len(senha) < minPasswordLength {
+		return "", errors.New("a senha deve ter pelo menos 6 caracteres")
+	}
+
+	if senha != strings.TrimSpace(confirmacao) {
+		return "", errors.New("as senhas não coincidem")
+	}
+
+	return senha, nil
+}
+
+// validateAccountPassword aplica à senha da conta, além das regras básicas, a
+// força mínima configurada e a consulta às senhas vazadas. Nome e email do
+// usuário contam como palavras previsíveis.
+func validateAccountPassword(senha string, confirmacao string, minScore int, breachService *BreachService, userInputs ...string) (string, error) {
+	senha, err := validateNewPassword(senha, confirmacao)
+	if err != nil {
+		return "", err
+	}
+
+	if breachService.IsBreached(senha) {
+		return "", errors.New("esta senha aparece em vazamentos de dados conhecidos, escolha outra")
+	}
+
+	result := strength.Estimate(senha, userInputs...)
+	if result.Pontuacao >= minScore {
+		return senha, nil
+	}
+
+	message := "a senha é muito fraca"
+	if result.Aviso != "" {
+		message += ": " + strings.TrimSuffix(result.Aviso, ".")
+	}
+	if len(result.Sugestoes) > 0 {
+		message += ". " + strings.Join(result.Sugestoes, " ")
+	}
+	return "", errors.New(message)
+}
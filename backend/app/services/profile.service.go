@@ -0,0 +1,127 @@
+package services
+
+import (
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/Vicente/Password-Mobile-App/backend/app/dal"
+	"github.com/Vicente/Password-Mobile-App/backend/app/ratelimit"
+	"github.com/Vicente/Password-Mobile-App/backend/app/types"
+	"golang.org/x/crypto/bcrypt"
+)
+
+type ProfileService struct {
+	AuthDAL             *dal.AuthDAL
+	CryptoService       *CryptoService
+	VerificationService *VerificationService
+	Limiter             *ratelimit.Limiter
+}
+
+func NewProfileService(authDAL *dal.AuthDAL, cryptoService *CryptoService, verificationService *VerificationService, limiter *ratelimit.Limiter) *ProfileService {
+	return &ProfileService{
+		AuthDAL:             authDAL,
+		CryptoService:       cryptoService,
+		VerificationService: verificationService,
+		Limiter:             limiter,
+	}
+}
+
+func (s *ProfileService) GetProfile(userID uint) (*types.ProfileResponse, error) {
+	user, err := s.AuthDAL.GetUserByID(userID)
+	if err != nil {
+		return nil, errors.New("usuário não encontrado")
+	}
+
+	profile := user.Profile()
+	return &profile, nil
+}
+
+func (s *ProfileService) UpdateProfile(req *types.UpdateProfileRequest) (*types.ProfileResponse, error) {
+	user, err := s.AuthDAL.GetUserByID(req.UserID)
+	if err != nil {
+		return nil, errors.New("usuário não encontrado")
+	}
+
+	if req.Nome != nil {
+		nome := strings.TrimSpace(*req.Nome)
+		if nome == "" {
+			return nil, errors.New("nome é obrigatório")
+		}
+		user.Nome = nome
+	}
+
+	if req.DataNascimento != nil {
+		if req.DataNascimento.Time.After(time.Now()) {
+			return nil, errors.New("data de nascimento deve ser anterior à data atual")
+		}
+		user.DataNascimento = *req.DataNascimento
+	}
+
+	sendVerification := false
+	if req.Email != nil {
+		email, err := normalizeEmail(*req.Email)
+		if err != nil {
+			return nil, err
+		}
+
+		if email == user.Email {
+			user.EmailPendente = ""
+		} else if email != user.EmailPendente {
+			if err := allowPasswordCheck(s.Limiter, user.ID); err != nil {
+				return nil, err
+			}
+			if err := bcrypt.CompareHashAndPassword([]byte(user.SenhaHash), []byte(req.Senha)); err != nil {
+				return nil, errors.New("senha incorreta")
+			}
+
+			inUse, err := s.AuthDAL.EmailInUse(email, user.ID)
+			if err != nil {
+				return nil, err
+			}
+			if inUse {
+				return nil, errors.New("email já cadastrado")
+			}
+
+			user.EmailPendente = email
+			sendVerification = true
+		}
+	}
+
+	if err := s.AuthDAL.UpdateProfile(user); err != nil {
+		return nil, err
+	}
+
+	// O novo email só passa a valer depois de confirmado pelo link enviado a ele.
+	if sendVerification {
+		if err := s.VerificationService.SendVerification(user, user.EmailPendente); err != nil {
+			log.Printf("Falha ao enviar email de verificação: %v", err)
+		}
+	}
+
+	profile := user.Profile()
+	return &profile, nil
+}
+
+func (s *ProfileService) DeleteAccount(req *types.DeleteAccountRequest) error {
+	user, err := s.AuthDAL.GetUserByID(req.UserID)
+	if err != nil {
+		return errors.New("usuário não encontrado")
+	}
+
+	if err := allowPasswordCheck(s.Limiter, user.ID); err != nil {
+		return err
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.SenhaHash), []byte(req.Senha)); err != nil {
+		return errors.New("senha incorreta")
+	}
+
+	if err := s.AuthDAL.DeleteUser(user.ID); err != nil {
+		return err
+	}
+
+	s.CryptoService.ForgetKey(user.ID)
+	return nil
+}
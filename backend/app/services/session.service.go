@@ -0,0 +1,299 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/Vicente/Password-Mobile-App/backend/app/dal"
+	"github.com/Vicente/Password-Mobile-App/backend/app/types"
+	"github.com/golang-jwt/jwt/v4"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultAccessTokenTTL  = 15 * time.Minute
+	defaultRefreshTokenTTL = 30 * 24 * time.Hour
+	accessTokenType        = "access"
+	lastSeenInterval       = time.Minute
+	maxDeviceNameLength    = 100
+	maxUserAgentLength     = 255
+)
+
+type SessionService struct {
+	SessionDAL      *dal.SessionDAL
+	AuthDAL         *dal.AuthDAL
+	JWTSecret       []byte
+	AccessTokenTTL  time.Duration
+	RefreshTokenTTL time.Duration
+}
+
+func NewSessionService(sessionDAL *dal.SessionDAL, authDAL *dal.AuthDAL, jwtSecret string) *SessionService {
+	return &SessionService{
+		SessionDAL:      sessionDAL,
+		AuthDAL:         authDAL,
+		JWTSecret:       []byte(jwtSecret),
+		AccessTokenTTL:  defaultAccessTokenTTL,
+		RefreshTokenTTL: defaultRefreshTokenTTL,
+	}
+}
+
+func (s *SessionService) CreateSession(user *types.User, info types.SessionInfo) (*types.AuthResponse, error) {
+	refreshToken, token, err := s.newRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+
+	session := &types.Session{
+		UserID:       user.ID,
+		Dispositivo:  truncate(strings.TrimSpace(info.Dispositivo), maxDeviceNameLength),
+		UserAgent:    truncate(info.UserAgent, maxUserAgentLength),
+		IP:           info.IP,
+		UltimoAcesso: time.Now(),
+		ExpiraEm:     token.ExpiraEm,
+	}
+	if err := s.SessionDAL.CreateSession(session, token); err != nil {
+		return nil, errors.New("erro ao criar sessão")
+	}
+
+	accessToken, err := s.generateAccessToken(user.ID, user.Email, session.ID)
+	if err != nil {
+		return nil, errors.New("erro ao gerar token de autenticação")
+	}
+
+	return &types.AuthResponse{
+		Token:        accessToken,
+		RefreshToken: refreshToken,
+	}, nil
+}
+
+func (s *SessionService) Refresh(refreshToken string) (*types.AuthResponse, error) {
+	current, err := s.activeRefreshToken(refreshToken)
+	if err != nil {
+		return nil, err
+	}
+
+	if current.UsadoEm != nil {
+		// Reuso de um refresh token já rotacionado indica vazamento: encerra toda a sessão.
+		if err := s.SessionDAL.RevokeSession(current.SessionID); err != nil {
+			return nil, err
+		}
+		return nil, errors.New("refresh token reutilizado, sessão encerrada")
+	}
+
+	nextToken, next, err := s.newRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.SessionDAL.RotateRefreshToken(current, next); err != nil {
+		if err.Error() == "refresh token já utilizado" {
+			if revokeErr := s.SessionDAL.RevokeSession(current.SessionID); revokeErr != nil {
+				return nil, revokeErr
+			}
+			return nil, errors.New("refresh token reutilizado, sessão encerrada")
+		}
+		return nil, err
+	}
+
+	user, err := s.AuthDAL.GetUserByID(current.Session.UserID)
+	if err != nil {
+		return nil, errors.New("refresh token inválido")
+	}
+
+	accessToken, err := s.generateAccessToken(user.ID, user.Email, current.SessionID)
+	if err != nil {
+		return nil, errors.New("erro ao gerar token de autenticação")
+	}
+
+	return &types.AuthResponse{
+		Token:        accessToken,
+		RefreshToken: nextToken,
+	}, nil
+}
+
+func (s *SessionService) Logout(refreshToken string) error {
+	token, err := s.SessionDAL.GetRefreshTokenByHash(hashToken(refreshToken))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("refresh token inválido")
+		}
+		return err
+	}
+
+	return s.SessionDAL.RevokeSession(token.SessionID)
+}
+
+func (s *SessionService) ValidateSession(sessionID uint, userID uint) error {
+	session, err := s.SessionDAL.GetSessionByID(sessionID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("sessão encerrada")
+		}
+		return err
+	}
+
+	if session.UserID != userID || session.RevogadaEm != nil {
+		return errors.New("sessão encerrada")
+	}
+
+	if time.Now().After(session.ExpiraEm) {
+		return errors.New("sessão expirada")
+	}
+
+	if now := time.Now(); now.Sub(session.UltimoAcesso) > lastSeenInterval {
+		if err := s.SessionDAL.TouchSession(session.ID, now); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *SessionService) ListSessions(userID uint, currentSessionID uint) ([]types.SessionResponse, error) {
+	sessions, err := s.SessionDAL.GetActiveSessionsByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]types.SessionResponse, 0, len(sessions))
+	for _, session := range sessions {
+		response = append(response, types.SessionResponse{
+			ID:           session.ID,
+			Dispositivo:  session.Dispositivo,
+			UserAgent:    session.UserAgent,
+			IP:           session.IP,
+			CriadoEm:     session.CreatedAt,
+			UltimoAcesso: session.UltimoAcesso,
+			Atual:        session.ID == currentSessionID,
+		})
+	}
+
+	return response, nil
+}
+
+func (s *SessionService) RevokeSession(userID uint, sessionID uint) error {
+	if sessionID == 0 {
+		return errors.New("ID da sessão é obrigatório")
+	}
+
+	return s.SessionDAL.RevokeUserSession(sessionID, userID)
+}
+
+func (s *SessionService) RevokeOtherSessions(userID uint, currentSessionID uint) (int64, error) {
+	if userID == 0 {
+		return 0, errors.New("usuário é obrigatório")
+	}
+
+	return s.SessionDAL.RevokeOtherSessions(userID, currentSessionID)
+}
+
+func (s *SessionService) activeRefreshToken(refreshToken string) (*types.RefreshToken, error) {
+	if refreshToken == "" {
+		return nil, errors.New("refresh token é obrigatório")
+	}
+
+	token, err := s.SessionDAL.GetRefreshTokenByHash(hashToken(refreshToken))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("refresh token inválido")
+		}
+		return nil, err
+	}
+
+	if token.Session.ID == 0 || token.Session.RevogadaEm != nil {
+		return nil, errors.New("sessão encerrada")
+	}
+
+	if time.Now().After(token.ExpiraEm) {
+		return nil, errors.New("refresh token expirado")
+	}
+
+	return token, nil
+}
+
+func (s *SessionService) newRefreshToken() (string, *types.RefreshToken, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", nil, errors.New("erro ao gerar refresh token")
+	}
+
+	refreshToken := base64.RawURLEncoding.EncodeToString(raw)
+	return refreshToken, &types.RefreshToken{
+		TokenHash: hashToken(refreshToken),
+		ExpiraEm:  time.Now().Add(s.RefreshTokenTTL),
+	}, nil
+}
+
+func (s *SessionService) generateAccessToken(userID uint, email string, sessionID uint) (string, error) {
+	claims := jwt.MapClaims{
+		"id":    userID,
+		"email": email,
+		"sid":   sessionID,
+		"typ":   accessTokenType,
+		"exp":   time.Now().Add(s.AccessTokenTTL).Unix(),
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+
+	return token.SignedString(s.JWTSecret)
+}
+
+func (s *SessionService) ParseAccessToken(tokenString string) (*types.AccessClaims, error) {
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, errors.New("método de assinatura inválido")
+		}
+		return s.JWTSecret, nil
+	})
+	if err != nil || !token.Valid {
+		return nil, errors.New("token expirado ou inválido")
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return nil, errors.New("claims do token inválidos")
+	}
+
+	if typ, _ := claims["typ"].(string); typ != accessTokenType {
+		return nil, errors.New("tipo de token inválido")
+	}
+
+	userID, ok := claims["id"].(float64)
+	if !ok {
+		return nil, errors.New("ID do usuário não encontrado no token")
+	}
+
+	email, ok := claims["email"].(string)
+	if !ok {
+		return nil, errors.New("email do usuário não encontrado no token")
+	}
+
+	sessionID, ok := claims["sid"].(float64)
+	if !ok {
+		return nil, errors.New("sessão não encontrada no token")
+	}
+
+	return &types.AccessClaims{
+		UserID:    uint(userID),
+		Email:     email,
+		SessionID: uint(sessionID),
+	}, nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func truncate(value string, max int) string {
+	runes := []rune(value)
+	if len(runes) <= max {
+		return value
+	}
+	return string(runes[:max])
+}
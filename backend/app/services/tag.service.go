@@ -0,0 +1,152 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/Vicente/Password-Mobile-App/backend/app/dal"
+	"github.com/Vicente/Password-Mobile-App/backend/app/types"
+	"gorm.io/gorm"
+)
+
+const (
+	maxTagNameLength = 50
+	maxTagsPerItem   = 20
+)
+
+type TagService struct {
+	TagDAL *dal.TagDAL
+}
+
+func NewTagService(tagDAL *dal.TagDAL) *TagService {
+	return &TagService{
+		TagDAL: tagDAL,
+	}
+}
+
+func (s *TagService) ListTags(userID uint) ([]types.TagResponse, error) {
+	tags, err := s.TagDAL.GetTagsByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	counts, err := s.TagDAL.CountItemsByTag(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]types.TagResponse, 0, len(tags))
+	for _, tag := range tags {
+		response = append(response, types.TagResponse{ID: tag.ID, Nome: tag.Nome, Itens: counts[tag.ID]})
+	}
+	return response, nil
+}
+
+func (s *TagService) CreateTag(req *types.TagRequest) (*types.TagResponse, error) {
+	if req.UserID == 0 {
+		return nil, errors.New("usuário é obrigatório")
+	}
+
+	nome, err := validateTagName(req.Nome)
+	if err != nil {
+		return nil, err
+	}
+
+	tag := &types.Tag{Nome: nome, UserID: req.UserID}
+	if err := s.TagDAL.CreateTag(tag); err != nil {
+		return nil, err
+	}
+
+	return &types.TagResponse{ID: tag.ID, Nome: tag.Nome}, nil
+}
+
+func (s *TagService) RenameTag(req *types.TagRequest) (*types.TagResponse, error) {
+	tag, err := s.getTag(req.ID, req.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	if tag.Nome, err = validateTagName(req.Nome); err != nil {
+		return nil, err
+	}
+
+	if err := s.TagDAL.UpdateTag(tag); err != nil {
+		return nil, err
+	}
+
+	counts, err := s.TagDAL.CountItemsByTag(req.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &types.TagResponse{ID: tag.ID, Nome: tag.Nome, Itens: counts[tag.ID]}, nil
+}
+
+// DeleteTag remove a tag de todos os itens e depois a apaga.
+func (s *TagService) DeleteTag(tagID uint, userID uint) error {
+	tag, err := s.getTag(tagID, userID)
+	if err != nil {
+		return err
+	}
+	return s.TagDAL.DeleteTag(tag)
+}
+
+func (s *TagService) getTag(tagID uint, userID uint) (*types.Tag, error) {
+	tag, err := s.TagDAL.GetTagByID(tagID, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("tag não encontrada")
+		}
+		return nil, err
+	}
+	return tag, nil
+}
+
+func validateTagName(nome string) (string, error) {
+	nome = strings.TrimSpace(nome)
+	if nome == "" {
+		return "", errors.New("nome da tag é obrigatório")
+	}
+	if strings.Contains(nome, ",") {
+		return "", errors.New("o nome da tag não pode conter vírgulas")
+	}
+	if utf8.RuneCountInString(nome) > maxTagNameLength {
+		return "", errors.New("o nome da tag deve ter no máximo 50 caracteres")
+	}
+	return nome, nil
+}
+
+// normalizeTags valida as tags de um item e remove as repetidas, mantendo a
+// ordem em que vieram.
+func normalizeTags(names []string) ([]string, error) {
+	seen := make(map[string]bool, len(names))
+	tags := make([]string, 0, len(names))
+	for _, name := range names {
+		nome, err := validateTagName(name)
+		if err != nil {
+			return nil, err
+		}
+		if !seen[nome] {
+			seen[nome] = true
+			tags = append(tags, nome)
+		}
+	}
+
+	if len(tags) > maxTagsPerItem {
+		return nil, errors.New("um item pode ter no máximo 20 tags")
+	}
+	return tags, nil
+}
+
+func tagNames(tags []types.Tag) []string {
+	if len(tags) == 0 {
+		return nil
+	}
+
+	names := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		names = append(names, tag.Nome)
+	}
+	return names
+}
@@ -0,0 +1,112 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"github.com/Vicente/Password-Mobile-App/backend/app/otp"
+	"github.com/Vicente/Password-Mobile-App/backend/app/types"
+)
+
+// GetItemTOTP gera o código atual do autenticador guardado no item. A consulta
+// não altera o item: códigos HOTP dependem do contador e são gerados por
+// NextItemHOTP.
+func (s *ItemService) GetItemTOTP(itemID uint, userID uint) (*types.ItemTOTPResponse, error) {
+	_, _, key, err := s.itemOTPKey(itemID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if key.Type == otp.TypeHOTP {
+		return nil, errors.New("códigos HOTP são gerados com POST /api/item/:id/hotp")
+	}
+
+	now := time.Now()
+	codigo, err := key.Code(now)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &types.ItemTOTPResponse{
+		Codigo:   codigo,
+		Tipo:     key.Type,
+		Digitos:  len(codigo),
+		Periodo:  key.Period,
+		Restante: key.Remaining(now),
+	}
+	if key.Steam {
+		response.Tipo = "steam"
+	}
+	return response, nil
+}
+
+// NextItemHOTP gera o código HOTP do contador guardado e o avança, como faria
+// um aplicativo autenticador. O avanço é uma edição do item e muda a revisão;
+// se outra requisição alterou o item no meio tempo, nada é gravado.
+func (s *ItemService) NextItemHOTP(itemID uint, userID uint) (*types.ItemTOTPResponse, error) {
+	item, dados, key, err := s.itemOTPKey(itemID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if key.Type != otp.TypeHOTP {
+		return nil, errors.New("o autenticador do item não é HOTP")
+	}
+
+	codigo, err := key.Code(time.Now())
+	if err != nil {
+		return nil, err
+	}
+	contador := key.Counter
+
+	key.Counter++
+	dados.TOTP = key.URI()
+	if item.DadosCifrados, err = s.encryptItemData(userID, item.ID, &dados); err != nil {
+		return nil, err
+	}
+	if err := s.ItemDAL.UpdateItem(item, item.Revisao); err != nil {
+		return nil, err
+	}
+
+	return &types.ItemTOTPResponse{
+		Codigo:   codigo,
+		Tipo:     key.Type,
+		Digitos:  len(codigo),
+		Contador: &contador,
+		Revisao:  item.Revisao,
+	}, nil
+}
+
+// itemOTPKey lê o autenticador guardado em um item.
+func (s *ItemService) itemOTPKey(itemID uint, userID uint) (*types.Item, types.ItemData, otp.Key, error) {
+	if itemID == 0 {
+		return nil, types.ItemData{}, otp.Key{}, errors.New("ID do item é obrigatório")
+	}
+
+	if userID == 0 {
+		return nil, types.ItemData{}, otp.Key{}, errors.New("usuário é obrigatório")
+	}
+
+	item, err := s.getItem(itemID, userID)
+	if err != nil {
+		return nil, types.ItemData{}, otp.Key{}, err
+	}
+
+	if len(item.Blob) > 0 {
+		return nil, types.ItemData{}, otp.Key{}, errors.New("o código de itens zero-knowledge é gerado no cliente")
+	}
+
+	dados, err := s.decryptItemData(item)
+	if err != nil {
+		return nil, types.ItemData{}, otp.Key{}, err
+	}
+	if dados.TOTP == "" {
+		return nil, types.ItemData{}, otp.Key{}, errors.New("o item não tem TOTP configurado")
+	}
+
+	key, err := otp.Parse(dados.TOTP)
+	if err != nil {
+		return nil, types.ItemData{}, otp.Key{}, err
+	}
+	return item, dados, key, nil
+}
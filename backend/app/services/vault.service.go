@@ -0,0 +1,166 @@
+package services
+
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+
+	"github.com/Vicente/Password-Mobile-App/backend/app/dal"
+	"github.com/Vicente/Password-Mobile-App/backend/app/types"
+	"gorm.io/gorm"
+)
+
+// Blob zero-knowledge: [versão (1 byte)][nonce XChaCha20-Poly1305 (24 bytes)][dados + tag (16 bytes)].
+const (
+	blobVersion       byte = 1
+	blobNonceSize          = 24
+	blobTagSize            = 16
+	maxBlobSize            = 64 * 1024
+	kdfArgon2id            = "argon2id"
+	minKDFSaltSize         = 16
+	maxKDFSaltSize         = 64
+	minKDFMemory           = 19 * 1024
+	maxKDFMemory           = 1024 * 1024
+	maxKDFIterations       = 20
+	maxKDFParallelism      = 16
+)
+
+type VaultService struct {
+	VaultDAL *dal.VaultDAL
+	ItemDAL  *dal.ItemDAL
+}
+
+func NewVaultService(vaultDAL *dal.VaultDAL, itemDAL *dal.ItemDAL) *VaultService {
+	return &VaultService{
+		VaultDAL: vaultDAL,
+		ItemDAL:  itemDAL,
+	}
+}
+
+func (s *VaultService) GetConfig(userID uint) (*types.VaultConfigResponse, error) {
+	config, err := s.VaultDAL.GetConfigByUserID(userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return &types.VaultConfigResponse{}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	response := &types.VaultConfigResponse{ZeroKnowledge: config.ZeroKnowledge}
+	if config.ZeroKnowledge {
+		kdf := config.KDF
+		response.KDF = &kdf
+	}
+	return response, nil
+}
+
+func (s *VaultService) IsZeroKnowledge(userID uint) (bool, error) {
+	config, err := s.VaultDAL.GetConfigByUserID(userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	return config.ZeroKnowledge, nil
+}
+
+func (s *VaultService) EnableZeroKnowledge(req *types.EnableZeroKnowledgeRequest) (*types.VaultConfigResponse, error) {
+	if req.UserID == 0 {
+		return nil, errors.New("usuário é obrigatório")
+	}
+
+	if err := ValidateKDFParams(&req.KDF); err != nil {
+		return nil, err
+	}
+
+	config, err := s.VaultDAL.GetConfigByUserID(req.UserID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		config = &types.VaultConfig{UserID: req.UserID}
+	} else if err != nil {
+		return nil, err
+	}
+
+	if config.ZeroKnowledge {
+		return nil, errors.New("o modo zero-knowledge já está ativo")
+	}
+
+	count, err := s.ItemDAL.CountItemsByUserID(req.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if count > 0 {
+		return nil, errors.New("o cofre deve estar vazio para ativar o modo zero-knowledge")
+	}
+
+	config.ZeroKnowledge = true
+	config.KDF = req.KDF
+	config.KDF.Algoritmo = kdfArgon2id
+
+	if err := s.VaultDAL.SaveConfig(config); err != nil {
+		return nil, err
+	}
+
+	kdf := config.KDF
+	return &types.VaultConfigResponse{
+		ZeroKnowledge: true,
+		KDF:           &kdf,
+	}, nil
+}
+
+func ValidateKDFParams(params *types.KDFParams) error {
+	algoritmo := strings.ToLower(strings.TrimSpace(params.Algoritmo))
+	if algoritmo != kdfArgon2id {
+		return errors.New("algoritmo de derivação não suportado, use argon2id")
+	}
+
+	if len(params.Salt) < minKDFSaltSize || len(params.Salt) > maxKDFSaltSize {
+		return errors.New("o salt deve ter entre 16 e 64 bytes")
+	}
+
+	if params.Memoria < minKDFMemory || params.Memoria > maxKDFMemory {
+		return errors.New("memória do argon2id deve estar entre 19456 e 1048576 KiB")
+	}
+
+	if params.Iteracoes == 0 || params.Iteracoes > maxKDFIterations {
+		return errors.New("iterações do argon2id devem estar entre 1 e 20")
+	}
+
+	if params.Paralelismo == 0 || params.Paralelismo > maxKDFParallelism {
+		return errors.New("paralelismo do argon2id deve estar entre 1 e 16")
+	}
+
+	return nil
+}
+
+func DecodeBlob(blob string) ([]byte, error) {
+	blob = strings.TrimSpace(blob)
+	if blob == "" {
+		return nil, errors.New("blob cifrado é obrigatório")
+	}
+
+	if len(blob) > base64.StdEncoding.EncodedLen(maxBlobSize) {
+		return nil, errors.New("blob cifrado excede o tamanho máximo de 64 KiB")
+	}
+
+	data, err := base64.StdEncoding.DecodeString(blob)
+	if err != nil {
+		return nil, errors.New("blob cifrado deve estar em base64")
+	}
+
+	if len(data) > maxBlobSize {
+		return nil, errors.New("blob cifrado excede o tamanho máximo de 64 KiB")
+	}
+
+	if len(data) < 1+blobNonceSize+blobTagSize {
+		return nil, errors.New("blob cifrado muito curto")
+	}
+
+	if data[0] != blobVersion {
+		return nil, errors.New("versão do blob cifrado não suportada")
+	}
+
+	return data, nil
+}
+
+func EncodeBlob(data []byte) string {
+	return base64.StdEncoding.EncodeToString(data)
+}
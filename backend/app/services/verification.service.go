@@ -0,0 +1,175 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Vicente/Password-Mobile-App/backend/app/dal"
+	"github.com/Vicente/Password-Mobile-App/backend/app/mailer"
+	"github.com/Vicente/Password-Mobile-App/backend/app/ratelimit"
+	"github.com/Vicente/Password-Mobile-App/backend/app/types"
+	"github.com/golang-jwt/jwt/v4"
+)
+
+const (
+	UnverifiedAllow    = "allow"
+	UnverifiedReadOnly = "read-only"
+	UnverifiedBlock    = "block"
+
+	verifyTokenType       = "verify"
+	defaultVerifyTokenTTL = 24 * time.Hour
+)
+
+var resendVerificationRateLimit = ratelimit.Rule{Limit: 3, Window: time.Hour}
+
+type VerificationService struct {
+	AuthDAL   *dal.AuthDAL
+	Mailer    mailer.Mailer
+	Limiter   *ratelimit.Limiter
+	JWTSecret []byte
+	TokenTTL  time.Duration
+	VerifyURL string
+	Policy    string
+}
+
+func NewVerificationService(authDAL *dal.AuthDAL, mail mailer.Mailer, limiter *ratelimit.Limiter, jwtSecret string) *VerificationService {
+	return &VerificationService{
+		AuthDAL:   authDAL,
+		Mailer:    mail,
+		Limiter:   limiter,
+		JWTSecret: []byte(jwtSecret),
+		TokenTTL:  defaultVerifyTokenTTL,
+		Policy:    UnverifiedAllow,
+	}
+}
+
+func ValidateUnverifiedPolicy(policy string) error {
+	switch policy {
+	case UnverifiedAllow, UnverifiedReadOnly, UnverifiedBlock:
+		return nil
+	}
+	return fmt.Errorf("política inválida %q, use allow, read-only ou block", policy)
+}
+
+func (s *VerificationService) SendVerification(user *types.User, email string) error {
+	token, err := s.generateToken(user.ID, email)
+	if err != nil {
+		return errors.New("erro ao gerar token de verificação")
+	}
+
+	body := fmt.Sprintf(`Olá, %s!
+
+Confirme o seu email para concluir o cadastro.
+Use o link abaixo em até %d horas:
+
+%s
+
+Se você não criou uma conta, ignore este email.
+`, user.Nome, int(s.TokenTTL.Hours()), tokenLink(s.VerifyURL, token))
+
+	return s.Mailer.Send(mailer.Message{
+		To:      email,
+		Subject: "Confirme o seu email",
+		Body:    body,
+	})
+}
+
+func (s *VerificationService) Resend(userID uint) error {
+	user, err := s.AuthDAL.GetUserByID(userID)
+	if err != nil {
+		return errors.New("usuário não encontrado")
+	}
+
+	email := user.EmailPendente
+	if email == "" {
+		if user.EmailVerificado {
+			return errors.New("email já verificado")
+		}
+		email = user.Email
+	}
+
+	if err := s.Limiter.Allow(fmt.Sprintf("verify:%d", userID), resendVerificationRateLimit); err != nil {
+		return err
+	}
+
+	if err := s.SendVerification(user, email); err != nil {
+		log.Printf("Falha ao enviar email de verificação: %v", err)
+		return errors.New("erro ao enviar email de verificação")
+	}
+	return nil
+}
+
+func (s *VerificationService) Verify(tokenString string) error {
+	if strings.TrimSpace(tokenString) == "" {
+		return errors.New("token é obrigatório")
+	}
+
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, errors.New("método de assinatura inválido")
+		}
+		return s.JWTSecret, nil
+	})
+	if err != nil || !token.Valid {
+		return errors.New("link de verificação inválido ou expirado")
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return errors.New("link de verificação inválido ou expirado")
+	}
+
+	if typ, _ := claims["typ"].(string); typ != verifyTokenType {
+		return errors.New("link de verificação inválido ou expirado")
+	}
+
+	userID, ok := claims["id"].(float64)
+	email, emailOK := claims["email"].(string)
+	if !ok || !emailOK {
+		return errors.New("link de verificação inválido ou expirado")
+	}
+
+	// O email faz parte do token: um link antigo deixa de valer se o email mudar.
+	return s.AuthDAL.ConfirmEmail(uint(userID), email)
+}
+
+// CheckAccess aplica a política para contas não verificadas. As rotas de
+// /api/auth e /api/me continuam liberadas para que o usuário consiga reenviar
+// o email, corrigir o endereço, trocar a senha e encerrar sessões.
+func (s *VerificationService) CheckAccess(userID uint, method string, path string) error {
+	if s.Policy == UnverifiedAllow || strings.HasPrefix(path, "/api/auth/") ||
+		path == "/api/me" || strings.HasPrefix(path, "/api/me/") {
+		return nil
+	}
+
+	if s.Policy == UnverifiedReadOnly && (method == http.MethodGet || method == http.MethodHead) {
+		return nil
+	}
+
+	user, err := s.AuthDAL.GetUserByID(userID)
+	if err != nil {
+		return errors.New("usuário não encontrado")
+	}
+
+	if !user.EmailVerificado {
+		return errors.New("confirme o seu email para continuar")
+	}
+	return nil
+}
+
+func (s *VerificationService) generateToken(userID uint, email string) (string, error) {
+	claims := jwt.MapClaims{
+		"id":    userID,
+		"email": email,
+		"typ":   verifyTokenType,
+		"exp":   time.Now().Add(s.TokenTTL).Unix(),
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+
+	return token.SignedString(s.JWTSecret)
+}
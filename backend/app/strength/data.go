@@ -0,0 +1,96 @@
+package strength
+
+import (
+	"embed"
+	"encoding/json"
+	"strings"
+	"sync"
+)
+
+// As listas em inglês, nomes, sobrenomes e senhas comuns e os grafos de
+// teclado vêm do zxcvbn (licença MIT); portuguese.txt é uma lista curta de
+// palavras e senhas comuns no Brasil. Cada lista está ordenada por
+// frequência, e a posição da palavra é o número de tentativas atribuído a ela.
+//
+//go:embed data
+var dataFS embed.FS
+
+// dictionaryNames fixa a ordem de consulta: em caso de empate no número de
+// tentativas vale o primeiro dicionário.
+var dictionaryNames = []string{"passwords", "english", "portuguese", "female_names", "male_names", "surnames"}
+
+var dictionaries = sync.OnceValue(func() map[string]map[string]int {
+	dicts := make(map[string]map[string]int)
+	for _, name := range dictionaryNames {
+		content, err := dataFS.ReadFile("data/" + name + ".txt")
+		if err != nil {
+			panic(err)
+		}
+		dicts[name] = rankedDictionary(strings.Split(strings.TrimSpace(string(content)), "\n"))
+	}
+	return dicts
+})
+
+func rankedDictionary(words []string) map[string]int {
+	ranked := make(map[string]int, len(words))
+	for i, word := range words {
+		if _, ok := ranked[word]; !ok {
+			ranked[word] = i + 1
+		}
+	}
+	return ranked
+}
+
+type adjacencyGraph struct {
+	name          string
+	keys          map[rune][]string
+	startingCount float64
+	averageDegree float64
+}
+
+var graphs = sync.OnceValue(func() []*adjacencyGraph {
+	var result []*adjacencyGraph
+	for _, name := range []string{"qwerty", "keypad"} {
+		content, err := dataFS.ReadFile("data/" + name + ".json")
+		if err != nil {
+			panic(err)
+		}
+
+		var raw map[string][]*string
+		if err := json.Unmarshal(content, &raw); err != nil {
+			panic(err)
+		}
+
+		graph := &adjacencyGraph{name: name, keys: make(map[rune][]string, len(raw))}
+		neighbors := 0
+		for key, adjacent := range raw {
+			list := make([]string, len(adjacent))
+			for i, value := range adjacent {
+				if value != nil {
+					list[i] = *value
+					neighbors++
+				}
+			}
+			graph.keys[[]rune(key)[0]] = list
+		}
+		graph.startingCount = float64(len(raw))
+		graph.averageDegree = float64(neighbors) / float64(len(raw))
+		result = append(result, graph)
+	}
+	return result
+})
+
+var l33tTable = map[rune][]rune{
+	'a': {'4', '@'},
+	'b': {'8'},
+	'c': {'(', '{', '[', '<'},
+	'e': {'3'},
+	'g': {'6', '9'},
+	'i': {'1', '!', '|'},
+	'l': {'1', '|', '7'},
+	'o': {'0'},
+	's': {'$', '5'},
+	't': {'+', '7'},
+	'x': {'%'},
+	'z': {'2'},
+}
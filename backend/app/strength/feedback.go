@@ -0,0 +1,116 @@
+package strength
+
+import (
+	"math"
+	"unicode"
+)
+
+const suggestionExtraWord = "Acrescente mais uma ou duas palavras; palavras incomuns são melhores."
+
+func feedback(score int, sequence []*match) (string, []string) {
+	if len(sequence) == 0 {
+		return "", []string{
+			"Use algumas palavras e evite frases comuns.",
+			"Não é preciso usar símbolos, números ou letras maiúsculas.",
+		}
+	}
+	if score >= MinScore {
+		return "", nil
+	}
+
+	longest := sequence[0]
+	for _, m := range sequence[1:] {
+		if len([]rune(m.token)) > len([]rune(longest.token)) {
+			longest = m
+		}
+	}
+
+	warning, suggestions := matchFeedback(longest, len(sequence) == 1)
+	return warning, append([]string{suggestionExtraWord}, suggestions...)
+}
+
+func matchFeedback(m *match, soleMatch bool) (string, []string) {
+	switch m.pattern {
+	case patternDictionary:
+		return dictionaryFeedback(m, soleMatch)
+	case patternSpatial:
+		warning := "Padrões curtos de teclado são fáceis de adivinhar."
+		if m.turns == 1 {
+			warning = "Sequências retas de teclas são fáceis de adivinhar."
+		}
+		return warning, []string{"Use um padrão de teclado mais longo e com mais mudanças de direção."}
+	case patternRepeat:
+		warning := "Repetições como \"abcabcabc\" são só um pouco mais difíceis de adivinhar que \"abc\"."
+		if len([]rune(m.base)) == 1 {
+			warning = "Repetições como \"aaa\" são fáceis de adivinhar."
+		}
+		return warning, []string{"Evite palavras e caracteres repetidos."}
+	case patternSequence:
+		return "Sequências como abc ou 6543 são fáceis de adivinhar.", []string{"Evite sequências."}
+	case patternYear:
+		return "Anos recentes são fáceis de adivinhar.", []string{
+			"Evite anos recentes.",
+			"Evite anos associados a você.",
+		}
+	case patternDate:
+		return "Datas costumam ser fáceis de adivinhar.", []string{"Evite datas e anos associados a você."}
+	}
+	return "", nil
+}
+
+func dictionaryFeedback(m *match, soleMatch bool) (string, []string) {
+	var warning string
+	switch m.dict {
+	case "passwords":
+		switch {
+		case soleMatch && !m.l33t && !m.reversed && m.rank <= 10:
+			warning = "Esta é uma das 10 senhas mais comuns."
+		case soleMatch && !m.l33t && !m.reversed && m.rank <= 100:
+			warning = "Esta é uma das 100 senhas mais comuns."
+		case soleMatch && !m.l33t && !m.reversed:
+			warning = "Esta é uma senha muito comum."
+		case math.Log10(m.guesses) <= 4:
+			warning = "Esta senha é parecida com uma senha muito usada."
+		}
+	case "english", "portuguese":
+		if soleMatch {
+			warning = "Uma palavra sozinha é fácil de adivinhar."
+		}
+	case "surnames", "male_names", "female_names":
+		if soleMatch {
+			warning = "Nomes e sobrenomes sozinhos são fáceis de adivinhar."
+		} else {
+			warning = "Nomes e sobrenomes comuns são fáceis de adivinhar."
+		}
+	case dictUserInputs:
+		warning = "Senhas baseadas no seu nome ou e-mail são fáceis de adivinhar."
+	}
+
+	var suggestions []string
+	runes := []rune(m.token)
+	if startsUpper(runes) {
+		suggestions = append(suggestions, "Começar com letra maiúscula não ajuda muito.")
+	} else if allUpper(runes) {
+		suggestions = append(suggestions, "Tudo em maiúsculas é quase tão fácil de adivinhar quanto tudo em minúsculas.")
+	}
+	if m.reversed && len(runes) >= 4 {
+		suggestions = append(suggestions, "Palavras invertidas não são muito mais difíceis de adivinhar.")
+	}
+	if m.l33t {
+		suggestions = append(suggestions, "Substituições previsíveis, como \"@\" no lugar de \"a\", não ajudam muito.")
+	}
+	return warning, suggestions
+}
+
+func allUpper(runes []rune) bool {
+	hasUpper := false
+	for _, r := range runes {
+		if unicode.IsLower(r) {
+			return false
+		}
+		if unicode.IsUpper(r) {
+			hasUpper = true
+		}
+	}
+	return hasUpper
+}
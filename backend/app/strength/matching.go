@@ -0,0 +1,593 @@
+package strength
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"unicode"
+)
+
+const (
+	patternDictionary = "dictionary"
+	patternSpatial    = "spatial"
+	patternRepeat     = "repeat"
+	patternSequence   = "sequence"
+	patternYear       = "year"
+	patternDate       = "date"
+	patternBruteforce = "bruteforce"
+
+	dictUserInputs = "user_inputs"
+
+	minYear      = 1000
+	maxYear      = 2050
+	maxDelta     = 5
+	maxL33tSubs  = 256
+	shiftedChars = "~!@#$%^&*()_+QWERTYUIOP{}|ASDFGHJKL:\"ZXCVBNM<>?"
+)
+
+type match struct {
+	pattern string
+	i, j    int
+	token   string
+	guesses float64
+
+	// dictionary
+	word     string
+	rank     int
+	dict     string
+	reversed bool
+	l33t     bool
+	sub      map[rune]rune
+
+	// spatial
+	graph   string
+	turns   int
+	shifted int
+
+	// repeat
+	base        string
+	baseGuesses float64
+	repeats     int
+
+	// sequence
+	ascending bool
+
+	// date e year
+	year      int
+	separator string
+}
+
+func omnimatch(password []rune, userInputs map[string]int) []*match {
+	dicts := dictionaries()
+	if len(userInputs) > 0 {
+		withInputs := make(map[string]map[string]int, len(dicts)+1)
+		for name, dict := range dicts {
+			withInputs[name] = dict
+		}
+		withInputs[dictUserInputs] = userInputs
+		dicts = withInputs
+	}
+
+	var matches []*match
+	matches = append(matches, dictionaryMatch(password, dicts)...)
+	matches = append(matches, reverseDictionaryMatch(password, dicts)...)
+	matches = append(matches, l33tMatch(password, dicts)...)
+	matches = append(matches, spatialMatch(password)...)
+	matches = append(matches, repeatMatch(password, userInputs)...)
+	matches = append(matches, sequenceMatch(password)...)
+	matches = append(matches, yearMatch(password)...)
+	matches = append(matches, dateMatch(password)...)
+
+	sort.SliceStable(matches, func(a, b int) bool {
+		if matches[a].i != matches[b].i {
+			return matches[a].i < matches[b].i
+		}
+		return matches[a].j < matches[b].j
+	})
+	return matches
+}
+
+// normalize passa para minúsculas e remove acentos, mantendo uma runa por
+// runa para que os índices continuem valendo no texto original.
+func normalize(password []rune) []rune {
+	out := make([]rune, len(password))
+	for i, r := range password {
+		r = unicode.ToLower(r)
+		if folded, ok := accents[r]; ok {
+			r = folded
+		}
+		out[i] = r
+	}
+	return out
+}
+
+var accents = map[rune]rune{
+	'á': 'a', 'à': 'a', 'â': 'a', 'ã': 'a', 'ä': 'a',
+	'é': 'e', 'è': 'e', 'ê': 'e', 'ë': 'e',
+	'í': 'i', 'ì': 'i', 'î': 'i', 'ï': 'i',
+	'ó': 'o', 'ò': 'o', 'ô': 'o', 'õ': 'o', 'ö': 'o',
+	'ú': 'u', 'ù': 'u', 'û': 'u', 'ü': 'u',
+	'ç': 'c', 'ñ': 'n',
+}
+
+func dictionaryMatch(password []rune, dicts map[string]map[string]int) []*match {
+	var matches []*match
+	lower := normalize(password)
+	n := len(password)
+	names := make([]string, 0, len(dictionaryNames)+1)
+	names = append(append(names, dictionaryNames...), dictUserInputs)
+
+	for i := 0; i < n; i++ {
+		for j := i; j < n; j++ {
+			word := string(lower[i : j+1])
+			for _, name := range names {
+				if rank, ok := dicts[name][word]; ok {
+					matches = append(matches, &match{
+						pattern: patternDictionary,
+						i:       i,
+						j:       j,
+						token:   string(password[i : j+1]),
+						word:    word,
+						rank:    rank,
+						dict:    name,
+					})
+				}
+			}
+		}
+	}
+	return matches
+}
+
+func reverseDictionaryMatch(password []rune, dicts map[string]map[string]int) []*match {
+	n := len(password)
+	reversed := make([]rune, n)
+	for i, r := range password {
+		reversed[n-1-i] = r
+	}
+
+	matches := dictionaryMatch(reversed, dicts)
+	for _, m := range matches {
+		m.i, m.j = n-1-m.j, n-1-m.i
+		m.token = string(password[m.i : m.j+1])
+		m.reversed = true
+	}
+	return matches
+}
+
+func l33tMatch(password []rune, dicts map[string]map[string]int) []*match {
+	var matches []*match
+	seen := make(map[string]bool)
+
+	for _, sub := range l33tSubs(password) {
+		subbed := make([]rune, len(password))
+		for i, r := range password {
+			if letter, ok := sub[r]; ok {
+				r = letter
+			}
+			subbed[i] = r
+		}
+
+		for _, m := range dictionaryMatch(subbed, dicts) {
+			token := password[m.i : m.j+1]
+			// Só conta como l33t se alguma substituição foi de fato usada, e
+			// um caractere sozinho não é uma palavra disfarçada.
+			if len(token) <= 1 || string(normalize(token)) == m.word {
+				continue
+			}
+
+			used := make(map[rune]rune)
+			for _, r := range token {
+				if letter, ok := sub[r]; ok {
+					used[r] = letter
+				}
+			}
+
+			key := strconv.Itoa(m.i) + ":" + strconv.Itoa(m.j) + ":" + m.dict + ":" + m.word
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+
+			m.token = string(token)
+			m.l33t = true
+			m.sub = used
+			matches = append(matches, m)
+		}
+	}
+	return matches
+}
+
+// l33tSubs enumera as formas de ler os caracteres l33t da senha: cada um vira
+// uma das letras que pode representar (o "1" pode ser "i" ou "l").
+func l33tSubs(password []rune) []map[rune]rune {
+	candidates := make(map[rune][]rune)
+	var chars []rune
+	for _, r := range password {
+		if _, done := candidates[r]; done {
+			continue
+		}
+		var letters []rune
+		for letter, subs := range l33tTable {
+			for _, s := range subs {
+				if s == r {
+					letters = append(letters, letter)
+				}
+			}
+		}
+		if len(letters) > 0 {
+			sort.Slice(letters, func(a, b int) bool { return letters[a] < letters[b] })
+			candidates[r] = letters
+			chars = append(chars, r)
+		}
+	}
+
+	if len(chars) == 0 {
+		return nil
+	}
+
+	subs := []map[rune]rune{{}}
+	for _, c := range chars {
+		var next []map[rune]rune
+		for _, sub := range subs {
+			for _, letter := range candidates[c] {
+				extended := make(map[rune]rune, len(sub)+1)
+				for k, v := range sub {
+					extended[k] = v
+				}
+				extended[c] = letter
+				next = append(next, extended)
+				if len(next) >= maxL33tSubs {
+					break
+				}
+			}
+			if len(next) >= maxL33tSubs {
+				break
+			}
+		}
+		subs = next
+	}
+	return subs
+}
+
+func spatialMatch(password []rune) []*match {
+	var matches []*match
+	n := len(password)
+
+	for _, graph := range graphs() {
+		i := 0
+		for i < n-1 {
+			j := i + 1
+			lastDirection := -1
+			turns := 0
+			shifted := 0
+			if graph.name == "qwerty" && containsRune(shiftedChars, password[i]) {
+				shifted = 1
+			}
+
+			for {
+				found := false
+				if j < n {
+					current := password[j]
+					for direction, adjacent := range graph.keys[password[j-1]] {
+						if adjacent == "" {
+							continue
+						}
+						if index := indexRune(adjacent, current); index >= 0 {
+							found = true
+							if index == 1 {
+								shifted++
+							}
+							if lastDirection != direction {
+								turns++
+								lastDirection = direction
+							}
+							break
+						}
+					}
+				}
+
+				if found {
+					j++
+					continue
+				}
+
+				if j-i > 2 {
+					matches = append(matches, &match{
+						pattern: patternSpatial,
+						i:       i,
+						j:       j - 1,
+						token:   string(password[i:j]),
+						graph:   graph.name,
+						turns:   turns,
+						shifted: shifted,
+					})
+				}
+				i = j
+				break
+			}
+		}
+	}
+	return matches
+}
+
+// repeatMatch procura o trecho mais longo formado por cópias de uma mesma
+// base; a base é avaliada recursivamente.
+func repeatMatch(password []rune, userInputs map[string]int) []*match {
+	var matches []*match
+	n := len(password)
+
+	i := 0
+	for i < n-1 {
+		bestLength, bestBase, bestRepeats := 0, 0, 0
+		for size := 1; i+2*size <= n; size++ {
+			repeats := 1
+			for i+(repeats+1)*size <= n && string(password[i+repeats*size:i+(repeats+1)*size]) == string(password[i:i+size]) {
+				repeats++
+			}
+			if repeats >= 2 && size*repeats > bestLength {
+				bestLength, bestBase, bestRepeats = size*repeats, size, repeats
+			}
+		}
+
+		if bestLength == 0 {
+			i++
+			continue
+		}
+
+		base := password[i : i+bestBase]
+		analysis := mostGuessableSequence(base, omnimatch(base, userInputs))
+		matches = append(matches, &match{
+			pattern:     patternRepeat,
+			i:           i,
+			j:           i + bestLength - 1,
+			token:       string(password[i : i+bestLength]),
+			base:        string(base),
+			baseGuesses: analysis.guesses,
+			repeats:     bestRepeats,
+		})
+		i += bestLength
+	}
+	return matches
+}
+
+func sequenceMatch(password []rune) []*match {
+	var matches []*match
+	n := len(password)
+	if n == 1 {
+		return nil
+	}
+
+	add := func(i, j, delta int) {
+		abs := delta
+		if abs < 0 {
+			abs = -abs
+		}
+		if (j-i > 1 || abs == 1) && abs > 0 && abs <= maxDelta {
+			matches = append(matches, &match{
+				pattern:   patternSequence,
+				i:         i,
+				j:         j,
+				token:     string(password[i : j+1]),
+				ascending: delta > 0,
+			})
+		}
+	}
+
+	i := 0
+	lastDelta := 0
+	hasDelta := false
+	for k := 1; k < n; k++ {
+		delta := int(password[k]) - int(password[k-1])
+		if !hasDelta {
+			lastDelta, hasDelta = delta, true
+		}
+		if delta == lastDelta {
+			continue
+		}
+		j := k - 1
+		add(i, j, lastDelta)
+		i = j
+		lastDelta = delta
+	}
+	add(i, n-1, lastDelta)
+	return matches
+}
+
+// yearMatch encontra anos recentes (1900 a 2039) escritos com quatro dígitos.
+func yearMatch(password []rune) []*match {
+	var matches []*match
+	for i := 0; i+4 <= len(password); {
+		if year, ok := digitsValue(password[i : i+4]); ok && year >= 1900 && year <= 2039 {
+			matches = append(matches, &match{
+				pattern: patternYear,
+				i:       i,
+				j:       i + 3,
+				token:   string(password[i : i+4]),
+				year:    year,
+			})
+			i += 4
+			continue
+		}
+		i++
+	}
+	return matches
+}
+
+var (
+	dateWithSeparator = regexp.MustCompile(`^(\d{1,4})([\s/\\_.-])(\d{1,2})([\s/\\_.-])(\d{1,4})$`)
+
+	dateSplits = map[int][][2]int{
+		4: {{1, 2}, {2, 3}},
+		5: {{1, 3}, {2, 3}},
+		6: {{1, 2}, {2, 4}, {4, 5}},
+		7: {{1, 3}, {2, 3}, {4, 5}, {4, 6}},
+		8: {{2, 4}, {4, 6}},
+	}
+)
+
+type dmy struct {
+	day, month, year int
+}
+
+func dateMatch(password []rune) []*match {
+	var matches []*match
+	n := len(password)
+
+	// Datas sem separador, como 13051990 ou 1990513.
+	for i := 0; i+4 <= n; i++ {
+		for j := i + 3; j < i+8 && j < n; j++ {
+			token := password[i : j+1]
+			if _, ok := digitsValue(token); !ok {
+				continue
+			}
+
+			var best *dmy
+			for _, split := range dateSplits[len(token)] {
+				a, _ := digitsValue(token[:split[0]])
+				b, _ := digitsValue(token[split[0]:split[1]])
+				c, _ := digitsValue(token[split[1]:])
+				if date, ok := mapIntsToDMY(a, b, c); ok {
+					if best == nil || distanceFromReference(date.year) < distanceFromReference(best.year) {
+						d := date
+						best = &d
+					}
+				}
+			}
+
+			if best != nil {
+				matches = append(matches, &match{pattern: patternDate, i: i, j: j, token: string(token), year: best.year})
+			}
+		}
+	}
+
+	// Datas com separador, como 13/05/1990 ou 1990-05-13.
+	for i := 0; i+6 <= n; i++ {
+		for j := i + 5; j < i+10 && j < n; j++ {
+			token := string(password[i : j+1])
+			parts := dateWithSeparator.FindStringSubmatch(token)
+			if parts == nil || parts[2] != parts[4] {
+				continue
+			}
+
+			a, _ := strconv.Atoi(parts[1])
+			b, _ := strconv.Atoi(parts[3])
+			c, _ := strconv.Atoi(parts[5])
+			if date, ok := mapIntsToDMY(a, b, c); ok {
+				matches = append(matches, &match{pattern: patternDate, i: i, j: j, token: token, year: date.year, separator: parts[2]})
+			}
+		}
+	}
+
+	// Descarta datas contidas em outras datas maiores.
+	var filtered []*match
+	for _, m := range matches {
+		contained := false
+		for _, other := range matches {
+			if m != other && other.i <= m.i && other.j >= m.j && (other.i != m.i || other.j != m.j) {
+				contained = true
+				break
+			}
+		}
+		if !contained {
+			filtered = append(filtered, m)
+		}
+	}
+	return filtered
+}
+
+func mapIntsToDMY(a, b, c int) (dmy, bool) {
+	if b > 31 || b <= 0 {
+		return dmy{}, false
+	}
+
+	over12, over31, under1 := 0, 0, 0
+	for _, v := range []int{a, b, c} {
+		if (v > 99 && v < minYear) || v > maxYear {
+			return dmy{}, false
+		}
+		if v > 31 {
+			over31++
+		}
+		if v > 12 {
+			over12++
+		}
+		if v <= 0 {
+			under1++
+		}
+	}
+	if over31 >= 2 || over12 == 3 || under1 >= 2 {
+		return dmy{}, false
+	}
+
+	splits := [][3]int{{c, a, b}, {a, b, c}}
+	for _, split := range splits {
+		year := split[0]
+		if year >= minYear && year <= maxYear {
+			if day, month, ok := mapIntsToDM(split[1], split[2]); ok {
+				return dmy{day: day, month: month, year: year}, true
+			}
+			// Um ano de quatro dígitos com dia e mês inválidos não é data.
+			return dmy{}, false
+		}
+	}
+
+	for _, split := range splits {
+		if day, month, ok := mapIntsToDM(split[1], split[2]); ok {
+			return dmy{day: day, month: month, year: twoToFourDigitYear(split[0])}, true
+		}
+	}
+	return dmy{}, false
+}
+
+func mapIntsToDM(a, b int) (int, int, bool) {
+	for _, pair := range [][2]int{{a, b}, {b, a}} {
+		if pair[0] >= 1 && pair[0] <= 31 && pair[1] >= 1 && pair[1] <= 12 {
+			return pair[0], pair[1], true
+		}
+	}
+	return 0, 0, false
+}
+
+func twoToFourDigitYear(year int) int {
+	switch {
+	case year > 99:
+		return year
+	case year > 50:
+		return year + 1900
+	default:
+		return year + 2000
+	}
+}
+
+func distanceFromReference(year int) int {
+	if year > referenceYear {
+		return year - referenceYear
+	}
+	return referenceYear - year
+}
+
+func digitsValue(runes []rune) (int, bool) {
+	value := 0
+	for _, r := range runes {
+		if r < '0' || r > '9' {
+			return 0, false
+		}
+		value = value*10 + int(r-'0')
+	}
+	return value, true
+}
+
+func containsRune(s string, r rune) bool {
+	return indexRune(s, r) >= 0
+}
+
+func indexRune(s string, r rune) int {
+	i := 0
+	for _, c := range s {
+		if c == r {
+			return i
+		}
+		i++
+	}
+	return -1
+}
@@ -0,0 +1,350 @@
+package strength
+
+import (
+	"math"
+	"sort"
+	"time"
+	"unicode"
+)
+
+const (
+	minGuessesBeforeGrowingSequence = 10000
+	minSubmatchGuessesSingleChar    = 10
+	minSubmatchGuessesMultiChar     = 50
+	minYearSpace                    = 20
+)
+
+var referenceYear = time.Now().Year()
+
+type analysis struct {
+	guesses  float64
+	sequence []*match
+}
+
+// mostGuessableSequence escolhe, entre os padrões encontrados, a sequência
+// que cobre a senha inteira com o menor número de tentativas. Trechos sem
+// padrão são preenchidos por força bruta.
+func mostGuessableSequence(password []rune, matches []*match) analysis {
+	n := len(password)
+	if n == 0 {
+		return analysis{guesses: 1}
+	}
+
+	matchesByJ := make([][]*match, n)
+	for _, m := range matches {
+		matchesByJ[m.j] = append(matchesByJ[m.j], m)
+	}
+	for _, list := range matchesByJ {
+		sort.SliceStable(list, func(a, b int) bool { return list[a].i < list[b].i })
+	}
+
+	// Para cada posição k e tamanho de sequência l: o último padrão usado, o
+	// produto das tentativas e o total já com o fatorial e o termo aditivo.
+	optimalM := make([]map[int]*match, n)
+	optimalPi := make([]map[int]float64, n)
+	optimalG := make([]map[int]float64, n)
+	for k := range optimalM {
+		optimalM[k] = make(map[int]*match)
+		optimalPi[k] = make(map[int]float64)
+		optimalG[k] = make(map[int]float64)
+	}
+
+	update := func(m *match, l int) {
+		k := m.j
+		pi := estimateGuesses(m, n)
+		if l > 1 {
+			pi *= optimalPi[m.i-1][l-1]
+		}
+		g := factorial(l)*pi + math.Pow(minGuessesBeforeGrowingSequence, float64(l-1))
+
+		for competingL, competingG := range optimalG[k] {
+			if competingL > l {
+				continue
+			}
+			if competingG <= g {
+				return
+			}
+		}
+		optimalG[k][l] = g
+		optimalM[k][l] = m
+		optimalPi[k][l] = pi
+	}
+
+	bruteforce := func(i, j int) *match {
+		return &match{pattern: patternBruteforce, i: i, j: j, token: string(password[i : j+1])}
+	}
+
+	for k := 0; k < n; k++ {
+		for _, m := range matchesByJ[k] {
+			if m.i > 0 {
+				for _, l := range sortedKeys(optimalM[m.i-1]) {
+					update(m, l+1)
+				}
+			} else {
+				update(m, 1)
+			}
+		}
+
+		update(bruteforce(0, k), 1)
+		for i := 1; i <= k; i++ {
+			m := bruteforce(i, k)
+			for _, l := range sortedKeys(optimalM[i-1]) {
+				// Dois trechos de força bruta seguidos nunca são melhores que um só.
+				if optimalM[i-1][l].pattern == patternBruteforce {
+					continue
+				}
+				update(m, l+1)
+			}
+		}
+	}
+
+	k := n - 1
+	bestL := 0
+	bestG := math.Inf(1)
+	for _, l := range sortedKeys(optimalG[k]) {
+		if g := optimalG[k][l]; bestL == 0 || g < bestG {
+			bestL, bestG = l, g
+		}
+	}
+
+	sequence := make([]*match, bestL)
+	for l := bestL; l > 0; l-- {
+		m := optimalM[k][l]
+		sequence[l-1] = m
+		k = m.i - 1
+	}
+
+	return analysis{guesses: bestG, sequence: sequence}
+}
+
+func estimateGuesses(m *match, passwordLength int) float64 {
+	if m.guesses != 0 {
+		return m.guesses
+	}
+
+	length := len([]rune(m.token))
+	minGuesses := 1.0
+	if length < passwordLength {
+		if length == 1 {
+			minGuesses = minSubmatchGuessesSingleChar
+		} else {
+			minGuesses = minSubmatchGuessesMultiChar
+		}
+	}
+
+	var guesses float64
+	switch m.pattern {
+	case patternBruteforce:
+		guesses = math.Min(math.Pow(10, float64(length)), math.MaxFloat64)
+		if length == 1 {
+			minGuesses = math.Max(minGuesses, minSubmatchGuessesSingleChar+1)
+		} else {
+			minGuesses = math.Max(minGuesses, minSubmatchGuessesMultiChar+1)
+		}
+	case patternDictionary:
+		guesses = float64(m.rank) * uppercaseVariations(m.token) * l33tVariations(m)
+		if m.reversed {
+			guesses *= 2
+		}
+	case patternSpatial:
+		guesses = spatialGuesses(m)
+	case patternRepeat:
+		guesses = m.baseGuesses * float64(m.repeats)
+	case patternSequence:
+		guesses = sequenceGuesses(m)
+	case patternYear:
+		guesses = math.Max(math.Abs(float64(m.year-referenceYear)), minYearSpace)
+	case patternDate:
+		guesses = math.Max(math.Abs(float64(m.year-referenceYear)), minYearSpace) * 365
+		if m.separator != "" {
+			guesses *= 4
+		}
+	}
+
+	m.guesses = math.Max(guesses, minGuesses)
+	return m.guesses
+}
+
+func spatialGuesses(m *match) float64 {
+	var starts, degree float64
+	for _, graph := range graphs() {
+		if graph.name == m.graph {
+			starts, degree = graph.startingCount, graph.averageDegree
+		}
+	}
+
+	length := len([]rune(m.token))
+	var guesses float64
+	for i := 2; i <= length; i++ {
+		possibleTurns := min(m.turns, i-1)
+		for j := 1; j <= possibleTurns; j++ {
+			guesses += nCk(i-1, j-1) * starts * math.Pow(degree, float64(j))
+		}
+	}
+
+	if m.shifted > 0 {
+		unshifted := length - m.shifted
+		if unshifted == 0 {
+			guesses *= 2
+		} else {
+			var variations float64
+			for i := 1; i <= min(m.shifted, unshifted); i++ {
+				variations += nCk(m.shifted+unshifted, i)
+			}
+			guesses *= variations
+		}
+	}
+	return guesses
+}
+
+func sequenceGuesses(m *match) float64 {
+	runes := []rune(m.token)
+	first := runes[0]
+
+	var base float64
+	switch {
+	case first == 'a' || first == 'z' || first == 'A' || first == 'Z' || first == '0' || first == '1':
+		// Sequências que começam no óbvio são testadas primeiro.
+		base = 4
+	case first >= '0' && first <= '9':
+		base = 10
+	default:
+		base = 26
+	}
+	if !m.ascending {
+		base *= 2
+	}
+	return base * float64(len(runes))
+}
+
+func uppercaseVariations(token string) float64 {
+	runes := []rune(token)
+	upper, lower := 0, 0
+	for _, r := range runes {
+		if unicode.IsUpper(r) {
+			upper++
+		} else if unicode.IsLower(r) {
+			lower++
+		}
+	}
+
+	if upper == 0 {
+		return 1
+	}
+	if startsUpper(runes) || endsUpper(runes) || lower == 0 {
+		return 2
+	}
+
+	var variations float64
+	for i := 1; i <= min(upper, lower); i++ {
+		variations += nCk(upper+lower, i)
+	}
+	return variations
+}
+
+func startsUpper(runes []rune) bool {
+	if len(runes) < 2 || !unicode.IsUpper(runes[0]) {
+		return false
+	}
+	for _, r := range runes[1:] {
+		if unicode.IsUpper(r) {
+			return false
+		}
+	}
+	return true
+}
+
+func endsUpper(runes []rune) bool {
+	last := len(runes) - 1
+	if len(runes) < 2 || !unicode.IsUpper(runes[last]) {
+		return false
+	}
+	for _, r := range runes[:last] {
+		if unicode.IsUpper(r) {
+			return false
+		}
+	}
+	return true
+}
+
+func l33tVariations(m *match) float64 {
+	if !m.l33t {
+		return 1
+	}
+
+	variations := 1.0
+	lower := normalize([]rune(m.token))
+	for subbed, unsubbed := range m.sub {
+		s, u := 0, 0
+		for _, r := range lower {
+			if r == subbed {
+				s++
+			}
+			if r == unsubbed {
+				u++
+			}
+		}
+
+		if s == 0 || u == 0 {
+			// Todas as ocorrências trocadas (ou nenhuma): só dobra o espaço.
+			variations *= 2
+			continue
+		}
+
+		var possibilities float64
+		for i := 1; i <= min(s, u); i++ {
+			possibilities += nCk(s+u, i)
+		}
+		variations *= possibilities
+	}
+	return variations
+}
+
+func scoreFromGuesses(guesses float64) int {
+	const delta = 5
+	switch {
+	case guesses < 1e3+delta:
+		return 0
+	case guesses < 1e6+delta:
+		return 1
+	case guesses < 1e8+delta:
+		return 2
+	case guesses < 1e10+delta:
+		return 3
+	default:
+		return 4
+	}
+}
+
+func nCk(n, k int) float64 {
+	if k > n {
+		return 0
+	}
+	if k == 0 {
+		return 1
+	}
+	result := 1.0
+	for d := 1; d <= k; d++ {
+		result *= float64(n)
+		result /= float64(d)
+		n--
+	}
+	return result
+}
+
+func factorial(n int) float64 {
+	result := 1.0
+	for i := 2; i <= n; i++ {
+		result *= float64(i)
+	}
+	return result
+}
+
+func sortedKeys[V any](m map[int]V) []int {
+	keys := make([]int, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Ints(keys)
+	return keys
+}
@@ -0,0 +1,68 @@
+// Package strength estima a força de senhas no estilo do zxcvbn: procura
+// padrões previsíveis (palavras de dicionário, sequências de teclado, datas,
+// repetições, substituições l33t) e calcula quantas tentativas um atacante
+// precisaria para chegar à senha seguindo esses padrões.
+package strength
+
+import (
+	"math"
+	"strings"
+	"unicode"
+)
+
+// MaxLength limita o trecho analisado; acima disso a senha já é forte e a
+// análise só ficaria mais cara.
+const MaxLength = 100
+
+// MinScore é a pontuação a partir da qual uma senha deixa de ser fraca.
+// Abaixo dela a avaliação traz aviso e sugestões.
+const MinScore = 3
+
+type Result struct {
+	// Pontuacao vai de 0 (trivial) a 4 (muito forte).
+	Pontuacao  int
+	Tentativas float64
+	Aviso      string
+	Sugestoes  []string
+}
+
+// Estimate avalia a senha. userInputs são dados do próprio usuário (nome,
+// e-mail, nome do item) que um atacante tentaria primeiro.
+func Estimate(password string, userInputs ...string) Result {
+	runes := []rune(password)
+	if len(runes) > MaxLength {
+		runes = runes[:MaxLength]
+	}
+
+	analysis := mostGuessableSequence(runes, omnimatch(runes, userInputDictionary(userInputs)))
+	score := scoreFromGuesses(analysis.guesses)
+	warning, suggestions := feedback(score, analysis.sequence)
+
+	return Result{
+		Pontuacao:  score,
+		Tentativas: math.Min(analysis.guesses, math.MaxFloat64),
+		Aviso:      warning,
+		Sugestoes:  suggestions,
+	}
+}
+
+// userInputDictionary quebra os dados do usuário em palavras, de modo que
+// "maria.silva@exemplo.com" gere "maria", "silva" e o e-mail inteiro.
+func userInputDictionary(inputs []string) map[string]int {
+	var words []string
+	for _, input := range inputs {
+		input = string(normalize([]rune(strings.TrimSpace(input))))
+		if input == "" {
+			continue
+		}
+		words = append(words, input)
+		words = append(words, strings.FieldsFunc(input, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		})...)
+	}
+
+	if len(words) == 0 {
+		return nil
+	}
+	return rankedDictionary(words)
+}
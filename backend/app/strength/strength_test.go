@@ -0,0 +1,229 @@
+package strength
+
+import (
+	"testing"
+)
+
+// sequence devolve os padrões escolhidos para cobrir a senha, sem dados do
+// usuário.
+func sequence(password string) []*match {
+	runes := []rune(password)
+	return mostGuessableSequence(runes, omnimatch(runes, nil)).sequence
+}
+
+func soleMatch(t *testing.T, password, pattern string) *match {
+	t.Helper()
+
+	seq := sequence(password)
+	if len(seq) != 1 || seq[0].pattern != pattern {
+		var patterns []string
+		for _, m := range seq {
+			patterns = append(patterns, m.pattern+"("+m.token+")")
+		}
+		t.Fatalf("%q: padrões %v, esperado um só %s", password, patterns, pattern)
+	}
+	return seq[0]
+}
+
+func TestDictionaryMatch(t *testing.T) {
+	tests := []struct {
+		password, dict, word string
+		reversed             bool
+	}{
+		{"password", "passwords", "password", false},
+		{"PASSWORD", "passwords", "password", false},
+		{"drowssap", "passwords", "password", true},
+		{"coração", "portuguese", "coracao", false},
+	}
+
+	for _, tt := range tests {
+		m := soleMatch(t, tt.password, patternDictionary)
+		if m.dict != tt.dict || m.word != tt.word || m.reversed != tt.reversed || m.l33t {
+			t.Errorf("%q: dicionário %s, palavra %s, invertida %v, l33t %v", tt.password, m.dict, m.word, m.reversed, m.l33t)
+		}
+	}
+
+	// Palavras de dicionário em sequência continuam sendo encontradas.
+	seq := sequence("correcthorsebatterystaple")
+	if len(seq) != 4 {
+		t.Fatalf("correcthorsebatterystaple: %d padrões", len(seq))
+	}
+	for _, m := range seq {
+		if m.pattern != patternDictionary {
+			t.Errorf("correcthorsebatterystaple: %s em %q", m.pattern, m.token)
+		}
+	}
+}
+
+func TestL33tMatch(t *testing.T) {
+	tests := []struct {
+		password string
+		sub      map[rune]rune
+	}{
+		{"P@ssw0rd", map[rune]rune{'@': 'a', '0': 'o'}},
+		{"p4ssw0rd", map[rune]rune{'4': 'a', '0': 'o'}},
+		{"$enh@", map[rune]rune{'$': 's', '@': 'a'}},
+	}
+
+	for _, tt := range tests {
+		m := soleMatch(t, tt.password, patternDictionary)
+		if !m.l33t || len(m.sub) != len(tt.sub) {
+			t.Fatalf("%q: l33t %v, substituições %q", tt.password, m.l33t, m.sub)
+		}
+		for from, to := range tt.sub {
+			if m.sub[from] != to {
+				t.Errorf("%q: %q lido como %q, esperado %q", tt.password, from, m.sub[from], to)
+			}
+		}
+	}
+
+	// Sem substituição não há l33t, e um caractere sozinho não é palavra.
+	for _, m := range l33tMatch([]rune("password"), dictionaries()) {
+		t.Errorf("password: l33t em %q", m.token)
+	}
+	for _, m := range l33tMatch([]rune("@"), dictionaries()) {
+		t.Errorf("@: l33t em %q", m.token)
+	}
+}
+
+func TestSequenceMatch(t *testing.T) {
+	tests := []struct {
+		password  string
+		ascending bool
+	}{
+		{"abcdefg", true},
+		{"13579", true},
+		{"987654", false},
+		{"zyxwv", false},
+	}
+
+	for _, tt := range tests {
+		if m := soleMatch(t, tt.password, patternSequence); m.ascending != tt.ascending {
+			t.Errorf("%q: crescente %v", tt.password, m.ascending)
+		}
+	}
+
+	// Saltos maiores que maxDelta não formam sequência.
+	if matches := sequenceMatch([]rune("agmsy")); len(matches) != 0 {
+		t.Errorf("agmsy: %d sequências", len(matches))
+	}
+
+	seq := sequence("senha123")
+	if last := seq[len(seq)-1]; last.pattern != patternSequence || last.token != "123" {
+		t.Errorf("senha123: último padrão %s(%s)", last.pattern, last.token)
+	}
+}
+
+func TestRepeatMatch(t *testing.T) {
+	tests := []struct {
+		password, base string
+		repeats        int
+	}{
+		{"aaaaaaaa", "a", 8},
+		{"abcabcabc", "abc", 3},
+		{"senhasenha", "senha", 2},
+	}
+
+	for _, tt := range tests {
+		m := soleMatch(t, tt.password, patternRepeat)
+		if m.base != tt.base || m.repeats != tt.repeats {
+			t.Errorf("%q: base %q repetida %d vezes", tt.password, m.base, m.repeats)
+		}
+	}
+}
+
+func TestDateMatch(t *testing.T) {
+	tests := []struct {
+		password, separator string
+		year                int
+	}{
+		{"13/05/1990", "/", 1990},
+		{"1990-05-13", "-", 1990},
+		{"13.5.90", ".", 1990},
+		{"13051990", "", 1990},
+		{"130507", "", 2007},
+	}
+
+	for _, tt := range tests {
+		m := soleMatch(t, tt.password, patternDate)
+		if m.year != tt.year || m.separator != tt.separator {
+			t.Errorf("%q: ano %d, separador %q", tt.password, m.year, m.separator)
+		}
+	}
+
+	if m := soleMatch(t, "2019", patternYear); m.year != 2019 {
+		t.Errorf("2019: ano %d", m.year)
+	}
+
+	// Dia e mês impossíveis e separadores diferentes não formam uma data
+	// inteira, ainda que parte dos dígitos forme.
+	for _, password := range []string{"45/45/1990", "13/05-1990", "99999999"} {
+		for _, m := range dateMatch([]rune(password)) {
+			if m.token == password {
+				t.Errorf("%q lido como data", password)
+			}
+		}
+	}
+}
+
+func TestScoreFromGuesses(t *testing.T) {
+	tests := []struct {
+		guesses float64
+		score   int
+	}{
+		{1, 0},
+		{1e3 + 4, 0},
+		{1e3 + 5, 1},
+		{1e6 + 4, 1},
+		{1e6 + 5, 2},
+		{1e8 + 4, 2},
+		{1e8 + 5, 3},
+		{1e10 + 4, 3},
+		{1e10 + 5, 4},
+		{1e20, 4},
+	}
+
+	for _, tt := range tests {
+		if score := scoreFromGuesses(tt.guesses); score != tt.score {
+			t.Errorf("%g tentativas: pontuação %d, esperado %d", tt.guesses, score, tt.score)
+		}
+	}
+}
+
+func TestEstimate(t *testing.T) {
+	tests := []struct {
+		password string
+		score    int
+	}{
+		{"", 0},
+		{"password", 0},
+		{"P@ssw0rd", 0},
+		{"qwerty", 0},
+		{"aaaaaaaa", 0},
+		{"senha123", 1},
+		{"13/05/1990", 1},
+		{"coracaoverdeluz", 3},
+		{"correcthorsebatterystaple", 4},
+		{"x7#Kp!2vQz@9Lm", 4},
+	}
+
+	for _, tt := range tests {
+		result := Estimate(tt.password)
+		if result.Pontuacao != tt.score {
+			t.Errorf("%q: pontuação %d, esperado %d", tt.password, result.Pontuacao, tt.score)
+		}
+
+		// Aviso e sugestões só aparecem abaixo de MinScore.
+		weak := result.Aviso != "" || len(result.Sugestoes) > 0
+		if tt.password != "" && weak != (result.Pontuacao < MinScore) {
+			t.Errorf("%q: pontuação %d com aviso %q e sugestões %v", tt.password, result.Pontuacao, result.Aviso, result.Sugestoes)
+		}
+	}
+
+	// Dados do próprio usuário contam como palavras conhecidas.
+	without := Estimate("silvaexemplo2024")
+	with := Estimate("silvaexemplo2024", "Maria Silva", "maria@exemplo.com")
+	if with.Tentativas >= without.Tentativas {
+		t.Errorf("dados do usuário não reduziram as tentativas: %g, sem eles %g", with.Tentativas, without.Tentativas)
+	}
+}
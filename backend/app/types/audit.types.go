@@ -0,0 +1,21 @@
+package types
+
+import "time"
+
+const (
+	AuditLogin           = "login"
+	AuditLoginFailed     = "login_falhou"
+	AuditPasswordChanged = "senha_alterada"
+	AuditPasswordReset   = "senha_redefinida"
+	AuditExportRequested = "exportacao_solicitada"
+)
+
+type AuditEvent struct {
+	ID        uint      `json:"id" gorm:"primarykey"`
+	UserID    uint      `json:"userId" gorm:"index"`
+	Acao      string    `json:"acao"`
+	IP        string    `json:"ip"`
+	UserAgent string    `json:"userAgent"`
+	Detalhes  string    `json:"detalhes,omitempty"`
+	CreatedAt time.Time `json:"criadoEm"`
+}
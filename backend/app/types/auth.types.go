@@ -55,11 +55,13 @@ func (d *Date) Scan(value interface{}) error {
 
 type User struct {
 	gorm.Model
-	Nome           string `json:"nome" binding:"required"`
-	DataNascimento Date   `json:"dataNascimento" binding:"required"`
-	Email          string `json:"email" binding:"required,email" gorm:"unique"`
-	Senha          string `json:"senha" binding:"required" gorm:"-"`
-	SenhaHash      string `json:"-"`
+	Nome            string `json:"nome" binding:"required"`
+	DataNascimento  Date   `json:"dataNascimento" binding:"required"`
+	Email           string `json:"email" binding:"required,email" gorm:"unique"`
+	Senha           string `json:"senha" binding:"required" gorm:"-"`
+	SenhaHash       string `json:"-"`
+	EmailVerificado bool   `json:"emailVerificado" gorm:"not null;default:false"`
+	EmailPendente   string `json:"emailPendente"`
 }
 
 type SignupRequest struct {
@@ -71,11 +73,16 @@ type SignupRequest struct {
 }
 
 type LoginRequest struct {
-	Email string `json:"email" binding:"required,email"`
-	Senha string `json:"senha" binding:"required"`
+	Email       string      `json:"email" binding:"required,email"`
+	Senha       string      `json:"senha" binding:"required"`
+	Dispositivo string      `json:"dispositivo"`
+	Sessao      SessionInfo `json:"-"`
 }
 
 type AuthResponse struct {
-	Token string `json:"token"`
-	User  User   `json:"user"`
+	Token          string `json:"token"`
+	RefreshToken   string `json:"refreshToken"`
+	MFAObrigatorio bool   `json:"mfaObrigatorio"`
+	MFAToken       string `json:"mfaToken"`
+	User           User   `json:"user"`
 }
@@ -0,0 +1,59 @@
+package types
+
+import "time"
+
+const (
+	BackupVersion = 1
+
+	RestoreMerge   = "merge"
+	RestoreReplace = "replace"
+)
+
+// VaultBackup é o conteúdo (antes de cifrado) da cópia de segurança do cofre.
+// Itens do modo zero-knowledge são guardados como o blob opaco do cliente.
+// Pastas traz o caminho de todas as pastas, inclusive as vazias.
+type VaultBackup struct {
+	Versao        int          `json:"versao"`
+	GeradoEm      time.Time    `json:"geradoEm"`
+	ZeroKnowledge bool         `json:"zeroKnowledge"`
+	Pastas        []string     `json:"pastas,omitempty"`
+	Tags          []string     `json:"tags,omitempty"`
+	Itens         []BackupItem `json:"itens"`
+}
+
+type BackupItem struct {
+	Tipo         string          `json:"tipo,omitempty"`
+	Nome         string          `json:"nome,omitempty"`
+	Usuario      string          `json:"usuario,omitempty"`
+	URL          string          `json:"url,omitempty"`
+	Senha        string          `json:"senha,omitempty"`
+	Notas        string          `json:"notas,omitempty"`
+	Dados        *ItemData       `json:"dados,omitempty"`
+	Blob         string          `json:"blob,omitempty"`
+	Pasta        string          `json:"pasta,omitempty"`
+	Tags         []string        `json:"tags,omitempty"`
+	Revisao      uint            `json:"revisao"`
+	CriadoEm     time.Time       `json:"criadoEm"`
+	AtualizadoEm time.Time       `json:"atualizadoEm"`
+	ExcluidoEm   *time.Time      `json:"excluidoEm,omitempty"`
+	Historico    []BackupHistory `json:"historico,omitempty"`
+}
+
+type BackupHistory struct {
+	Versao   uint      `json:"versao"`
+	Senha    string    `json:"senha,omitempty"`
+	Blob     string    `json:"blob,omitempty"`
+	CriadoEm time.Time `json:"criadoEm"`
+}
+
+type BackupExportRequest struct {
+	Senha string `json:"senha"`
+}
+
+type RestoreResult struct {
+	Modo        string        `json:"modo"`
+	Total       int           `json:"total"`
+	Restaurados int           `json:"restaurados"`
+	Removidos   int           `json:"removidos"`
+	Ignorados   []ImportIssue `json:"ignorados"`
+}
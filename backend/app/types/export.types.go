@@ -0,0 +1,102 @@
+package types
+
+import "time"
+
+const (
+	ExportPending = "pendente"
+	ExportRunning = "processando"
+	ExportDone    = "concluido"
+	ExportFailed  = "erro"
+
+	ExportFormatJSON = "json"
+	ExportFormatZIP  = "zip"
+)
+
+type ExportJob struct {
+	ID            uint       `json:"id" gorm:"primarykey"`
+	UserID        uint       `json:"userId" gorm:"index"`
+	Formato       string     `json:"formato"`
+	IncluirSenhas bool       `json:"incluirSenhas"`
+	Status        string     `json:"status" gorm:"index"`
+	Erro          string     `json:"erro"`
+	Conteudo      []byte     `json:"-"`
+	ExpiraEm      *time.Time `json:"expiraEm"`
+	ConcluidoEm   *time.Time `json:"concluidoEm"`
+	CreatedAt     time.Time  `json:"criadoEm"`
+}
+
+type ExportRequest struct {
+	Formato       string      `json:"formato"`
+	IncluirSenhas bool        `json:"incluirSenhas"`
+	Senha         string      `json:"senha"`
+	UserID        uint        `json:"-"`
+	Sessao        SessionInfo `json:"-"`
+}
+
+type ExportJobResponse struct {
+	ID            uint       `json:"id"`
+	Formato       string     `json:"formato"`
+	IncluirSenhas bool       `json:"incluirSenhas"`
+	Status        string     `json:"status"`
+	Erro          string     `json:"erro,omitempty"`
+	CriadoEm      time.Time  `json:"criadoEm"`
+	ConcluidoEm   *time.Time `json:"concluidoEm,omitempty"`
+	ExpiraEm      *time.Time `json:"expiraEm,omitempty"`
+	Download      string     `json:"download,omitempty"`
+}
+
+type ExportDocument struct {
+	Versao    int             `json:"versao"`
+	GeradoEm  time.Time       `json:"geradoEm"`
+	Usuario   ProfileResponse `json:"usuario"`
+	Pastas    []Folder        `json:"pastas"`
+	Tags      []Tag           `json:"tags"`
+	Itens     []ExportItem    `json:"itens"`
+	Historico []ExportHistory `json:"historico"`
+	Sessoes   []ExportSession `json:"sessoes"`
+	Eventos   []AuditEvent    `json:"eventos"`
+}
+
+// Com incluirSenhas as senhas saem decifradas em Senha; caso contrário vão
+// como estão armazenadas (SenhaCifrada, em base64). O mesmo vale para as notas
+// e para os dados próprios do tipo do item. Itens zero-knowledge sempre vão
+// como Blob, pois o servidor não consegue decifrá-los.
+type ExportItem struct {
+	ID            uint       `json:"id"`
+	Tipo          string     `json:"tipo,omitempty"`
+	Nome          string     `json:"nome,omitempty"`
+	Usuario       string     `json:"usuario,omitempty"`
+	URL           string     `json:"url,omitempty"`
+	Senha         string     `json:"senha,omitempty"`
+	SenhaCifrada  []byte     `json:"senhaCifrada,omitempty"`
+	Notas         string     `json:"notas,omitempty"`
+	NotasCifradas []byte     `json:"notasCifradas,omitempty"`
+	Dados         *ItemData  `json:"dados,omitempty"`
+	DadosCifrados []byte     `json:"dadosCifrados,omitempty"`
+	Blob          string     `json:"blob,omitempty"`
+	PastaID       *uint      `json:"pastaId,omitempty"`
+	Tags          []string   `json:"tags,omitempty"`
+	Revisao       uint       `json:"revisao"`
+	CriadoEm      time.Time  `json:"criadoEm"`
+	AtualizadoEm  time.Time  `json:"atualizadoEm"`
+	ExcluidoEm    *time.Time `json:"excluidoEm,omitempty"`
+}
+
+type ExportHistory struct {
+	ItemID       uint      `json:"itemId"`
+	Versao       uint      `json:"versao"`
+	Senha        string    `json:"senha,omitempty"`
+	SenhaCifrada []byte    `json:"senhaCifrada,omitempty"`
+	Blob         string    `json:"blob,omitempty"`
+	CriadoEm     time.Time `json:"criadoEm"`
+}
+
+type ExportSession struct {
+	ID           uint       `json:"id"`
+	Dispositivo  string     `json:"dispositivo"`
+	UserAgent    string     `json:"userAgent"`
+	IP           string     `json:"ip"`
+	CriadoEm     time.Time  `json:"criadoEm"`
+	UltimoAcesso time.Time  `json:"ultimoAcesso"`
+	RevogadaEm   *time.Time `json:"revogadaEm,omitempty"`
+}
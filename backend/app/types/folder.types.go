@@ -0,0 +1,62 @@
+package types
+
+import "time"
+
+const (
+	FolderDeleteMove    = "mover"
+	FolderDeleteCascade = "cascata"
+)
+
+// Folder é uma pasta do cofre. Pastas sem ParentID ficam na raiz; cada item
+// pertence a no máximo uma pasta.
+type Folder struct {
+	ID        uint      `json:"id" gorm:"primarykey"`
+	Nome      string    `json:"nome" gorm:"not null"`
+	ParentID  *uint     `json:"parentId" gorm:"index"`
+	UserID    uint      `json:"userId" gorm:"index"`
+	CreatedAt time.Time `json:"criadoEm"`
+	UpdatedAt time.Time `json:"atualizadoEm"`
+}
+
+// Tag é uma etiqueta livre; um item pode ter várias e o nome é único por
+// usuário.
+type Tag struct {
+	ID        uint      `json:"id" gorm:"primarykey"`
+	Nome      string    `json:"nome" gorm:"not null;index:idx_tags_user_nome,unique"`
+	UserID    uint      `json:"userId" gorm:"index:idx_tags_user_nome,unique"`
+	CreatedAt time.Time `json:"criadoEm"`
+}
+
+type FolderRequest struct {
+	Nome     *string `json:"nome"`
+	ParentID *uint   `json:"parentId"`
+	ID       uint    `json:"-"`
+	UserID   uint    `json:"-"`
+}
+
+type FolderResponse struct {
+	ID       uint   `json:"id"`
+	Nome     string `json:"nome"`
+	ParentID *uint  `json:"parentId,omitempty"`
+	Caminho  string `json:"caminho"`
+	Itens    int64  `json:"itens"`
+}
+
+type FolderDeleteResult struct {
+	Modo            string `json:"modo"`
+	PastasRemovidas int    `json:"pastasRemovidas"`
+	ItensMovidos    int64  `json:"itensMovidos"`
+	ItensExcluidos  int64  `json:"itensExcluidos"`
+}
+
+type TagRequest struct {
+	Nome   string `json:"nome"`
+	ID     uint   `json:"-"`
+	UserID uint   `json:"-"`
+}
+
+type TagResponse struct {
+	ID    uint   `json:"id"`
+	Nome  string `json:"nome"`
+	Itens int64  `json:"itens"`
+}
@@ -0,0 +1,29 @@
+package types
+
+// GenerateRequest descreve a senha a ser gerada. Campos omitidos usam os
+// padrões do modo: todas as classes de caracteres ativas, 20 caracteres no
+// modo aleatório, 12 no pronunciável e 6 palavras separadas por "-" no
+// diceware.
+type GenerateRequest struct {
+	Modo            string  `json:"modo"`
+	Tamanho         int     `json:"tamanho"`
+	Minusculas      *bool   `json:"minusculas"`
+	Maiusculas      *bool   `json:"maiusculas"`
+	Numeros         *bool   `json:"numeros"`
+	Simbolos        *bool   `json:"simbolos"`
+	ExcluirAmbiguos bool    `json:"excluirAmbiguos"`
+	MinMinusculas   int     `json:"minMinusculas"`
+	MinMaiusculas   int     `json:"minMaiusculas"`
+	MinNumeros      int     `json:"minNumeros"`
+	MinSimbolos     int     `json:"minSimbolos"`
+	Palavras        int     `json:"palavras"`
+	Separador       *string `json:"separador"`
+	Capitalizar     bool    `json:"capitalizar"`
+	IncluirNumero   bool    `json:"incluirNumero"`
+}
+
+type GenerateResponse struct {
+	Senha    string  `json:"senha"`
+	Entropia float64 `json:"entropia"`
+	Modo     string  `json:"modo"`
+}
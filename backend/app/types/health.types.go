@@ -0,0 +1,28 @@
+package types
+
+import "time"
+
+type HealthReport struct {
+	Pontuacao       int                   `json:"pontuacao"`
+	Total           int                   `json:"total"`
+	Reutilizadas    []ReusedPasswordGroup `json:"reutilizadas"`
+	Fracas          []HealthItem          `json:"fracas"`
+	Antigas         []HealthItem          `json:"antigas"`
+	Vazadas         []HealthItem          `json:"vazadas"`
+	VerificaVazadas bool                  `json:"verificaVazadas"`
+	DiasAntigas     int                   `json:"diasAntigas"`
+	GeradoEm        time.Time             `json:"geradoEm"`
+}
+
+type ReusedPasswordGroup struct {
+	Itens []HealthItem `json:"itens"`
+}
+
+type HealthItem struct {
+	ID           uint      `json:"id"`
+	Nome         string    `json:"nome"`
+	Usuario      string    `json:"usuario,omitempty"`
+	URL          string    `json:"url,omitempty"`
+	Forca        *int      `json:"forca,omitempty"`
+	AtualizadoEm time.Time `json:"atualizadoEm"`
+}
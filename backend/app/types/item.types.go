@@ -1,26 +1,218 @@
 package types
 
 import (
+	"time"
+
 	"gorm.io/gorm"
 )
 
 type Item struct {
 	gorm.Model
-	Nome   string `json:"nome" binding:"required"`
-	Senha  string `json:"senha" binding:"required"`
-	UserID uint   `json:"userId" binding:"required"`
-	User   User   `json:"user" gorm:"foreignKey:UserID"`
+	Nome          string `json:"nome" binding:"required"`
+	Usuario       string `json:"usuario"`
+	URL           string `json:"url"`
+	Senha         string `json:"senha" binding:"required" gorm:"-"`
+	SenhaCifrada  []byte `json:"-"`
+	SenhaHMAC     []byte `json:"-" gorm:"index"`
+	Notas         string `json:"notas" gorm:"-"`
+	NotasCifradas []byte `json:"-"`
+	Blob          []byte `json:"-"`
+	Tipo          string `json:"tipo" gorm:"not null;default:login"`
+	DadosCifrados []byte `json:"-"`
+	Revisao       uint   `json:"revisao" gorm:"not null;default:1"`
+	ForcaSenha    *int   `json:"-"`
+	SenhaVazada   *bool  `json:"-"`
+	PastaID       *uint  `json:"pastaId" gorm:"index"`
+	Tags          []Tag  `json:"-" gorm:"many2many:item_tags"`
+	UserID        uint   `json:"userId" binding:"required"`
+	User          User   `json:"user" gorm:"foreignKey:UserID"`
+}
+
+type ItemHistory struct {
+	ID           uint      `json:"id" gorm:"primarykey"`
+	ItemID       uint      `json:"itemId" gorm:"index:idx_item_history_versao,unique"`
+	Versao       uint      `json:"versao" gorm:"index:idx_item_history_versao,unique"`
+	UserID       uint      `json:"userId" gorm:"index"`
+	SenhaCifrada []byte    `json:"-"`
+	Blob         []byte    `json:"-"`
+	CreatedAt    time.Time `json:"criadoEm"`
+}
+
+type LegacyItem struct {
+	ID     uint
+	UserID uint
+	Senha  string
 }
 
 type CreateItemRequest struct {
-	Nome   string `json:"nome" binding:"required"`
-	Senha  string `json:"senha" binding:"required"`
-	UserID uint   `json:"-"`
+	Tipo       string           `json:"tipo"`
+	Nome       string           `json:"nome" binding:"required"`
+	Usuario    string           `json:"usuario"`
+	URL        string           `json:"url"`
+	URLs       []string         `json:"urls"`
+	Senha      string           `json:"senha"`
+	TOTP       string           `json:"totp"`
+	Notas      string           `json:"notas"`
+	Cartao     *CardData        `json:"cartao"`
+	Identidade *IdentityData    `json:"identidade"`
+	WiFi       *WiFiData        `json:"wifi"`
+	Campos     []CustomField    `json:"campos"`
+	Blob       string           `json:"blob"`
+	Generate   *GenerateRequest `json:"generate"`
+	PastaID    *uint            `json:"pastaId"`
+	Tags       []string         `json:"tags"`
+	UserID     uint             `json:"-"`
+}
+
+type UpdateItemRequest struct {
+	Tipo        *string        `json:"tipo"`
+	Nome        *string        `json:"nome"`
+	Usuario     *string        `json:"usuario"`
+	URL         *string        `json:"url"`
+	URLs        *[]string      `json:"urls"`
+	Senha       *string        `json:"senha"`
+	TOTP        *string        `json:"totp"`
+	RemoverTOTP bool           `json:"removerTotp"`
+	Notas       *string        `json:"notas"`
+	Cartao      *CardData      `json:"cartao"`
+	Identidade  *IdentityData  `json:"identidade"`
+	WiFi        *WiFiData      `json:"wifi"`
+	Campos      *[]CustomField `json:"campos"`
+	Blob        *string        `json:"blob"`
+	PastaID     *uint          `json:"pastaId"`
+	Tags        *[]string      `json:"tags"`
+	Revisao     uint           `json:"revisao"`
+	ID          uint           `json:"-"`
+	UserID      uint           `json:"-"`
+	Parcial     bool           `json:"-"`
 }
 
 type ItemResponse struct {
-	ID     uint   `json:"id"`
-	Nome   string `json:"nome"`
-	Senha  string `json:"senha"`
-	UserID uint   `json:"userId"`
+	ID         uint              `json:"id"`
+	Tipo       string            `json:"tipo,omitempty"`
+	Nome       string            `json:"nome"`
+	Usuario    string            `json:"usuario,omitempty"`
+	URL        string            `json:"url,omitempty"`
+	URLs       []string          `json:"urls,omitempty"`
+	Senha      string            `json:"senha,omitempty"`
+	TOTP       string            `json:"totp,omitempty"`
+	TemTOTP    bool              `json:"temTotp,omitempty"`
+	Notas      string            `json:"notas,omitempty"`
+	Cartao     *CardData         `json:"cartao,omitempty"`
+	Identidade *IdentityData     `json:"identidade,omitempty"`
+	WiFi       *WiFiData         `json:"wifi,omitempty"`
+	Campos     []CustomField     `json:"campos,omitempty"`
+	Blob       string            `json:"blob,omitempty"`
+	PastaID    *uint             `json:"pastaId,omitempty"`
+	Tags       []string          `json:"tags,omitempty"`
+	Forca      *PasswordStrength `json:"forca,omitempty"`
+	Vazada     *bool             `json:"vazada,omitempty"`
+	Revisao    uint              `json:"revisao"`
+	UserID     uint              `json:"userId"`
+}
+
+const (
+	ItemSortName    = "nome"
+	ItemSortCreated = "criado"
+	ItemSortUpdated = "atualizado"
+)
+
+// Campos em que a busca da listagem pode procurar.
+const (
+	ItemSearchName = "nome"
+	ItemSearchURL  = "url"
+)
+
+// ItemFilter restringe a listagem de itens. PastaID 0 seleciona os itens fora
+// de pastas; com Subpastas entram também os itens das pastas descendentes.
+// Com várias tags, o item precisa ter todas. Tipo restringe a um tipo de
+// item. Busca procura o texto nos Campos escolhidos, por padrão no nome e na
+// URL, em qualquer posição ou, com Prefixo, só no início. As notas ficam
+// criptografadas e não podem ser buscadas no banco. Limite 0 devolve
+// todos os itens; Apos continua a listagem depois do último item da página
+// anterior.
+type ItemFilter struct {
+	PastaID     *uint
+	Subpastas   bool
+	Tags        []string
+	Tipo        string
+	Busca       string
+	Campos      []string
+	Prefixo     bool
+	Ordem       string
+	Decrescente bool
+	Limite      int
+	Cursor      string
+	Apos        *ItemCursor
+}
+
+// ItemCursor é a posição do último item de uma página, na ordem da listagem.
+// Vai para o cliente codificado em base64 e volta intacto no próximo pedido.
+type ItemCursor struct {
+	Ordem       string    `json:"o"`
+	Decrescente bool      `json:"d,omitempty"`
+	Nome        string    `json:"n,omitempty"`
+	Data        time.Time `json:"t"`
+	ID          uint      `json:"id"`
+}
+
+type ItemPage struct {
+	Itens  []ItemResponse
+	Total  int64
+	Cursor string
+}
+
+type PasswordStrength struct {
+	Pontuacao int      `json:"pontuacao"`
+	Aviso     string   `json:"aviso,omitempty"`
+	Sugestoes []string `json:"sugestoes,omitempty"`
+}
+
+// ItemTOTPResponse é o código atual do autenticador de um item. Restante é o
+// tempo de vida do código em segundos; códigos HOTP não expiram e trazem o
+// Contador usado.
+type ItemTOTPResponse struct {
+	Codigo   string  `json:"codigo"`
+	Tipo     string  `json:"tipo"`
+	Digitos  int     `json:"digitos"`
+	Periodo  int     `json:"periodo,omitempty"`
+	Restante int     `json:"restante,omitempty"`
+	Contador *uint64 `json:"contador,omitempty"`
+	Revisao  uint    `json:"revisao,omitempty"`
+}
+
+type ItemHistoryResponse struct {
+	Versao   uint      `json:"versao"`
+	Senha    string    `json:"senha,omitempty"`
+	Blob     string    `json:"blob,omitempty"`
+	CriadoEm time.Time `json:"criadoEm"`
+}
+
+type TrashItemResponse struct {
+	ItemResponse
+	ExcluidoEm time.Time `json:"excluidoEm"`
+}
+
+type ImportIssue struct {
+	Linha  int    `json:"linha"`
+	Nome   string `json:"nome,omitempty"`
+	Motivo string `json:"motivo"`
+}
+
+type ImportResult struct {
+	Formato    string        `json:"formato"`
+	DryRun     bool          `json:"dryRun"`
+	Total      int           `json:"total"`
+	Importados int           `json:"importados"`
+	Duplicados []ImportIssue `json:"duplicados"`
+	Erros      []ImportIssue `json:"erros"`
+	Avisos     []ImportIssue `json:"avisos"`
+}
+
+type KDBXExportRequest struct {
+	Senha            string `json:"senha"`
+	ConfirmacaoSenha string `json:"confirmacaoSenha"`
+	Cifra            string `json:"cifra"`
+	KDF              string `json:"kdf"`
+	UserID           uint   `json:"-"`
 }
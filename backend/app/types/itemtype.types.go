@@ -0,0 +1,76 @@
+package types
+
+const (
+	ItemTypeLogin    = "login"
+	ItemTypeCard     = "cartao"
+	ItemTypeIdentity = "identidade"
+	ItemTypeNote     = "nota"
+	ItemTypeWiFi     = "wifi"
+)
+
+const (
+	WiFiSecurityWPA  = "wpa"
+	WiFiSecurityWPA2 = "wpa2"
+	WiFiSecurityWPA3 = "wpa3"
+	WiFiSecurityWEP  = "wep"
+	WiFiSecurityOpen = "aberta"
+)
+
+const (
+	CustomFieldText    = "texto"
+	CustomFieldHidden  = "oculto"
+	CustomFieldBoolean = "booleano"
+	CustomFieldURL     = "url"
+	CustomFieldDate    = "data"
+)
+
+// ItemData reúne os campos próprios de cada tipo de item e os campos
+// personalizados, que valem para todos os tipos. É gravado como JSON
+// cifrado em Item.DadosCifrados; nome, usuário, URL, senha e notas continuam
+// nas colunas de sempre, o que mantém os itens antigos como logins.
+type ItemData struct {
+	URLs       []string      `json:"urls,omitempty"`
+	TOTP       string        `json:"totp,omitempty"`
+	Cartao     *CardData     `json:"cartao,omitempty"`
+	Identidade *IdentityData `json:"identidade,omitempty"`
+	WiFi       *WiFiData     `json:"wifi,omitempty"`
+	Campos     []CustomField `json:"campos,omitempty"`
+}
+
+// CardData é um cartão de pagamento. Validade vai no formato MM/AAAA; a senha
+// do cartão, quando existe, fica em Senha como nos demais itens.
+type CardData struct {
+	Titular  string `json:"titular,omitempty"`
+	Numero   string `json:"numero"`
+	Validade string `json:"validade,omitempty"`
+	CVV      string `json:"cvv,omitempty"`
+}
+
+// IdentityData guarda documentos e dados pessoais. DataNascimento vai no
+// formato AAAA-MM-DD.
+type IdentityData struct {
+	NomeCompleto   string `json:"nomeCompleto,omitempty"`
+	CPF            string `json:"cpf,omitempty"`
+	RG             string `json:"rg,omitempty"`
+	DataNascimento string `json:"dataNascimento,omitempty"`
+	Email          string `json:"email,omitempty"`
+	Telefone       string `json:"telefone,omitempty"`
+	Endereco       string `json:"endereco,omitempty"`
+}
+
+// WiFiData é uma rede sem fio; a senha da rede fica em Senha.
+type WiFiData struct {
+	SSID      string `json:"ssid"`
+	Seguranca string `json:"seguranca"`
+	Oculta    bool   `json:"oculta,omitempty"`
+}
+
+// CustomField é um campo extra do item, como uma pergunta de segurança ou um
+// número de conta. Os campos mantêm a ordem em que foram enviados. Na
+// listagem, os campos ocultos vêm sem Valor e com Mascarado.
+type CustomField struct {
+	Nome      string `json:"nome"`
+	Tipo      string `json:"tipo"`
+	Valor     string `json:"valor"`
+	Mascarado bool   `json:"mascarado,omitempty"`
+}
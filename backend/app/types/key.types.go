@@ -0,0 +1,12 @@
+package types
+
+import (
+	"gorm.io/gorm"
+)
+
+type UserKey struct {
+	gorm.Model
+	UserID       uint   `json:"userId" gorm:"uniqueIndex"`
+	ChaveCifrada []byte `json:"-"`
+	User         User   `json:"-" gorm:"foreignKey:UserID"`
+}
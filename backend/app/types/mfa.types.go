@@ -0,0 +1,58 @@
+package types
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type MFAConfig struct {
+	gorm.Model
+	UserID         uint   `json:"userId" gorm:"uniqueIndex"`
+	SegredoCifrado []byte `json:"-"`
+	Ativo          bool   `json:"ativo"`
+	UltimoPasso    int64  `json:"-"`
+	User           User   `json:"-" gorm:"foreignKey:UserID"`
+}
+
+type RecoveryCode struct {
+	ID         uint       `json:"id" gorm:"primarykey"`
+	UserID     uint       `json:"userId" gorm:"index"`
+	CodigoHash string     `json:"-" gorm:"uniqueIndex"`
+	UsadoEm    *time.Time `json:"usadoEm"`
+	CreatedAt  time.Time  `json:"criadoEm"`
+}
+
+type MFASetupResponse struct {
+	Segredo string `json:"segredo"`
+	URI     string `json:"uri"`
+}
+
+type MFACodeRequest struct {
+	Codigo string `json:"codigo" binding:"required"`
+	UserID uint   `json:"-"`
+}
+
+type MFADisableRequest struct {
+	Senha             string `json:"senha" binding:"required"`
+	Codigo            string `json:"codigo"`
+	CodigoRecuperacao string `json:"codigoRecuperacao"`
+	UserID            uint   `json:"-"`
+}
+
+type MFAVerifyRequest struct {
+	MFAToken          string      `json:"mfaToken" binding:"required"`
+	Codigo            string      `json:"codigo"`
+	CodigoRecuperacao string      `json:"codigoRecuperacao"`
+	Dispositivo       string      `json:"dispositivo"`
+	Sessao            SessionInfo `json:"-"`
+}
+
+type RecoveryCodesResponse struct {
+	CodigosRecuperacao []string `json:"codigosRecuperacao"`
+}
+
+type MFAStatusResponse struct {
+	Ativo            bool  `json:"ativo"`
+	CodigosRestantes int64 `json:"codigosRestantes"`
+}
@@ -0,0 +1,32 @@
+package types
+
+import "time"
+
+type PasswordReset struct {
+	ID        uint       `json:"id" gorm:"primarykey"`
+	UserID    uint       `json:"userId" gorm:"index"`
+	TokenHash string     `json:"-" gorm:"uniqueIndex"`
+	ExpiraEm  time.Time  `json:"expiraEm"`
+	UsadoEm   *time.Time `json:"usadoEm"`
+	CreatedAt time.Time  `json:"criadoEm"`
+}
+
+type ChangePasswordRequest struct {
+	SenhaAtual       string      `json:"senhaAtual" binding:"required"`
+	NovaSenha        string      `json:"novaSenha" binding:"required,min=6"`
+	ConfirmacaoSenha string      `json:"confirmacaoSenha" binding:"required"`
+	UserID           uint        `json:"-"`
+	SessionID        uint        `json:"-"`
+	Sessao           SessionInfo `json:"-"`
+}
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+type ResetPasswordRequest struct {
+	Token            string      `json:"token" binding:"required"`
+	NovaSenha        string      `json:"novaSenha" binding:"required,min=6"`
+	ConfirmacaoSenha string      `json:"confirmacaoSenha" binding:"required"`
+	Sessao           SessionInfo `json:"-"`
+}
@@ -0,0 +1,38 @@
+package types
+
+import "time"
+
+type ProfileResponse struct {
+	ID              uint      `json:"id"`
+	Nome            string    `json:"nome"`
+	DataNascimento  Date      `json:"dataNascimento"`
+	Email           string    `json:"email"`
+	EmailVerificado bool      `json:"emailVerificado"`
+	EmailPendente   string    `json:"emailPendente,omitempty"`
+	CriadoEm        time.Time `json:"criadoEm"`
+}
+
+type UpdateProfileRequest struct {
+	Nome           *string `json:"nome"`
+	DataNascimento *Date   `json:"dataNascimento"`
+	Email          *string `json:"email"`
+	Senha          string  `json:"senha"`
+	UserID         uint    `json:"-"`
+}
+
+type DeleteAccountRequest struct {
+	Senha  string `json:"senha" binding:"required"`
+	UserID uint   `json:"-"`
+}
+
+func (u *User) Profile() ProfileResponse {
+	return ProfileResponse{
+		ID:              u.ID,
+		Nome:            u.Nome,
+		DataNascimento:  u.DataNascimento,
+		Email:           u.Email,
+		EmailVerificado: u.EmailVerificado,
+		EmailPendente:   u.EmailPendente,
+		CriadoEm:        u.CreatedAt,
+	}
+}
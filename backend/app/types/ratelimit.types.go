@@ -0,0 +1,16 @@
+package types
+
+import (
+	"time"
+)
+
+type RateLimitEvent struct {
+	ID    uint      `gorm:"primarykey"`
+	Chave string    `gorm:"index:idx_rate_limit_chave_em"`
+	Em    time.Time `gorm:"index:idx_rate_limit_chave_em;index"`
+}
+
+type RateLimitLock struct {
+	Chave string `gorm:"primarykey"`
+	Ate   time.Time
+}
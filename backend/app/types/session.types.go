@@ -0,0 +1,55 @@
+package types
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type Session struct {
+	gorm.Model
+	UserID       uint       `json:"userId" gorm:"index"`
+	Dispositivo  string     `json:"dispositivo"`
+	UserAgent    string     `json:"userAgent"`
+	IP           string     `json:"ip"`
+	UltimoAcesso time.Time  `json:"ultimoAcesso"`
+	ExpiraEm     time.Time  `json:"expiraEm"`
+	RevogadaEm   *time.Time `json:"revogadaEm"`
+	User         User       `json:"-" gorm:"foreignKey:UserID"`
+}
+
+type RefreshToken struct {
+	ID        uint       `json:"id" gorm:"primarykey"`
+	SessionID uint       `json:"sessionId" gorm:"index"`
+	TokenHash string     `json:"-" gorm:"uniqueIndex"`
+	UsadoEm   *time.Time `json:"usadoEm"`
+	ExpiraEm  time.Time  `json:"expiraEm"`
+	CreatedAt time.Time  `json:"criadoEm"`
+	Session   Session    `json:"-" gorm:"foreignKey:SessionID"`
+}
+
+type RefreshRequest struct {
+	RefreshToken string `json:"refreshToken" binding:"required"`
+}
+
+type SessionInfo struct {
+	Dispositivo string
+	UserAgent   string
+	IP          string
+}
+
+type SessionResponse struct {
+	ID           uint      `json:"id"`
+	Dispositivo  string    `json:"dispositivo"`
+	UserAgent    string    `json:"userAgent"`
+	IP           string    `json:"ip"`
+	CriadoEm     time.Time `json:"criadoEm"`
+	UltimoAcesso time.Time `json:"ultimoAcesso"`
+	Atual        bool      `json:"atual"`
+}
+
+type AccessClaims struct {
+	UserID    uint
+	Email     string
+	SessionID uint
+}
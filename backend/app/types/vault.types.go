@@ -0,0 +1,31 @@
+package types
+
+import (
+	"gorm.io/gorm"
+)
+
+type KDFParams struct {
+	Algoritmo   string `json:"algoritmo"`
+	Salt        []byte `json:"salt"`
+	Memoria     uint32 `json:"memoria"`
+	Iteracoes   uint32 `json:"iteracoes"`
+	Paralelismo uint8  `json:"paralelismo"`
+}
+
+type VaultConfig struct {
+	gorm.Model
+	UserID        uint      `json:"userId" gorm:"uniqueIndex"`
+	ZeroKnowledge bool      `json:"zeroKnowledge"`
+	KDF           KDFParams `json:"kdf" gorm:"embedded;embeddedPrefix:kdf_"`
+	User          User      `json:"-" gorm:"foreignKey:UserID"`
+}
+
+type EnableZeroKnowledgeRequest struct {
+	KDF    KDFParams `json:"kdf" binding:"required"`
+	UserID uint      `json:"-"`
+}
+
+type VaultConfigResponse struct {
+	ZeroKnowledge bool       `json:"zeroKnowledge"`
+	KDF           *KDFParams `json:"kdf,omitempty"`
+}
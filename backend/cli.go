@@ -0,0 +1,156 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/Vicente/Password-Mobile-App/backend/app/breach"
+	"github.com/Vicente/Password-Mobile-App/backend/app/dal"
+	"github.com/Vicente/Password-Mobile-App/backend/app/services"
+)
+
+// runCommand executa tarefas de manutenção com o mesmo binário do servidor,
+// por exemplo:
+//
+//	go run . backup-export -email joao@email.com -out cofre.json
+//	go run . backup-restore -email joao@email.com -in cofre.json -modo replace
+//
+// A senha do arquivo vem de BACKUP_PASSWORD ou é lida da entrada padrão.
+func runCommand(args []string, authDAL *dal.AuthDAL, itemService *services.ItemService) error {
+	switch args[0] {
+	case "backup-export":
+		flags := flag.NewFlagSet(args[0], flag.ExitOnError)
+		email := flags.String("email", "", "email do usuário")
+		out := flags.String("out", "", "arquivo de saída")
+		flags.Parse(args[1:])
+
+		if *out == "" {
+			return errors.New("informe o arquivo de saída com -out")
+		}
+
+		userID, err := commandUser(authDAL, *email)
+		if err != nil {
+			return err
+		}
+
+		content, err := itemService.ExportBackup(userID, commandPassword())
+		if err != nil {
+			return err
+		}
+
+		if err := os.WriteFile(*out, content, 0600); err != nil {
+			return err
+		}
+		fmt.Printf("Cópia de segurança gravada em %s\n", *out)
+		return nil
+	case "backup-restore":
+		flags := flag.NewFlagSet(args[0], flag.ExitOnError)
+		email := flags.String("email", "", "email do usuário")
+		in := flags.String("in", "", "arquivo da cópia de segurança")
+		modo := flags.String("modo", "merge", "merge ou replace")
+		flags.Parse(args[1:])
+
+		userID, err := commandUser(authDAL, *email)
+		if err != nil {
+			return err
+		}
+
+		data, err := os.ReadFile(*in)
+		if err != nil {
+			return err
+		}
+
+		result, err := itemService.RestoreBackup(userID, data, commandPassword(), *modo)
+		if err != nil {
+			if result != nil {
+				for _, issue := range result.Ignorados {
+					fmt.Printf("  item %d %s: %s\n", issue.Linha, issue.Nome, issue.Motivo)
+				}
+			}
+			return err
+		}
+
+		fmt.Printf("%d de %d itens restaurados (%d removidos)\n", result.Restaurados, result.Total, result.Removidos)
+		for _, issue := range result.Ignorados {
+			fmt.Printf("  item %d %s: %s\n", issue.Linha, issue.Nome, issue.Motivo)
+		}
+		return nil
+	}
+
+	return fmt.Errorf("comando desconhecido: %s", args[0])
+}
+
+// runOfflineCommand executa os comandos que não precisam do banco de dados,
+// antes da conexão:
+//
+//	go run . breach-build -in pwnedpasswords -out vazadas.bloom -fp 0.001
+//
+// -in aceita o diretório de intervalos baixado do HIBP ou um arquivo com
+// linhas HASH:OCORRÊNCIAS; -hash escolhe entre sha1 e ntlm.
+func runOfflineCommand(args []string) (bool, error) {
+	switch args[0] {
+	case "breach-build":
+		flags := flag.NewFlagSet(args[0], flag.ExitOnError)
+		in := flags.String("in", "", "diretório de intervalos ou arquivo de hashes")
+		out := flags.String("out", "", "arquivo do filtro")
+		hash := flags.String("hash", "sha1", "sha1 ou ntlm")
+		fp := flags.Float64("fp", 0.001, "taxa de falsos positivos")
+		flags.Parse(args[1:])
+
+		if *in == "" || *out == "" {
+			return true, errors.New("informe o conjunto com -in e o arquivo de saída com -out")
+		}
+
+		kind, err := breach.ParseHashKind(*hash)
+		if err != nil {
+			return true, err
+		}
+
+		filter, err := breach.BuildFilter(*in, kind, *fp)
+		if err != nil {
+			return true, err
+		}
+
+		file, err := os.OpenFile(*out, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+		if err != nil {
+			return true, err
+		}
+		defer file.Close()
+
+		size, err := filter.WriteTo(file)
+		if err != nil {
+			return true, err
+		}
+		fmt.Printf("Filtro gravado em %s (%d bytes)\n", *out, size)
+		return true, file.Close()
+	}
+
+	return false, nil
+}
+
+func commandUser(authDAL *dal.AuthDAL, email string) (uint, error) {
+	email = strings.ToLower(strings.TrimSpace(email))
+	if email == "" {
+		return 0, errors.New("informe o email do usuário com -email")
+	}
+
+	user, err := authDAL.GetUserByEmail(email)
+	if err != nil {
+		return 0, fmt.Errorf("usuário %s não encontrado", email)
+	}
+	return user.ID, nil
+}
+
+func commandPassword() string {
+	if senha := os.Getenv("BACKUP_PASSWORD"); senha != "" {
+		return senha
+	}
+
+	fmt.Fprint(os.Stderr, "Senha do arquivo: ")
+	line, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	return strings.TrimRight(line, "\r\n")
+}
@@ -3,12 +3,19 @@ package main
 import (
 	"crypto/rand"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"log"
 	"os"
+	"strconv"
+	"time"
 
+	"github.com/Vicente/Password-Mobile-App/backend/app/breach"
 	"github.com/Vicente/Password-Mobile-App/backend/app/controllers"
 	"github.com/Vicente/Password-Mobile-App/backend/app/dal"
+	"github.com/Vicente/Password-Mobile-App/backend/app/mailer"
+	"github.com/Vicente/Password-Mobile-App/backend/app/middleware"
+	"github.com/Vicente/Password-Mobile-App/backend/app/ratelimit"
 	"github.com/Vicente/Password-Mobile-App/backend/app/routes"
 	"github.com/Vicente/Password-Mobile-App/backend/app/services"
 	"github.com/Vicente/Password-Mobile-App/backend/app/types"
@@ -27,11 +34,202 @@ func generateRandomSecret() string {
 	return hex.EncodeToString(bytes)
 }
 
+func envInt(name string, fallback int) int {
+	value := os.Getenv(name)
+	if value == "" {
+		return fallback
+	}
+
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		log.Printf("%s inválido (%q), usando %d", name, value, fallback)
+		return fallback
+	}
+	return parsed
+}
+
+func envDuration(name string, fallback time.Duration) time.Duration {
+	value := os.Getenv(name)
+	if value == "" {
+		return fallback
+	}
+
+	parsed, err := time.ParseDuration(value)
+	if err != nil {
+		log.Printf("%s inválido (%q), usando %s", name, value, fallback)
+		return fallback
+	}
+	return parsed
+}
+
+func loadMasterKey() []byte {
+	if value := os.Getenv("MASTER_KEY"); value != "" {
+		key, err := services.DecodeMasterKey(value)
+		if err != nil {
+			log.Fatalf("MASTER_KEY inválida: %v", err)
+		}
+		log.Println("Usando MASTER_KEY fornecida")
+		return key
+	}
+
+	path := os.Getenv("MASTER_KEY_FILE")
+	if path == "" {
+		path = "master.key"
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		// Uma chave gerada no lugar da verdadeira deixaria todos os itens
+		// ilegíveis; fora do desenvolvimento ela precisa ser configurada.
+		if os.Getenv("APP_ENV") != "development" {
+			log.Fatalf("Chave mestra não configurada: defina MASTER_KEY ou crie %s (a geração automática só acontece com APP_ENV=development)", path)
+		}
+		log.Printf("Chave mestra não encontrada, gerando automaticamente em %s...", path)
+		key, err := services.GenerateKey()
+		if err != nil {
+			log.Fatalf("Falha ao gerar chave mestra: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(hex.EncodeToString(key)), 0600); err != nil {
+			log.Fatalf("Falha ao salvar chave mestra: %v", err)
+		}
+		return key
+	} else if err != nil {
+		log.Fatalf("Falha ao ler chave mestra: %v", err)
+	}
+
+	key, err := services.DecodeMasterKey(string(data))
+	if err != nil {
+		log.Fatalf("Arquivo de chave mestra inválido: %v", err)
+	}
+	log.Printf("Usando chave mestra de %s", path)
+	return key
+}
+
+func startTrashPurge(itemService *services.ItemService, retention time.Duration) {
+	purge := func() {
+		purged, err := itemService.PurgeTrash(retention)
+		if err != nil {
+			log.Printf("Falha ao esvaziar a lixeira: %v", err)
+			return
+		}
+		if purged > 0 {
+			log.Printf("%d itens removidos definitivamente da lixeira", purged)
+		}
+	}
+
+	go func() {
+		ticker := time.NewTicker(time.Hour)
+		defer ticker.Stop()
+
+		purge()
+		for range ticker.C {
+			purge()
+		}
+	}()
+}
+
+func startExportPurge(exportService *services.ExportService) {
+	go func() {
+		ticker := time.NewTicker(time.Hour)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			if _, err := exportService.PurgeExpired(); err != nil {
+				log.Printf("Falha ao remover exportações expiradas: %v", err)
+			}
+		}
+	}()
+}
+
+func newRateLimitStore(db *gorm.DB) ratelimit.Store {
+	switch os.Getenv("RATE_LIMIT_STORE") {
+	case "postgres":
+		log.Println("Usando Postgres para o controle de tentativas")
+		return dal.NewRateLimitDAL(db)
+	case "", "memory":
+		return ratelimit.NewMemoryStore()
+	default:
+		log.Fatalf("RATE_LIMIT_STORE inválido: %s", os.Getenv("RATE_LIMIT_STORE"))
+		return nil
+	}
+}
+
+func startRateLimitPrune(store ratelimit.Store) {
+	go func() {
+		ticker := time.NewTicker(10 * time.Minute)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			if err := store.Prune(time.Now().Add(-24 * time.Hour)); err != nil {
+				log.Printf("Falha ao limpar registros de tentativas: %v", err)
+			}
+		}
+	}()
+}
+
+func newBreachChecker() breach.Checker {
+	path := os.Getenv("BREACH_DATASET")
+	if path == "" {
+		log.Println("BREACH_DATASET não definido, senhas vazadas não serão verificadas")
+		return nil
+	}
+
+	kind, err := breach.ParseHashKind(os.Getenv("BREACH_HASH"))
+	if err != nil {
+		log.Fatalf("BREACH_HASH: %v", err)
+	}
+
+	checker, err := breach.Open(path, kind)
+	if err != nil {
+		log.Fatalf("Falha ao carregar senhas vazadas de %s: %v", path, err)
+	}
+	log.Printf("Verificando senhas vazadas com %s", path)
+	return checker
+}
+
+func newMailer() mailer.Mailer {
+	switch os.Getenv("MAIL_DRIVER") {
+	case "smtp":
+		host := os.Getenv("SMTP_HOST")
+		from := os.Getenv("MAIL_FROM")
+		if host == "" || from == "" {
+			log.Fatal("SMTP_HOST e MAIL_FROM são obrigatórios com MAIL_DRIVER=smtp")
+		}
+		log.Printf("Enviando emails via SMTP (%s)", host)
+		return mailer.NewSMTPMailer(host, envInt("SMTP_PORT", 587), os.Getenv("SMTP_USERNAME"), os.Getenv("SMTP_PASSWORD"), from)
+	case "file":
+		dir := os.Getenv("MAIL_DIR")
+		if dir == "" {
+			dir = "mail"
+		}
+		fileMailer, err := mailer.NewFileMailer(dir)
+		if err != nil {
+			log.Fatalf("Falha ao preparar diretório de emails: %v", err)
+		}
+		log.Printf("Emails serão gravados em %s", dir)
+		return fileMailer
+	case "", "log":
+		return mailer.NewLogMailer()
+	default:
+		log.Fatalf("MAIL_DRIVER inválido: %s", os.Getenv("MAIL_DRIVER"))
+		return nil
+	}
+}
+
 func main() {
 	if err := godotenv.Load(); err != nil {
 		log.Println("Arquivo .env não encontrado, usando variáveis de ambiente do sistema")
 	}
 
+	if len(os.Args) > 1 {
+		if handled, err := runOfflineCommand(os.Args[1:]); handled {
+			if err != nil {
+				log.Fatal(err)
+			}
+			return
+		}
+	}
+
 	jwtSecret := os.Getenv("JWT_SECRET")
 	if jwtSecret == "" {
 		log.Println("JWT_SECRET não encontrado, gerando automaticamente...")
@@ -52,30 +250,185 @@ func main() {
 		log.Fatalf("Falha ao conectar ao banco de dados: %v", err)
 	}
 
-	if err := db.AutoMigrate(&types.User{}, &types.Item{}); err != nil {
+	authDAL := dal.NewAuthDAL(db)
+	if err := authDAL.AddEmailVerifiedColumn(); err != nil {
+		log.Fatalf("Falha ao marcar usuários existentes como verificados: %v", err)
+	}
+
+	if err := db.AutoMigrate(
+		&types.User{},
+		&types.Item{},
+		&types.Folder{},
+		&types.Tag{},
+		&types.UserKey{},
+		&types.VaultConfig{},
+		&types.ItemHistory{},
+		&types.Session{},
+		&types.RefreshToken{},
+		&types.MFAConfig{},
+		&types.RecoveryCode{},
+		&types.RateLimitEvent{},
+		&types.RateLimitLock{},
+		&types.PasswordReset{},
+		&types.AuditEvent{},
+		&types.ExportJob{},
+	); err != nil {
 		log.Fatalf("Falha ao migrar modelos: %v", err)
 	}
 
-	authDAL := dal.NewAuthDAL(db)
+	masterKey := loadMasterKey()
+
 	itemDAL := dal.NewItemDAL(db)
+	folderDAL := dal.NewFolderDAL(db)
+	tagDAL := dal.NewTagDAL(db)
+	keyDAL := dal.NewKeyDAL(db)
+	vaultDAL := dal.NewVaultDAL(db)
+	sessionDAL := dal.NewSessionDAL(db)
+	mfaDAL := dal.NewMFADAL(db)
+	passwordDAL := dal.NewPasswordDAL(db)
+	auditDAL := dal.NewAuditDAL(db)
+	exportDAL := dal.NewExportDAL(db)
 
-	authService := services.NewAuthService(authDAL)
-	itemService := services.NewItemService(itemDAL)
+	if err := itemDAL.EnsureListIndexes(); err != nil {
+		log.Fatalf("Falha ao criar índices da listagem de itens: %v", err)
+	}
+	if err := itemDAL.EnsureSearchIndexes(); err != nil {
+		log.Printf("Índices de busca não criados (requer a extensão pg_trgm): %v", err)
+	}
+
+	cryptoService := services.NewCryptoService(keyDAL, masterKey)
+	sessionService := services.NewSessionService(sessionDAL, authDAL, jwtSecret)
+	sessionService.AccessTokenTTL = envDuration("ACCESS_TOKEN_TTL", sessionService.AccessTokenTTL)
+	sessionService.RefreshTokenTTL = envDuration("REFRESH_TOKEN_TTL", sessionService.RefreshTokenTTL)
+
+	auditService := services.NewAuditService(auditDAL)
+	limiter := ratelimit.NewLimiter(newRateLimitStore(db))
+	startRateLimitPrune(limiter.Store)
+
+	mail := newMailer()
+	verificationService := services.NewVerificationService(authDAL, mail, limiter, jwtSecret)
+	verificationService.TokenTTL = envDuration("EMAIL_VERIFY_TTL", verificationService.TokenTTL)
+	verificationService.VerifyURL = os.Getenv("EMAIL_VERIFY_URL")
+	if policy := os.Getenv("UNVERIFIED_POLICY"); policy != "" {
+		if err := services.ValidateUnverifiedPolicy(policy); err != nil {
+			log.Fatalf("UNVERIFIED_POLICY: %v", err)
+		}
+		verificationService.Policy = policy
+	}
 
-	authController := controllers.NewAuthController(authService)
+	mfaService := services.NewMFAService(mfaDAL, authDAL, cryptoService, sessionService, limiter)
+	minPasswordScore := envInt("MIN_PASSWORD_SCORE", 2)
+	if minPasswordScore < 0 || minPasswordScore > 4 {
+		log.Fatalf("MIN_PASSWORD_SCORE deve estar entre 0 e 4: %d", minPasswordScore)
+	}
+
+	breachService := services.NewBreachService(newBreachChecker())
+	authService := services.NewAuthService(authDAL, sessionService, mfaService, verificationService, auditService, limiter, breachService)
+	authService.MinPasswordScore = minPasswordScore
+	passwordService := services.NewPasswordService(authDAL, passwordDAL, mail, auditService, limiter, breachService)
+	passwordService.MinPasswordScore = minPasswordScore
+	passwordService.ResetTokenTTL = envDuration("PASSWORD_RESET_TTL", passwordService.ResetTokenTTL)
+	passwordService.ResetURL = os.Getenv("PASSWORD_RESET_URL")
+	profileService := services.NewProfileService(authDAL, cryptoService, verificationService, limiter)
+	exportService := services.NewExportService(exportDAL, authDAL, itemDAL, folderDAL, tagDAL, sessionDAL, cryptoService, auditService, limiter, jwtSecret)
+	exportService.TTL = envDuration("EXPORT_TTL", exportService.TTL)
+	vaultService := services.NewVaultService(vaultDAL, itemDAL)
+	itemService := services.NewItemService(itemDAL, folderDAL, tagDAL, cryptoService, vaultService, breachService)
+	itemService.HistoryLimit = envInt("ITEM_HISTORY_LIMIT", itemService.HistoryLimit)
+	itemService.HealthMaxAgeDays = envInt("HEALTH_MAX_AGE_DAYS", itemService.HealthMaxAgeDays)
+	itemService.MinPasswordScore = minPasswordScore
+	folderService := services.NewFolderService(folderDAL)
+	tagService := services.NewTagService(tagDAL)
+
+	migrated, err := itemService.EncryptLegacyItems()
+	if err != nil {
+		log.Fatalf("Falha ao criptografar senhas existentes: %v", err)
+	}
+	if migrated > 0 {
+		log.Printf("%d senhas existentes foram criptografadas", migrated)
+	}
+
+	bound, err := itemService.BindItemFields()
+	if err != nil {
+		log.Fatalf("Falha ao vincular os campos cifrados aos itens: %v", err)
+	}
+	if bound > 0 {
+		log.Printf("%d itens e versões do histórico cifrados com o item e o campo no AAD", bound)
+	}
+
+	backfilled, err := itemService.BackfillPasswordMACs()
+	if err != nil {
+		log.Fatalf("Falha ao calcular o HMAC das senhas existentes: %v", err)
+	}
+	if backfilled > 0 {
+		log.Printf("HMAC calculado para %d senhas existentes", backfilled)
+	}
+
+	rated, err := itemService.BackfillPasswordRatings()
+	if err != nil {
+		log.Fatalf("Falha ao avaliar as senhas existentes: %v", err)
+	}
+	if rated > 0 {
+		log.Printf("Força e vazamento avaliados para %d senhas existentes", rated)
+	}
+
+	if len(os.Args) > 1 {
+		if err := runCommand(os.Args[1:], authDAL, itemService); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	trashRetentionDays := envInt("TRASH_RETENTION_DAYS", 30)
+	if trashRetentionDays <= 0 {
+		log.Fatalf("TRASH_RETENTION_DAYS deve ser maior que zero: %d", trashRetentionDays)
+	}
+	startTrashPurge(itemService, time.Duration(trashRetentionDays)*24*time.Hour)
+
+	if interrupted, err := exportService.FailInterrupted(); err != nil {
+		log.Printf("Falha ao verificar exportações interrompidas: %v", err)
+	} else if interrupted > 0 {
+		log.Printf("%d exportações interrompidas foram marcadas como erro", interrupted)
+	}
+	startExportPurge(exportService)
+
+	authController := controllers.NewAuthController(authService, sessionService)
 	itemController := controllers.NewItemController(itemService)
+	vaultController := controllers.NewVaultController(vaultService)
+	mfaController := controllers.NewMFAController(mfaService)
+	passwordController := controllers.NewPasswordController(passwordService)
+	verificationController := controllers.NewVerificationController(verificationService)
+	profileController := controllers.NewProfileController(profileService)
+	exportController := controllers.NewExportController(exportService)
+	generatorController := controllers.NewGeneratorController()
+	reportController := controllers.NewReportController(itemService)
+	folderController := controllers.NewFolderController(folderService)
+	tagController := controllers.NewTagController(tagService)
 
 	app := fiber.New()
 
 	app.Use(cors.New(cors.Config{
 		AllowOrigins:     "*",
 		AllowMethods:     "GET,POST,HEAD,PUT,DELETE,PATCH",
-		AllowHeaders:     "Origin,Content-Type,Accept,Authorization",
+		AllowHeaders:     "Origin,Content-Type,Accept,Authorization,If-Match",
+		ExposeHeaders:    "ETag,Retry-After,X-Total-Count,X-Next-Cursor",
 		AllowCredentials: false,
 	}))
 
-	routes.SetupAuthRoutes(app, authController)
-	routes.SetupItemRoutes(app, itemController)
+	authMiddleware := middleware.AuthMiddleware(sessionService, verificationService)
+
+	routes.SetupAuthRoutes(app, authController, authMiddleware, limiter)
+	routes.SetupMFARoutes(app, mfaController, authMiddleware, limiter)
+	routes.SetupPasswordRoutes(app, passwordController, authMiddleware, limiter)
+	routes.SetupVerificationRoutes(app, verificationController, authMiddleware, limiter)
+	routes.SetupProfileRoutes(app, profileController, authMiddleware)
+	routes.SetupExportRoutes(app, exportController, authMiddleware)
+	routes.SetupGeneratorRoutes(app, generatorController, authMiddleware)
+	routes.SetupReportRoutes(app, reportController, authMiddleware)
+	routes.SetupFolderRoutes(app, folderController, authMiddleware)
+	routes.SetupTagRoutes(app, tagController, authMiddleware)
+	routes.SetupItemRoutes(app, itemController, authMiddleware, limiter)
+	routes.SetupVaultRoutes(app, vaultController, authMiddleware)
 
 	port := os.Getenv("PORT")
 	if port == "" {
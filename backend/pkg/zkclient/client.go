@@ -0,0 +1,182 @@
+package zkclient
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// Doer permite trocar o transporte HTTP, por exemplo por um adaptador sobre
+// fiber.App.Test para exercitar as rotas sem abrir uma porta.
+type Doer interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+type Item struct {
+	ID      uint
+	Payload ItemPayload
+}
+
+type Client struct {
+	BaseURL string
+	Token   string
+	HTTP    Doer
+	key     []byte
+}
+
+func New(baseURL string) *Client {
+	return &Client{
+		BaseURL: strings.TrimSuffix(baseURL, "/"),
+		HTTP:    http.DefaultClient,
+	}
+}
+
+func (c *Client) Signin(email, senha string) error {
+	var response struct {
+		Token string `json:"token"`
+	}
+
+	body := map[string]string{"email": email, "senha": senha}
+	if err := c.do(http.MethodPost, "/api/auth/signin", body, &response); err != nil {
+		return err
+	}
+
+	c.Token = response.Token
+	return nil
+}
+
+// EnableZeroKnowledge gera novos parâmetros de KDF, ativa o modo no servidor
+// e deixa o cliente pronto para cifrar itens com a senha mestra informada.
+func (c *Client) EnableZeroKnowledge(masterPassword string) error {
+	params, err := DefaultKDFParams()
+	if err != nil {
+		return err
+	}
+
+	if err := c.do(http.MethodPost, "/api/vault/zero-knowledge", map[string]KDFParams{"kdf": params}, nil); err != nil {
+		return err
+	}
+
+	c.key, err = DeriveKey(masterPassword, params)
+	return err
+}
+
+// Unlock busca os parâmetros de KDF do usuário e deriva a chave do cofre.
+func (c *Client) Unlock(masterPassword string) error {
+	var config struct {
+		ZeroKnowledge bool       `json:"zeroKnowledge"`
+		KDF           *KDFParams `json:"kdf"`
+	}
+
+	if err := c.do(http.MethodGet, "/api/vault", nil, &config); err != nil {
+		return err
+	}
+
+	if !config.ZeroKnowledge || config.KDF == nil {
+		return errors.New("zkclient: o cofre não está no modo zero-knowledge")
+	}
+
+	key, err := DeriveKey(masterPassword, *config.KDF)
+	if err != nil {
+		return err
+	}
+
+	c.key = key
+	return nil
+}
+
+func (c *Client) CreateItem(payload ItemPayload) (*Item, error) {
+	if c.key == nil {
+		return nil, errors.New("zkclient: cofre bloqueado")
+	}
+
+	blob, err := EncryptItem(c.key, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	var response struct {
+		ID uint `json:"id"`
+	}
+	if err := c.do(http.MethodPost, "/api/item", map[string]string{"blob": blob}, &response); err != nil {
+		return nil, err
+	}
+
+	return &Item{ID: response.ID, Payload: payload}, nil
+}
+
+func (c *Client) ListItems() ([]Item, error) {
+	if c.key == nil {
+		return nil, errors.New("zkclient: cofre bloqueado")
+	}
+
+	var response []struct {
+		ID   uint   `json:"id"`
+		Blob string `json:"blob"`
+	}
+	if err := c.do(http.MethodGet, "/api/items", nil, &response); err != nil {
+		return nil, err
+	}
+
+	items := make([]Item, 0, len(response))
+	for _, entry := range response {
+		payload, err := DecryptItem(c.key, entry.Blob)
+		if err != nil {
+			return nil, fmt.Errorf("item %d: %w", entry.ID, err)
+		}
+		items = append(items, Item{ID: entry.ID, Payload: *payload})
+	}
+	return items, nil
+}
+
+func (c *Client) DeleteItem(id uint) error {
+	return c.do(http.MethodDelete, fmt.Sprintf("/api/item/%d", id), nil, nil)
+}
+
+func (c *Client) do(method, path string, body interface{}, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequest(method, c.BaseURL+path, reader)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		var apiErr struct {
+			Error string `json:"error"`
+		}
+		_ = json.NewDecoder(resp.Body).Decode(&apiErr)
+		return fmt.Errorf("zkclient: %s %s: %d %s", method, path, resp.StatusCode, apiErr.Error)
+	}
+
+	if out == nil || resp.StatusCode == http.StatusNoContent {
+		return nil
+	}
+
+	return json.NewDecoder(resp.Body).Decode(out)
+}
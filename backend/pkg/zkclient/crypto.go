@@ -0,0 +1,116 @@
+// Package zkclient é o cliente de referência do modo zero-knowledge: deriva a
+// chave do cofre a partir da senha mestra com Argon2id e cifra cada item
+// localmente, de modo que o servidor só armazena blobs opacos.
+package zkclient
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+const (
+	BlobVersion byte = 1
+	KeySize          = chacha20poly1305.KeySize
+	SaltSize         = 16
+)
+
+var itemAAD = []byte("password-mobile-app:zk-item:v1")
+
+type KDFParams struct {
+	Algoritmo   string `json:"algoritmo"`
+	Salt        []byte `json:"salt"`
+	Memoria     uint32 `json:"memoria"`
+	Iteracoes   uint32 `json:"iteracoes"`
+	Paralelismo uint8  `json:"paralelismo"`
+}
+
+type ItemPayload struct {
+	Nome  string `json:"nome"`
+	Senha string `json:"senha"`
+}
+
+func DefaultKDFParams() (KDFParams, error) {
+	salt := make([]byte, SaltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return KDFParams{}, err
+	}
+
+	return KDFParams{
+		Algoritmo:   "argon2id",
+		Salt:        salt,
+		Memoria:     64 * 1024,
+		Iteracoes:   3,
+		Paralelismo: 4,
+	}, nil
+}
+
+func DeriveKey(masterPassword string, params KDFParams) ([]byte, error) {
+	if params.Algoritmo != "argon2id" {
+		return nil, errors.New("zkclient: algoritmo de derivação não suportado")
+	}
+	if len(params.Salt) == 0 || params.Memoria == 0 || params.Iteracoes == 0 || params.Paralelismo == 0 {
+		return nil, errors.New("zkclient: parâmetros de derivação incompletos")
+	}
+
+	return argon2.IDKey([]byte(masterPassword), params.Salt, params.Iteracoes, params.Memoria, params.Paralelismo, KeySize), nil
+}
+
+func EncryptItem(key []byte, payload ItemPayload) (string, error) {
+	aead, err := chacha20poly1305.NewX(key)
+	if err != nil {
+		return "", err
+	}
+
+	plaintext, err := json.Marshal(payload)
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+
+	blob := make([]byte, 0, 1+len(nonce)+len(plaintext)+aead.Overhead())
+	blob = append(blob, BlobVersion)
+	blob = append(blob, nonce...)
+	blob = aead.Seal(blob, nonce, plaintext, itemAAD)
+
+	return base64.StdEncoding.EncodeToString(blob), nil
+}
+
+func DecryptItem(key []byte, blob string) (*ItemPayload, error) {
+	aead, err := chacha20poly1305.NewX(key)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := base64.StdEncoding.DecodeString(blob)
+	if err != nil {
+		return nil, errors.New("zkclient: blob não está em base64")
+	}
+
+	if len(data) < 1+aead.NonceSize()+aead.Overhead() {
+		return nil, errors.New("zkclient: blob muito curto")
+	}
+	if data[0] != BlobVersion {
+		return nil, errors.New("zkclient: versão de blob não suportada")
+	}
+
+	nonce := data[1 : 1+aead.NonceSize()]
+	plaintext, err := aead.Open(nil, nonce, data[1+aead.NonceSize():], itemAAD)
+	if err != nil {
+		return nil, errors.New("zkclient: falha ao descriptografar item, chave incorreta?")
+	}
+
+	var payload ItemPayload
+	if err := json.Unmarshal(plaintext, &payload); err != nil {
+		return nil, err
+	}
+	return &payload, nil
+}
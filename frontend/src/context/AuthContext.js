@@ -28,6 +28,7 @@ export const AuthProvider = ({ children }) => {
         }
       } catch (error) {
         await AsyncStorage.removeItem('authToken');
+        await AsyncStorage.removeItem('refreshToken');
         await AsyncStorage.removeItem('user');
       } finally {
         setLoading(false);
@@ -43,6 +44,9 @@ export const AuthProvider = ({ children }) => {
       
       if (response.token) {
         await AsyncStorage.setItem('authToken', response.token);
+        if (response.refreshToken) {
+          await AsyncStorage.setItem('refreshToken', response.refreshToken);
+        }
         await AsyncStorage.setItem('user', JSON.stringify(response.user || { email }));
         setUser(response.user || { email });
         return { success: true };
@@ -108,11 +112,13 @@ export const AuthProvider = ({ children }) => {
 
   const logout = async () => {
     try {
-      await authService.signOut();
+      const refreshToken = await AsyncStorage.getItem('refreshToken');
+      await authService.signOut(refreshToken);
     } catch (error) {
     } finally {
       try {
         await AsyncStorage.removeItem('authToken');
+        await AsyncStorage.removeItem('refreshToken');
         await AsyncStorage.removeItem('user');
         
         await AsyncStorage.removeItem('Senhas');
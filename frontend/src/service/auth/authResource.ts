@@ -8,6 +8,9 @@ export const signup = (data) => {
     return api.post('/auth/signup', data).then((response) => response.data)
 }
 
-export const signout = () => {
-    return Promise.resolve({ success: true })
+export const signout = (refreshToken) => {
+    if (!refreshToken) {
+        return Promise.resolve({ success: true })
+    }
+    return api.post('/auth/logout', { refreshToken }).then(() => ({ success: true }))
 }
\ No newline at end of file
@@ -1,7 +1,9 @@
+import { Platform } from 'react-native'
 import * as authResource from "./authResource"
 
 export const signIn = async (email: string, password: string) => {
-    const response = await authResource.signin({ email, senha: password })
+    const dispositivo = `${Platform.OS} ${Platform.Version ?? ''}`.trim()
+    const response = await authResource.signin({ email, senha: password, dispositivo })
     return response
 }
 
@@ -23,7 +25,7 @@ export const signUp = async (nome: string, email: string, dataNascimento: string
     return response
 }
 
-export const signOut = async () => {
-    const response = await authResource.signout()
+export const signOut = async (refreshToken?: string) => {
+    const response = await authResource.signout(refreshToken)
     return response
 }
\ No newline at end of file
@@ -10,4 +10,22 @@ export const getItems = () => {
 
 export const deleteItem = (id) => {
     return api.delete(`/item/${id}`);
+};
+
+// A exportação é um POST: a senha do arquivo vai no corpo, nunca na URL. O
+// arquivo volta como texto, sem passar pelo JSON.parse do axios, para que o
+// MAC continue valendo quando ele for restaurado.
+export const exportBackup = (senha) => {
+    return api.post('/items/export', { senha }, { responseType: 'text', transformResponse: (data) => data })
+        .then((response) => response.data);
+};
+
+export const restoreBackup = (arquivo, senha, modo) => {
+    const form = new FormData();
+    form.append('arquivo', arquivo);
+    form.append('senha', senha);
+    form.append('modo', modo);
+
+    return api.post('/items/restore', form, { headers: { 'Content-Type': 'multipart/form-data' } })
+        .then((response) => response.data);
 }; 
\ No newline at end of file
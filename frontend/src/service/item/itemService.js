@@ -76,4 +76,47 @@ export const deleteItem = async (id) => {
     
     return { success: false, message };
   }
+};
+
+export const exportBackup = async (senha) => {
+  try {
+    const arquivo = await itemResource.exportBackup(senha);
+    return { success: true, data: arquivo };
+  } catch (error) {
+    let message = 'Erro inesperado';
+
+    if (error.response?.status === 401) {
+      message = 'Sessão expirada. Faça login novamente';
+    } else if (error.code === 'NETWORK_ERROR' || error.message?.includes('Network Error')) {
+      message = 'Erro de conexão. Verifique sua internet';
+    } else if (error.response?.data) {
+      // Com responseType 'text' o corpo do erro também chega como texto.
+      try {
+        message = JSON.parse(error.response.data).error || message;
+      } catch {
+        message = error.response.data;
+      }
+    }
+
+    return { success: false, message };
+  }
+};
+
+export const restoreBackup = async (arquivo, senha, modo = 'merge') => {
+  try {
+    const response = await itemResource.restoreBackup(arquivo, senha, modo);
+    return { success: true, data: response };
+  } catch (error) {
+    let message = 'Erro inesperado';
+
+    if (error.response?.status === 401) {
+      message = 'Sessão expirada. Faça login novamente';
+    } else if (error.code === 'NETWORK_ERROR' || error.message?.includes('Network Error')) {
+      message = 'Erro de conexão. Verifique sua internet';
+    } else if (error.response?.data?.error) {
+      message = error.response.data.error;
+    }
+
+    return { success: false, message, ignorados: error.response?.data?.ignorados || [] };
+  }
 }; 
\ No newline at end of file
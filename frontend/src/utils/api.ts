@@ -41,14 +41,45 @@ api.interceptors.request.use(
   }
 );
 
+let refreshPromise: Promise<string | null> | null = null;
+
+const refreshAccessToken = async () => {
+  const refreshToken = await AsyncStorage.getItem('refreshToken');
+  if (!refreshToken) {
+    return null;
+  }
+
+  try {
+    const response = await axios.post(`${api.defaults.baseURL}/auth/refresh`, { refreshToken });
+    await AsyncStorage.setItem('authToken', response.data.token);
+    await AsyncStorage.setItem('refreshToken', response.data.refreshToken);
+    return response.data.token;
+  } catch (error) {
+    return null;
+  }
+};
+
 api.interceptors.response.use(
   (response) => response,
   async (error) => {
     if (error.response?.status === 401) {
       const isAuthRoute = error.config?.url?.includes('/auth/');
       
+      if (!isAuthRoute && !error.config?._retry) {
+        refreshPromise = refreshPromise || refreshAccessToken();
+        const token = await refreshPromise;
+        refreshPromise = null;
+
+        if (token) {
+          error.config._retry = true;
+          error.config.headers.Authorization = `Bearer ${token}`;
+          return api(error.config);
+        }
+      }
+
       if (!isAuthRoute) {
         await AsyncStorage.removeItem('authToken');
+        await AsyncStorage.removeItem('refreshToken');
         await AsyncStorage.removeItem('user');
       }
       